@@ -0,0 +1,320 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	db "voice_assistant/db/sqlc"
+	"voice_assistant/logging"
+	"voice_assistant/tools"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// pgUniqueViolation is the SQLSTATE of a unique constraint violation.
+const pgUniqueViolation = "23505"
+
+// errWrongPassword is returned by checkPassword when the password does not match.
+var errWrongPassword = errors.New("password is incorrect")
+
+// checkPassword confirms that password is the user's current password.
+func (s *Server) checkPassword(ctx context.Context, userID pgtype.UUID, password string) (db.GetUserCredentialsRow, error) {
+	credentials, err := s.db.GetUserCredentials(ctx, userID)
+	if err != nil {
+		return credentials, fmt.Errorf("get credentials: %w", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(credentials.Password), []byte(password)) != nil {
+		return credentials, errWrongPassword
+	}
+	return credentials, nil
+}
+
+// writeCheckPasswordError answers a failed checkPassword with 400, or 500.
+func writeCheckPasswordError(w http.ResponseWriter, r *http.Request, handler string, userID pgtype.UUID, err error) {
+	if errors.Is(err, errWrongPassword) {
+		http.Error(w, `{"message": "password is incorrect"}`, http.StatusBadRequest)
+		return
+	}
+	slog.ErrorContext(r.Context(), "Error checking password", "handler", handler, "user_id", userID, "err", err)
+	http.Error(w, `{"message": "failed to check password"}`, http.StatusInternalServerError)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+func (s *Server) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, sessionID, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "ChangePassword", "err", err)
+		return
+	}
+
+	var changePasswordRequest ChangePasswordRequest
+	if err := json.Unmarshal(bodyBytes, &changePasswordRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "ChangePassword", "err", err)
+		return
+	}
+
+	if changePasswordRequest.CurrentPassword == "" || changePasswordRequest.NewPassword == "" {
+		http.Error(w, `{"message": "current and new password are required"}`, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.checkPassword(r.Context(), userID, changePasswordRequest.CurrentPassword); err != nil {
+		writeCheckPasswordError(w, r, "ChangePassword", userID, err)
+		return
+	}
+
+	hashedNewPassword, err := bcrypt.GenerateFromPassword([]byte(changePasswordRequest.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, `{"message": "failed to hash new password"}`, http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error hashing new password", "handler", "ChangePassword", "user_id", userID, "err", err)
+		return
+	}
+
+	if _, err := s.db.ResetPassword(r.Context(), db.ResetPasswordParams{UserID: userID, Password: string(hashedNewPassword)}); err != nil {
+		slog.ErrorContext(r.Context(), "Database error changing password", "handler", "ChangePassword", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to change password"}`, http.StatusInternalServerError)
+		return
+	}
+
+	// Other devices signed in with the old password are signed out; the
+	// calling one stays, unless its token predates sessions.
+	if sessionID.Valid {
+		var revoked []pgtype.UUID
+		revoked, err = s.db.RevokeOtherAuthSessions(r.Context(), db.RevokeOtherAuthSessionsParams{UserID: userID, SessionID: sessionID})
+		if err == nil {
+			err = s.revokeSessionTokens(r.Context(), revoked...)
+		}
+	} else {
+		err = s.revokeAllSessions(r.Context(), userID)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error revoking other sessions", "handler", "ChangePassword", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "password changed, but signing out other devices failed"}`, http.StatusInternalServerError)
+		return
+	}
+	s.recordAuditEvent(r, auditPasswordChanged, userID, sessionID, nil)
+
+	response := ChangePasswordResponse{
+		Message: "Password changed. Other devices have been signed out.",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ChangePassword", "err", err)
+	}
+}
+
+func (s *Server) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "ChangeEmail", "err", err)
+		return
+	}
+
+	var changeEmailRequest ChangeEmailRequest
+	if err := json.Unmarshal(bodyBytes, &changeEmailRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "ChangeEmail", "err", err)
+		return
+	}
+
+	newEmail := strings.TrimSpace(changeEmailRequest.NewEmail)
+	if newEmail == "" || changeEmailRequest.Password == "" {
+		http.Error(w, `{"message": "new email and password are required"}`, http.StatusBadRequest)
+		return
+	}
+
+	credentials, err := s.checkPassword(r.Context(), userID, changeEmailRequest.Password)
+	if err != nil {
+		writeCheckPasswordError(w, r, "ChangeEmail", userID, err)
+		return
+	}
+	if strings.EqualFold(credentials.Email, newEmail) {
+		http.Error(w, `{"message": "this is already your email address"}`, http.StatusBadRequest)
+		return
+	}
+
+	_, err = s.db.GetUserByEmail(r.Context(), newEmail)
+	if err == nil {
+		http.Error(w, `{"message": "email address is already in use"}`, http.StatusConflict)
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, pgx.ErrNoRows) {
+		slog.ErrorContext(r.Context(), "Error checking email availability", "handler", "ChangeEmail", "email", logging.Email(newEmail), "err", err)
+		http.Error(w, `{"message": "failed to check email availability"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.issueCode(r, userID, newEmail, purposeEmailChange); err != nil {
+		writeIssueCodeError(w, r, "ChangeEmail", newEmail, err)
+		return
+	}
+
+	response := ChangeEmailResponse{
+		Message: "A confirmation code has been sent to the new address.",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ChangeEmail", "err", err)
+	}
+}
+
+func (s *Server) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	userID, sessionID, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "ConfirmEmailChange", "err", err)
+		return
+	}
+
+	var confirmEmailChangeRequest ConfirmEmailChangeRequest
+	if err := json.Unmarshal(bodyBytes, &confirmEmailChangeRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "ConfirmEmailChange", "err", err)
+		return
+	}
+
+	if confirmEmailChangeRequest.Code == "" {
+		http.Error(w, `{"message": "code is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	active, remaining, err := s.checkCode(r.Context(), userID, purposeEmailChange, confirmEmailChangeRequest.Code)
+	if err != nil {
+		writeCodeError(w, r, "ConfirmEmailChange", remaining, err)
+		return
+	}
+	if !active.NewEmail.Valid {
+		slog.ErrorContext(r.Context(), "Email change code has no address", "handler", "ConfirmEmailChange", "code_id", active.ID, "user_id", userID)
+		http.Error(w, `{"message": "code is invalid or expired, request a new one"}`, http.StatusBadRequest)
+		return
+	}
+
+	_, err = s.db.UpdateUserEmail(r.Context(), db.UpdateUserEmailParams{UserID: userID, Email: active.NewEmail.String})
+	if err != nil {
+		if isUniqueViolation(err) {
+			http.Error(w, `{"message": "email address has been taken in the meantime"}`, http.StatusConflict)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Database error changing email", "handler", "ConfirmEmailChange", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to change email"}`, http.StatusInternalServerError)
+		return
+	}
+	s.recordAuditEvent(r, auditEmailChanged, userID, sessionID, nil)
+
+	response := ConfirmEmailChangeResponse{
+		Message: "Email address changed.",
+		Email:   active.NewEmail.String,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ConfirmEmailChange", "err", err)
+	}
+}
+
+func (s *Server) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, sessionID, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "DeleteAccount", "err", err)
+		return
+	}
+
+	var deleteAccountRequest DeleteAccountRequest
+	if err := json.Unmarshal(bodyBytes, &deleteAccountRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "DeleteAccount", "err", err)
+		return
+	}
+
+	if deleteAccountRequest.Password == "" {
+		http.Error(w, `{"message": "password is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.checkPassword(r.Context(), userID, deleteAccountRequest.Password); err != nil {
+		writeCheckPasswordError(w, r, "DeleteAccount", userID, err)
+		return
+	}
+
+	// Tokens are revoked first: deleting the user removes the sessions they
+	// are found by.
+	err = s.revokeAllSessions(r.Context(), userID)
+	if err == nil {
+		if tokenID, _ := r.Context().Value(tools.TokenIDContextKey).(string); tokenID != "" {
+			err = s.revocations.RevokeToken(r.Context(), tokenID)
+		}
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error revoking tokens", "handler", "DeleteAccount", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to delete account"}`, http.StatusInternalServerError)
+		return
+	}
+
+	// Recorded before the user row is gone; the event's user_id is cleared
+	// by the deletion, so the ID is kept in the details.
+	s.recordAuditEvent(r, auditAccountDeleted, userID, sessionID, map[string]any{
+		"user_id": uuid.UUID(userID.Bytes).String(),
+	})
+
+	// Sessions and verification codes are deleted with the user row.
+	if _, err := s.db.DeleteUser(r.Context(), userID); err != nil {
+		slog.ErrorContext(r.Context(), "Database error deleting user", "handler", "DeleteAccount", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to delete account"}`, http.StatusInternalServerError)
+		return
+	}
+	s.deleteChatSessions(uuid.UUID(userID.Bytes).String())
+	s.deleteExports(uuid.UUID(userID.Bytes).String())
+
+	w.WriteHeader(http.StatusNoContent)
+}
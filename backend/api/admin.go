@@ -0,0 +1,67 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (s *Server) LockUser(w http.ResponseWriter, r *http.Request, userId uuid.UUID) {
+	adminID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userID := pgtype.UUID{Bytes: userId, Valid: true}
+	locked, err := s.db.LockUser(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error locking user", "handler", "LockUser", "user_id", userId, "err", err)
+		http.Error(w, `{"message": "failed to lock user"}`, http.StatusInternalServerError)
+		return
+	}
+	if locked == 0 {
+		http.Error(w, `{"message": "user not found or already locked"}`, http.StatusNotFound)
+		return
+	}
+
+	// Locking must take effect immediately, so the user's access tokens are
+	// revoked too instead of being left to expire.
+	if err := s.revokeAllSessions(r.Context(), userID); err != nil {
+		slog.ErrorContext(r.Context(), "Error revoking sessions", "handler", "LockUser", "user_id", userId, "err", err)
+		http.Error(w, `{"message": "failed to revoke sessions of the locked user"}`, http.StatusInternalServerError)
+		return
+	}
+	s.recordAuditEvent(r, auditUserLocked, userID, pgtype.UUID{}, map[string]any{
+		"admin_id": uuid.UUID(adminID.Bytes).String(),
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) UnlockUser(w http.ResponseWriter, r *http.Request, userId uuid.UUID) {
+	adminID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userID := pgtype.UUID{Bytes: userId, Valid: true}
+	unlocked, err := s.db.UnlockUser(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error unlocking user", "handler", "UnlockUser", "user_id", userId, "err", err)
+		http.Error(w, `{"message": "failed to unlock user"}`, http.StatusInternalServerError)
+		return
+	}
+	if unlocked == 0 {
+		http.Error(w, `{"message": "user not found or not locked"}`, http.StatusNotFound)
+		return
+	}
+	s.recordAuditEvent(r, auditUserUnlocked, userID, pgtype.UUID{}, map[string]any{
+		"admin_id": uuid.UUID(adminID.Bytes).String(),
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
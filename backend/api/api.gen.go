@@ -15,19 +15,147 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/oapi-codegen/runtime"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 const (
+	ApiKeyAuthScopes = "ApiKeyAuth.Scopes"
 	BearerAuthScopes = "BearerAuth.Scopes"
 )
 
+// Defines values for CreateApiKeyRequestScopes.
+const (
+	Chat CreateApiKeyRequestScopes = "chat"
+)
+
+// Defines values for ExportStatusStatus.
+const (
+	Failed  ExportStatusStatus = "failed"
+	Pending ExportStatusStatus = "pending"
+	Ready   ExportStatusStatus = "ready"
+)
+
+// Defines values for ResendCodeRequestPurpose.
+const (
+	EmailVerification ResendCodeRequestPurpose = "email_verification"
+	PasswordReset     ResendCodeRequestPurpose = "password_reset"
+)
+
+// Defines values for UpdateUserProfileRequestAnswerLength.
+const (
+	UpdateUserProfileRequestAnswerLengthDetailed UpdateUserProfileRequestAnswerLength = "detailed"
+	UpdateUserProfileRequestAnswerLengthEmpty    UpdateUserProfileRequestAnswerLength = ""
+	UpdateUserProfileRequestAnswerLengthNormal   UpdateUserProfileRequestAnswerLength = "normal"
+	UpdateUserProfileRequestAnswerLengthShort    UpdateUserProfileRequestAnswerLength = "short"
+)
+
+// Defines values for UpdateUserProfileRequestLanguage.
+const (
+	UpdateUserProfileRequestLanguageBe    UpdateUserProfileRequestLanguage = "be"
+	UpdateUserProfileRequestLanguageEmpty UpdateUserProfileRequestLanguage = ""
+	UpdateUserProfileRequestLanguageEn    UpdateUserProfileRequestLanguage = "en"
+	UpdateUserProfileRequestLanguageRu    UpdateUserProfileRequestLanguage = "ru"
+)
+
+// Defines values for UpdateUserProfileRequestUnits.
+const (
+	UpdateUserProfileRequestUnitsEmpty    UpdateUserProfileRequestUnits = ""
+	UpdateUserProfileRequestUnitsImperial UpdateUserProfileRequestUnits = "imperial"
+	UpdateUserProfileRequestUnitsMetric   UpdateUserProfileRequestUnits = "metric"
+)
+
+// Defines values for UserProfileAnswerLength.
+const (
+	UserProfileAnswerLengthDetailed UserProfileAnswerLength = "detailed"
+	UserProfileAnswerLengthEmpty    UserProfileAnswerLength = ""
+	UserProfileAnswerLengthNormal   UserProfileAnswerLength = "normal"
+	UserProfileAnswerLengthShort    UserProfileAnswerLength = "short"
+)
+
+// Defines values for UserProfileLanguage.
+const (
+	UserProfileLanguageBe    UserProfileLanguage = "be"
+	UserProfileLanguageEmpty UserProfileLanguage = ""
+	UserProfileLanguageEn    UserProfileLanguage = "en"
+	UserProfileLanguageRu    UserProfileLanguage = "ru"
+)
+
+// Defines values for UserProfileUnits.
+const (
+	UserProfileUnitsEmpty    UserProfileUnits = ""
+	UserProfileUnitsImperial UserProfileUnits = "imperial"
+	UserProfileUnitsMetric   UserProfileUnits = "metric"
+)
+
+// ApiKey defines model for ApiKey.
+type ApiKey struct {
+	CreatedAt  time.Time          `json:"created_at"`
+	ExpiresAt  *time.Time         `json:"expires_at,omitempty"`
+	Id         openapi_types.UUID `json:"id"`
+	LastUsedAt *time.Time         `json:"last_used_at,omitempty"`
+
+	// Owner Partner or service the key was issued to
+	Owner string `json:"owner"`
+
+	// Prefix First characters of the key, to tell keys apart
+	Prefix             string     `json:"prefix"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+	Scopes             []string   `json:"scopes"`
+}
+
+// ApiKeyList defines model for ApiKeyList.
+type ApiKeyList struct {
+	ApiKeys []ApiKey `json:"api_keys"`
+}
+
+// ChangeEmailRequest defines model for ChangeEmailRequest.
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email"`
+
+	// Password Current password, required to confirm the change
+	Password string `json:"password"`
+}
+
+// ChangeEmailResponse defines model for ChangeEmailResponse.
+type ChangeEmailResponse struct {
+	Message string `json:"message"`
+}
+
+// ChangePasswordRequest defines model for ChangePasswordRequest.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ChangePasswordResponse defines model for ChangePasswordResponse.
+type ChangePasswordResponse struct {
+	Message string `json:"message"`
+}
+
+// ConfirmEmailChangeRequest defines model for ConfirmEmailChangeRequest.
+type ConfirmEmailChangeRequest struct {
+	Code string `json:"code"`
+}
+
+// ConfirmEmailChangeResponse defines model for ConfirmEmailChangeResponse.
+type ConfirmEmailChangeResponse struct {
+	// Email The new email address
+	Email   string `json:"email"`
+	Message string `json:"message"`
+}
+
 // ConfirmEmailRequest defines model for ConfirmEmailRequest.
 type ConfirmEmailRequest struct {
-	Code  string `json:"code"`
-	Email string `json:"email"`
+	Code string `json:"code"`
+
+	// DeviceName Human-readable name of the device, shown in the session list
+	DeviceName *string `json:"device_name,omitempty"`
+	Email      string  `json:"email"`
 }
 
 // ConfirmEmailResponse defines model for ConfirmEmailResponse.
@@ -39,15 +167,166 @@ type ConfirmEmailResponse struct {
 	Token string `json:"token"`
 }
 
+// CreateApiKeyRequest defines model for CreateApiKeyRequest.
+type CreateApiKeyRequest struct {
+	// ExpiresInDays Days until the key expires. Keys without it never expire.
+	ExpiresInDays      *int                        `json:"expires_in_days,omitempty"`
+	Owner              string                      `json:"owner"`
+	RateLimitPerMinute *int                        `json:"rate_limit_per_minute,omitempty"`
+	Scopes             []CreateApiKeyRequestScopes `json:"scopes"`
+}
+
+// CreateApiKeyRequestScopes defines model for CreateApiKeyRequest.Scopes.
+type CreateApiKeyRequestScopes string
+
+// CreateApiKeyResponse defines model for CreateApiKeyResponse.
+type CreateApiKeyResponse struct {
+	ApiKey ApiKey `json:"api_key"`
+
+	// Key The API key. It is only shown once; store it securely.
+	Key string `json:"key"`
+}
+
+// CreateFavoritePharmacyRequest defines model for CreateFavoritePharmacyRequest.
+type CreateFavoritePharmacyRequest struct {
+	Label      *string `json:"label,omitempty"`
+	LocationId int32   `json:"location_id"`
+}
+
+// DataExport Everything stored about the user. Password hashes and token secrets are never included.
+type DataExport struct {
+	Account            ExportAccount      `json:"account"`
+	AuditEvents        []ExportAuditEvent `json:"audit_events"`
+	Chats              []ExportChat       `json:"chats"`
+	FavoritePharmacies []FavoritePharmacy `json:"favorite_pharmacies"`
+	GeneratedAt        time.Time          `json:"generated_at"`
+
+	// Profile Preferences the assistant applies on every device. Empty strings mean "not set".
+	Profile     *UserProfile `json:"profile,omitempty"`
+	SavedPlaces []SavedPlace `json:"saved_places"`
+	Sessions    []Session    `json:"sessions"`
+}
+
+// DeleteAccountRequest defines model for DeleteAccountRequest.
+type DeleteAccountRequest struct {
+	// Password Current password, required to confirm the deletion
+	Password string `json:"password"`
+}
+
 // Error defines model for Error.
 type Error struct {
 	Message string `json:"message"`
 }
 
+// ExportAccount defines model for ExportAccount.
+type ExportAccount struct {
+	Email         string             `json:"email"`
+	EmailVerified bool               `json:"email_verified"`
+	Id            openapi_types.UUID `json:"id"`
+	Locked        bool               `json:"locked"`
+	Roles         []string           `json:"roles"`
+}
+
+// ExportAuditEvent defines model for ExportAuditEvent.
+type ExportAuditEvent struct {
+	CreatedAt time.Time              `json:"created_at"`
+	Details   map[string]interface{} `json:"details"`
+	IpAddress string                 `json:"ip_address"`
+	Type      string                 `json:"type"`
+	UserAgent string                 `json:"user_agent"`
+}
+
+// ExportChat defines model for ExportChat.
+type ExportChat struct {
+	CreatedAt time.Time           `json:"created_at"`
+	Id        string              `json:"id"`
+	Messages  []ExportChatMessage `json:"messages"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// ExportChatMessage defines model for ExportChatMessage.
+type ExportChatMessage struct {
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+// ExportStatus defines model for ExportStatus.
+type ExportStatus struct {
+	CreatedAt time.Time `json:"created_at"`
+
+	// ExpiresAt When a ready export is deleted
+	ExpiresAt *time.Time         `json:"expires_at,omitempty"`
+	Id        openapi_types.UUID `json:"id"`
+	Status    ExportStatusStatus `json:"status"`
+}
+
+// ExportStatusStatus defines model for ExportStatus.Status.
+type ExportStatusStatus string
+
+// FavoritePharmacy defines model for FavoritePharmacy.
+type FavoritePharmacy struct {
+	CreatedAt time.Time `json:"created_at"`
+
+	// Label The user's own name for the pharmacy, e.g. "у дома"
+	Label          string  `json:"label"`
+	Latitude       float64 `json:"latitude"`
+	LocationId     int32   `json:"location_id"`
+	Longitude      float64 `json:"longitude"`
+	PharmacyName   string  `json:"pharmacy_name"`
+	PharmacyNumber string  `json:"pharmacy_number"`
+	Phone          string  `json:"phone"`
+
+	// Text Full description with the address and opening hours
+	Text string `json:"text"`
+}
+
+// FavoritePharmacyList defines model for FavoritePharmacyList.
+type FavoritePharmacyList struct {
+	Favorites []FavoritePharmacy `json:"favorites"`
+}
+
+// GuestRequest defines model for GuestRequest.
+type GuestRequest struct {
+	// DeviceKey Random secret generated once per app install and kept on the
+	// device. Presenting it again signs back in to the same guest.
+	DeviceKey string `json:"device_key"`
+
+	// DeviceName Human-readable name of the device, shown in the session list
+	DeviceName *string `json:"device_name,omitempty"`
+}
+
+// JsonWebKey Public key for verifying access tokens (RFC 7517)
+type JsonWebKey struct {
+	Alg string  `json:"alg"`
+	Crv *string `json:"crv,omitempty"`
+
+	// E RSA public exponent (base64url)
+	E *string `json:"e,omitempty"`
+
+	// Kid Key ID, matches the `kid` header of tokens signed with this key
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+
+	// N RSA modulus (base64url)
+	N   *string `json:"n,omitempty"`
+	Use string  `json:"use"`
+
+	// X Ed25519 public key (base64url)
+	X *string `json:"x,omitempty"`
+}
+
+// JsonWebKeySet defines model for JsonWebKeySet.
+type JsonWebKeySet struct {
+	// Keys Every key a currently valid token may be signed with. Empty while tokens are signed with HS256.
+	Keys []JsonWebKey `json:"keys"`
+}
+
 // LoginRequest defines model for LoginRequest.
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	// DeviceName Human-readable name of the device, shown in the session list
+	DeviceName *string `json:"device_name,omitempty"`
+	Email      string  `json:"email"`
+	Password   string  `json:"password"`
 }
 
 // LoginResponse defines model for LoginResponse.
@@ -72,9 +351,12 @@ type PasswordResetCodeResponse struct {
 
 // PasswordResetWithCodeRequest defines model for PasswordResetWithCodeRequest.
 type PasswordResetWithCodeRequest struct {
-	Code        string `json:"code"`
-	Email       string `json:"email"`
-	NewPassword string `json:"new_password"`
+	Code string `json:"code"`
+
+	// DeviceName Human-readable name of the device, shown in the session list
+	DeviceName  *string `json:"device_name,omitempty"`
+	Email       string  `json:"email"`
+	NewPassword string  `json:"new_password"`
 }
 
 // PasswordResetWithCodeResponse defines model for PasswordResetWithCodeResponse.
@@ -111,12 +393,138 @@ type RegisterResponse struct {
 	Message string `json:"message"`
 }
 
+// ResendCodeRequest defines model for ResendCodeRequest.
+type ResendCodeRequest struct {
+	Email   string                   `json:"email"`
+	Purpose ResendCodeRequestPurpose `json:"purpose"`
+}
+
+// ResendCodeRequestPurpose defines model for ResendCodeRequest.Purpose.
+type ResendCodeRequestPurpose string
+
+// ResendCodeResponse defines model for ResendCodeResponse.
+type ResendCodeResponse struct {
+	Message string `json:"message"`
+}
+
+// RevokeSessionsResponse defines model for RevokeSessionsResponse.
+type RevokeSessionsResponse struct {
+	// Revoked Number of sessions revoked
+	Revoked int `json:"revoked"`
+}
+
+// SavedPlace defines model for SavedPlace.
+type SavedPlace struct {
+	CreatedAt time.Time          `json:"created_at"`
+	Id        openapi_types.UUID `json:"id"`
+	Latitude  float64            `json:"latitude"`
+	Longitude float64            `json:"longitude"`
+	Name      string             `json:"name"`
+}
+
+// SavedPlaceList defines model for SavedPlaceList.
+type SavedPlaceList struct {
+	Places []SavedPlace `json:"places"`
+}
+
+// SavedPlaceRequest defines model for SavedPlaceRequest.
+type SavedPlaceRequest struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+
+	// Name Name the user says in queries, e.g. "дом" or "работа". Must not contain line breaks or control characters.
+	Name string `json:"name"`
+}
+
+// Session defines model for Session.
+type Session struct {
+	CreatedAt time.Time `json:"created_at"`
+
+	// Current Whether this is the session the request was made from
+	Current    bool               `json:"current"`
+	DeviceName string             `json:"device_name"`
+	Id         openapi_types.UUID `json:"id"`
+	IpAddress  string             `json:"ip_address"`
+	LastUsedAt time.Time          `json:"last_used_at"`
+	UserAgent  string             `json:"user_agent"`
+}
+
+// SessionList defines model for SessionList.
+type SessionList struct {
+	Sessions []Session `json:"sessions"`
+}
+
 // Token defines model for Token.
 type Token struct {
 	// Token JWT token
 	Token string `json:"token"`
 }
 
+// UpdateFavoritePharmacyRequest defines model for UpdateFavoritePharmacyRequest.
+type UpdateFavoritePharmacyRequest struct {
+	Label string `json:"label"`
+}
+
+// UpdateUserProfileRequest Fields to change. Omitted fields keep their value; an empty string resets one.
+type UpdateUserProfileRequest struct {
+	AnswerLength *UpdateUserProfileRequestAnswerLength `json:"answer_length,omitempty"`
+
+	// DefaultCity Must not contain line breaks or control characters.
+	DefaultCity *string `json:"default_city,omitempty"`
+
+	// DisplayName Must not contain line breaks or control characters.
+	DisplayName *string                           `json:"display_name,omitempty"`
+	Language    *UpdateUserProfileRequestLanguage `json:"language,omitempty"`
+	Units       *UpdateUserProfileRequestUnits    `json:"units,omitempty"`
+}
+
+// UpdateUserProfileRequestAnswerLength defines model for UpdateUserProfileRequest.AnswerLength.
+type UpdateUserProfileRequestAnswerLength string
+
+// UpdateUserProfileRequestLanguage defines model for UpdateUserProfileRequest.Language.
+type UpdateUserProfileRequestLanguage string
+
+// UpdateUserProfileRequestUnits defines model for UpdateUserProfileRequest.Units.
+type UpdateUserProfileRequestUnits string
+
+// UpgradeGuestRequest defines model for UpgradeGuestRequest.
+type UpgradeGuestRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// UpgradeGuestResponse defines model for UpgradeGuestResponse.
+type UpgradeGuestResponse struct {
+	Message string `json:"message"`
+}
+
+// UserProfile Preferences the assistant applies on every device. Empty strings mean "not set".
+type UserProfile struct {
+	AnswerLength UserProfileAnswerLength `json:"answer_length"`
+
+	// DefaultCity City to search in when the user does not name one
+	DefaultCity string `json:"default_city"`
+
+	// DisplayName How the assistant addresses the user
+	DisplayName string `json:"display_name"`
+
+	// Language Language of the answers
+	Language UserProfileLanguage `json:"language"`
+
+	// Units Units for distances
+	Units     UserProfileUnits `json:"units"`
+	UpdatedAt *time.Time       `json:"updated_at,omitempty"`
+}
+
+// UserProfileAnswerLength defines model for UserProfile.AnswerLength.
+type UserProfileAnswerLength string
+
+// UserProfileLanguage Language of the answers
+type UserProfileLanguage string
+
+// UserProfileUnits Units for distances
+type UserProfileUnits string
+
 // ChatMultipartBody defines parameters for Chat.
 type ChatMultipartBody struct {
 	Audio *openapi_types.File `json:"audio,omitempty"`
@@ -131,9 +539,33 @@ type ChatMultipartBody struct {
 	SessionId *string `json:"session_id,omitempty"`
 }
 
+// DeleteAccountJSONRequestBody defines body for DeleteAccount for application/json ContentType.
+type DeleteAccountJSONRequestBody = DeleteAccountRequest
+
+// ChangeEmailJSONRequestBody defines body for ChangeEmail for application/json ContentType.
+type ChangeEmailJSONRequestBody = ChangeEmailRequest
+
+// ConfirmEmailChangeJSONRequestBody defines body for ConfirmEmailChange for application/json ContentType.
+type ConfirmEmailChangeJSONRequestBody = ConfirmEmailChangeRequest
+
+// ChangePasswordJSONRequestBody defines body for ChangePassword for application/json ContentType.
+type ChangePasswordJSONRequestBody = ChangePasswordRequest
+
+// UpdateProfileJSONRequestBody defines body for UpdateProfile for application/json ContentType.
+type UpdateProfileJSONRequestBody = UpdateUserProfileRequest
+
+// CreateApiKeyJSONRequestBody defines body for CreateApiKey for application/json ContentType.
+type CreateApiKeyJSONRequestBody = CreateApiKeyRequest
+
 // ConfirmEmailJSONRequestBody defines body for ConfirmEmail for application/json ContentType.
 type ConfirmEmailJSONRequestBody = ConfirmEmailRequest
 
+// GuestLoginJSONRequestBody defines body for GuestLogin for application/json ContentType.
+type GuestLoginJSONRequestBody = GuestRequest
+
+// UpgradeGuestJSONRequestBody defines body for UpgradeGuest for application/json ContentType.
+type UpgradeGuestJSONRequestBody = UpgradeGuestRequest
+
 // LoginJSONRequestBody defines body for Login for application/json ContentType.
 type LoginJSONRequestBody = LoginRequest
 
@@ -149,14 +581,80 @@ type RefreshTokensJSONRequestBody = RefreshRequest
 // RegisterJSONRequestBody defines body for Register for application/json ContentType.
 type RegisterJSONRequestBody = RegisterRequest
 
+// ResendCodeJSONRequestBody defines body for ResendCode for application/json ContentType.
+type ResendCodeJSONRequestBody = ResendCodeRequest
+
 // ChatMultipartRequestBody defines body for Chat for multipart/form-data ContentType.
 type ChatMultipartRequestBody ChatMultipartBody
 
+// CreateFavoritePharmacyJSONRequestBody defines body for CreateFavoritePharmacy for application/json ContentType.
+type CreateFavoritePharmacyJSONRequestBody = CreateFavoritePharmacyRequest
+
+// UpdateFavoritePharmacyJSONRequestBody defines body for UpdateFavoritePharmacy for application/json ContentType.
+type UpdateFavoritePharmacyJSONRequestBody = UpdateFavoritePharmacyRequest
+
+// CreateSavedPlaceJSONRequestBody defines body for CreateSavedPlace for application/json ContentType.
+type CreateSavedPlaceJSONRequestBody = SavedPlaceRequest
+
+// UpdateSavedPlaceJSONRequestBody defines body for UpdateSavedPlace for application/json ContentType.
+type UpdateSavedPlaceJSONRequestBody = SavedPlaceRequest
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// Public keys for verifying access tokens
+	// (GET /.well-known/jwks.json)
+	GetJwks(w http.ResponseWriter, r *http.Request)
+	// Delete the account
+	// (POST /api/account/delete)
+	DeleteAccount(w http.ResponseWriter, r *http.Request)
+	// Start moving the account to a new email address
+	// (POST /api/account/email)
+	ChangeEmail(w http.ResponseWriter, r *http.Request)
+	// Confirm the new email address
+	// (POST /api/account/email/confirm)
+	ConfirmEmailChange(w http.ResponseWriter, r *http.Request)
+	// Export everything stored about the current user
+	// (POST /api/account/export)
+	ExportAccountData(w http.ResponseWriter, r *http.Request)
+	// Status of a background export
+	// (GET /api/account/export/{exportId})
+	GetAccountExport(w http.ResponseWriter, r *http.Request, exportId openapi_types.UUID)
+	// Download a ready background export
+	// (GET /api/account/export/{exportId}/download)
+	DownloadAccountExport(w http.ResponseWriter, r *http.Request, exportId openapi_types.UUID)
+	// Change the password
+	// (POST /api/account/password)
+	ChangePassword(w http.ResponseWriter, r *http.Request)
+	// Get the current user's profile
+	// (GET /api/account/profile)
+	GetProfile(w http.ResponseWriter, r *http.Request)
+	// Update the current user's profile
+	// (PATCH /api/account/profile)
+	UpdateProfile(w http.ResponseWriter, r *http.Request)
+	// List API keys
+	// (GET /api/admin/api-keys)
+	ListApiKeys(w http.ResponseWriter, r *http.Request)
+	// Issue an API key
+	// (POST /api/admin/api-keys)
+	CreateApiKey(w http.ResponseWriter, r *http.Request)
+	// Revoke an API key
+	// (DELETE /api/admin/api-keys/{keyId})
+	RevokeApiKey(w http.ResponseWriter, r *http.Request, keyId openapi_types.UUID)
+	// Unlock a user account
+	// (DELETE /api/admin/users/{userId}/lock)
+	UnlockUser(w http.ResponseWriter, r *http.Request, userId openapi_types.UUID)
+	// Lock a user account
+	// (POST /api/admin/users/{userId}/lock)
+	LockUser(w http.ResponseWriter, r *http.Request, userId openapi_types.UUID)
 	// Confirm user email address
 	// (POST /api/auth/confirm-email)
 	ConfirmEmail(w http.ResponseWriter, r *http.Request)
+	// Sign in as a guest
+	// (POST /api/auth/guest)
+	GuestLogin(w http.ResponseWriter, r *http.Request)
+	// Turn the current guest into a full account
+	// (POST /api/auth/guest/upgrade)
+	UpgradeGuest(w http.ResponseWriter, r *http.Request)
 	// Login to get a JWT token
 	// (POST /api/auth/login)
 	Login(w http.ResponseWriter, r *http.Request)
@@ -175,12 +673,48 @@ type ServerInterface interface {
 	// Register a new user
 	// (POST /api/auth/register)
 	Register(w http.ResponseWriter, r *http.Request)
+	// Send a new email verification or password reset code
+	// (POST /api/auth/resend-code)
+	ResendCode(w http.ResponseWriter, r *http.Request)
+	// List the current user's active sessions
+	// (GET /api/auth/sessions)
+	ListSessions(w http.ResponseWriter, r *http.Request)
+	// Revoke every session of the current user except the current one
+	// (POST /api/auth/sessions/revoke-others)
+	RevokeOtherSessions(w http.ResponseWriter, r *http.Request)
+	// Revoke one of the current user's sessions
+	// (DELETE /api/auth/sessions/{sessionId})
+	RevokeSession(w http.ResponseWriter, r *http.Request, sessionId openapi_types.UUID)
 	// Validate current authentication token
 	// (GET /api/auth/validate-token)
 	ValidateToken(w http.ResponseWriter, r *http.Request)
 	// Chat with voice assistant (send audio, get text)
 	// (POST /api/chat)
 	Chat(w http.ResponseWriter, r *http.Request)
+	// List the user's favorite pharmacies
+	// (GET /api/favorites/pharmacies)
+	ListFavoritePharmacies(w http.ResponseWriter, r *http.Request)
+	// Add a pharmacy to the favorites
+	// (POST /api/favorites/pharmacies)
+	CreateFavoritePharmacy(w http.ResponseWriter, r *http.Request)
+	// Remove a pharmacy from the favorites
+	// (DELETE /api/favorites/pharmacies/{locationId})
+	DeleteFavoritePharmacy(w http.ResponseWriter, r *http.Request, locationId int32)
+	// Rename a favorite pharmacy
+	// (PATCH /api/favorites/pharmacies/{locationId})
+	UpdateFavoritePharmacy(w http.ResponseWriter, r *http.Request, locationId int32)
+	// List the user's saved places
+	// (GET /api/places)
+	ListSavedPlaces(w http.ResponseWriter, r *http.Request)
+	// Save a place
+	// (POST /api/places)
+	CreateSavedPlace(w http.ResponseWriter, r *http.Request)
+	// Delete a saved place
+	// (DELETE /api/places/{placeId})
+	DeleteSavedPlace(w http.ResponseWriter, r *http.Request, placeId openapi_types.UUID)
+	// Rename or move a saved place
+	// (PUT /api/places/{placeId})
+	UpdateSavedPlace(w http.ResponseWriter, r *http.Request, placeId openapi_types.UUID)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -192,11 +726,11 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(http.Handler) http.Handler
 
-// ConfirmEmail operation middleware
-func (siw *ServerInterfaceWrapper) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
+// GetJwks operation middleware
+func (siw *ServerInterfaceWrapper) GetJwks(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.ConfirmEmail(w, r)
+		siw.Handler.GetJwks(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -206,11 +740,17 @@ func (siw *ServerInterfaceWrapper) ConfirmEmail(w http.ResponseWriter, r *http.R
 	handler.ServeHTTP(w, r)
 }
 
-// Login operation middleware
-func (siw *ServerInterfaceWrapper) Login(w http.ResponseWriter, r *http.Request) {
+// DeleteAccount operation middleware
+func (siw *ServerInterfaceWrapper) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
+
+	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.Login(w, r)
+		siw.Handler.DeleteAccount(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -220,17 +760,17 @@ func (siw *ServerInterfaceWrapper) Login(w http.ResponseWriter, r *http.Request)
 	handler.ServeHTTP(w, r)
 }
 
-// Logout operation middleware
-func (siw *ServerInterfaceWrapper) Logout(w http.ResponseWriter, r *http.Request) {
+// ChangeEmail operation middleware
+func (siw *ServerInterfaceWrapper) ChangeEmail(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
 
 	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.Logout(w, r)
+		siw.Handler.ChangeEmail(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -240,11 +780,17 @@ func (siw *ServerInterfaceWrapper) Logout(w http.ResponseWriter, r *http.Request
 	handler.ServeHTTP(w, r)
 }
 
-// RequestPasswordResetCode operation middleware
-func (siw *ServerInterfaceWrapper) RequestPasswordResetCode(w http.ResponseWriter, r *http.Request) {
+// ConfirmEmailChange operation middleware
+func (siw *ServerInterfaceWrapper) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
+
+	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.RequestPasswordResetCode(w, r)
+		siw.Handler.ConfirmEmailChange(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -254,11 +800,17 @@ func (siw *ServerInterfaceWrapper) RequestPasswordResetCode(w http.ResponseWrite
 	handler.ServeHTTP(w, r)
 }
 
-// ResetPasswordWithCode operation middleware
-func (siw *ServerInterfaceWrapper) ResetPasswordWithCode(w http.ResponseWriter, r *http.Request) {
+// ExportAccountData operation middleware
+func (siw *ServerInterfaceWrapper) ExportAccountData(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
+
+	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.ResetPasswordWithCode(w, r)
+		siw.Handler.ExportAccountData(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -268,11 +820,28 @@ func (siw *ServerInterfaceWrapper) ResetPasswordWithCode(w http.ResponseWriter,
 	handler.ServeHTTP(w, r)
 }
 
-// RefreshTokens operation middleware
-func (siw *ServerInterfaceWrapper) RefreshTokens(w http.ResponseWriter, r *http.Request) {
+// GetAccountExport operation middleware
+func (siw *ServerInterfaceWrapper) GetAccountExport(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "exportId" -------------
+	var exportId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "exportId", r.PathValue("exportId"), &exportId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "exportId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
+
+	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.RefreshTokens(w, r)
+		siw.Handler.GetAccountExport(w, r, exportId)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -282,11 +851,28 @@ func (siw *ServerInterfaceWrapper) RefreshTokens(w http.ResponseWriter, r *http.
 	handler.ServeHTTP(w, r)
 }
 
-// Register operation middleware
-func (siw *ServerInterfaceWrapper) Register(w http.ResponseWriter, r *http.Request) {
+// DownloadAccountExport operation middleware
+func (siw *ServerInterfaceWrapper) DownloadAccountExport(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "exportId" -------------
+	var exportId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "exportId", r.PathValue("exportId"), &exportId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "exportId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
+
+	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.Register(w, r)
+		siw.Handler.DownloadAccountExport(w, r, exportId)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -296,17 +882,17 @@ func (siw *ServerInterfaceWrapper) Register(w http.ResponseWriter, r *http.Reque
 	handler.ServeHTTP(w, r)
 }
 
-// ValidateToken operation middleware
-func (siw *ServerInterfaceWrapper) ValidateToken(w http.ResponseWriter, r *http.Request) {
+// ChangePassword operation middleware
+func (siw *ServerInterfaceWrapper) ChangePassword(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
 
 	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.ValidateToken(w, r)
+		siw.Handler.ChangePassword(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -316,17 +902,647 @@ func (siw *ServerInterfaceWrapper) ValidateToken(w http.ResponseWriter, r *http.
 	handler.ServeHTTP(w, r)
 }
 
-// Chat operation middleware
-func (siw *ServerInterfaceWrapper) Chat(w http.ResponseWriter, r *http.Request) {
+// GetProfile operation middleware
+func (siw *ServerInterfaceWrapper) GetProfile(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
 
 	r = r.WithContext(ctx)
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.Chat(w, r)
+		siw.Handler.GetProfile(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// UpdateProfile operation middleware
+func (siw *ServerInterfaceWrapper) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpdateProfile(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListApiKeys operation middleware
+func (siw *ServerInterfaceWrapper) ListApiKeys(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"admin"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListApiKeys(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateApiKey operation middleware
+func (siw *ServerInterfaceWrapper) CreateApiKey(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"admin"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateApiKey(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// RevokeApiKey operation middleware
+func (siw *ServerInterfaceWrapper) RevokeApiKey(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "keyId" -------------
+	var keyId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "keyId", r.PathValue("keyId"), &keyId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "keyId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"admin"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RevokeApiKey(w, r, keyId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// UnlockUser operation middleware
+func (siw *ServerInterfaceWrapper) UnlockUser(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "userId" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", r.PathValue("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "userId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"admin"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UnlockUser(w, r, userId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// LockUser operation middleware
+func (siw *ServerInterfaceWrapper) LockUser(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "userId" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", r.PathValue("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "userId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"admin"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.LockUser(w, r, userId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ConfirmEmail operation middleware
+func (siw *ServerInterfaceWrapper) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ConfirmEmail(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GuestLogin operation middleware
+func (siw *ServerInterfaceWrapper) GuestLogin(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GuestLogin(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// UpgradeGuest operation middleware
+func (siw *ServerInterfaceWrapper) UpgradeGuest(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpgradeGuest(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Login operation middleware
+func (siw *ServerInterfaceWrapper) Login(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Login(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Logout operation middleware
+func (siw *ServerInterfaceWrapper) Logout(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Logout(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// RequestPasswordResetCode operation middleware
+func (siw *ServerInterfaceWrapper) RequestPasswordResetCode(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RequestPasswordResetCode(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ResetPasswordWithCode operation middleware
+func (siw *ServerInterfaceWrapper) ResetPasswordWithCode(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ResetPasswordWithCode(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// RefreshTokens operation middleware
+func (siw *ServerInterfaceWrapper) RefreshTokens(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RefreshTokens(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Register operation middleware
+func (siw *ServerInterfaceWrapper) Register(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Register(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ResendCode operation middleware
+func (siw *ServerInterfaceWrapper) ResendCode(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ResendCode(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListSessions operation middleware
+func (siw *ServerInterfaceWrapper) ListSessions(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListSessions(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// RevokeOtherSessions operation middleware
+func (siw *ServerInterfaceWrapper) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RevokeOtherSessions(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// RevokeSession operation middleware
+func (siw *ServerInterfaceWrapper) RevokeSession(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "sessionId", r.PathValue("sessionId"), &sessionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"account"})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RevokeSession(w, r, sessionId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ValidateToken operation middleware
+func (siw *ServerInterfaceWrapper) ValidateToken(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ValidateToken(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Chat operation middleware
+func (siw *ServerInterfaceWrapper) Chat(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	ctx = context.WithValue(ctx, ApiKeyAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Chat(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListFavoritePharmacies operation middleware
+func (siw *ServerInterfaceWrapper) ListFavoritePharmacies(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListFavoritePharmacies(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateFavoritePharmacy operation middleware
+func (siw *ServerInterfaceWrapper) CreateFavoritePharmacy(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateFavoritePharmacy(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteFavoritePharmacy operation middleware
+func (siw *ServerInterfaceWrapper) DeleteFavoritePharmacy(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "locationId" -------------
+	var locationId int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "locationId", r.PathValue("locationId"), &locationId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "locationId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteFavoritePharmacy(w, r, locationId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// UpdateFavoritePharmacy operation middleware
+func (siw *ServerInterfaceWrapper) UpdateFavoritePharmacy(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "locationId" -------------
+	var locationId int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "locationId", r.PathValue("locationId"), &locationId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "locationId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpdateFavoritePharmacy(w, r, locationId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListSavedPlaces operation middleware
+func (siw *ServerInterfaceWrapper) ListSavedPlaces(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListSavedPlaces(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateSavedPlace operation middleware
+func (siw *ServerInterfaceWrapper) CreateSavedPlace(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateSavedPlace(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteSavedPlace operation middleware
+func (siw *ServerInterfaceWrapper) DeleteSavedPlace(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "placeId" -------------
+	var placeId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "placeId", r.PathValue("placeId"), &placeId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "placeId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteSavedPlace(w, r, placeId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// UpdateSavedPlace operation middleware
+func (siw *ServerInterfaceWrapper) UpdateSavedPlace(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "placeId" -------------
+	var placeId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "placeId", r.PathValue("placeId"), &placeId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "placeId", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{"chat"})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpdateSavedPlace(w, r, placeId)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -456,15 +1672,44 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	m.HandleFunc("GET "+options.BaseURL+"/.well-known/jwks.json", wrapper.GetJwks)
+	m.HandleFunc("POST "+options.BaseURL+"/api/account/delete", wrapper.DeleteAccount)
+	m.HandleFunc("POST "+options.BaseURL+"/api/account/email", wrapper.ChangeEmail)
+	m.HandleFunc("POST "+options.BaseURL+"/api/account/email/confirm", wrapper.ConfirmEmailChange)
+	m.HandleFunc("POST "+options.BaseURL+"/api/account/export", wrapper.ExportAccountData)
+	m.HandleFunc("GET "+options.BaseURL+"/api/account/export/{exportId}", wrapper.GetAccountExport)
+	m.HandleFunc("GET "+options.BaseURL+"/api/account/export/{exportId}/download", wrapper.DownloadAccountExport)
+	m.HandleFunc("POST "+options.BaseURL+"/api/account/password", wrapper.ChangePassword)
+	m.HandleFunc("GET "+options.BaseURL+"/api/account/profile", wrapper.GetProfile)
+	m.HandleFunc("PATCH "+options.BaseURL+"/api/account/profile", wrapper.UpdateProfile)
+	m.HandleFunc("GET "+options.BaseURL+"/api/admin/api-keys", wrapper.ListApiKeys)
+	m.HandleFunc("POST "+options.BaseURL+"/api/admin/api-keys", wrapper.CreateApiKey)
+	m.HandleFunc("DELETE "+options.BaseURL+"/api/admin/api-keys/{keyId}", wrapper.RevokeApiKey)
+	m.HandleFunc("DELETE "+options.BaseURL+"/api/admin/users/{userId}/lock", wrapper.UnlockUser)
+	m.HandleFunc("POST "+options.BaseURL+"/api/admin/users/{userId}/lock", wrapper.LockUser)
 	m.HandleFunc("POST "+options.BaseURL+"/api/auth/confirm-email", wrapper.ConfirmEmail)
+	m.HandleFunc("POST "+options.BaseURL+"/api/auth/guest", wrapper.GuestLogin)
+	m.HandleFunc("POST "+options.BaseURL+"/api/auth/guest/upgrade", wrapper.UpgradeGuest)
 	m.HandleFunc("POST "+options.BaseURL+"/api/auth/login", wrapper.Login)
 	m.HandleFunc("POST "+options.BaseURL+"/api/auth/logout", wrapper.Logout)
 	m.HandleFunc("POST "+options.BaseURL+"/api/auth/password/request-reset-code", wrapper.RequestPasswordResetCode)
 	m.HandleFunc("POST "+options.BaseURL+"/api/auth/password/reset-with-code", wrapper.ResetPasswordWithCode)
 	m.HandleFunc("POST "+options.BaseURL+"/api/auth/refresh", wrapper.RefreshTokens)
 	m.HandleFunc("POST "+options.BaseURL+"/api/auth/register", wrapper.Register)
+	m.HandleFunc("POST "+options.BaseURL+"/api/auth/resend-code", wrapper.ResendCode)
+	m.HandleFunc("GET "+options.BaseURL+"/api/auth/sessions", wrapper.ListSessions)
+	m.HandleFunc("POST "+options.BaseURL+"/api/auth/sessions/revoke-others", wrapper.RevokeOtherSessions)
+	m.HandleFunc("DELETE "+options.BaseURL+"/api/auth/sessions/{sessionId}", wrapper.RevokeSession)
 	m.HandleFunc("GET "+options.BaseURL+"/api/auth/validate-token", wrapper.ValidateToken)
 	m.HandleFunc("POST "+options.BaseURL+"/api/chat", wrapper.Chat)
+	m.HandleFunc("GET "+options.BaseURL+"/api/favorites/pharmacies", wrapper.ListFavoritePharmacies)
+	m.HandleFunc("POST "+options.BaseURL+"/api/favorites/pharmacies", wrapper.CreateFavoritePharmacy)
+	m.HandleFunc("DELETE "+options.BaseURL+"/api/favorites/pharmacies/{locationId}", wrapper.DeleteFavoritePharmacy)
+	m.HandleFunc("PATCH "+options.BaseURL+"/api/favorites/pharmacies/{locationId}", wrapper.UpdateFavoritePharmacy)
+	m.HandleFunc("GET "+options.BaseURL+"/api/places", wrapper.ListSavedPlaces)
+	m.HandleFunc("POST "+options.BaseURL+"/api/places", wrapper.CreateSavedPlace)
+	m.HandleFunc("DELETE "+options.BaseURL+"/api/places/{placeId}", wrapper.DeleteSavedPlace)
+	m.HandleFunc("PUT "+options.BaseURL+"/api/places/{placeId}", wrapper.UpdateSavedPlace)
 
 	return m
 }
@@ -472,30 +1717,125 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+RZ32/bthP/Vwh+v8A2QLHSrXuY39KiAzLsIWjT9qEIAkY8WWwlUr2jnLiF//eBpORY",
-	"vxy7qx2veUosHsnj5+5zvDt+5YkpSqNBW+LTr5ySDArh/31pdKqweFUIlb+GzxWQdZ9LNCWgVeCFEiPB",
-	"/bWLEviUk0WlZ3wZcXDTBkaWEUf4XCkEyacfarEorHMVNdLm5iMk1q3T1oJKown6aiCkCJRdW/MJtPsg",
-	"gRJUpVVG8yl/HYZZGI762o7M++v95diczjkaqbYiQwd6hWiwf4ICiMQMHkasERxa+28zU3rUVmMmiXgp",
-	"iG4Nyu3ttZqxQY0fwFgX9TFfA4F9aSTsDO4gfltuNQbguCG39qLGjpu8qaXRe2WzjQDsHAkiruH2enfX",
-	"8xt1Ju+g/g/glbUuo6boHWXzxjts+EOAN1NkAR8/TN5rMgbrGp3hThRlDh5MNw2Fw4hRlSRAlFb5hF3k",
-	"IAhYkkHyiS1Mhcyrwaxhc0CVLsJHkSSm0nbyIKabYsNlY7C2wt/Jjv0dlxEnSCpUdvHGpShhuxcgEPCs",
-	"spn7deN//WmwEDZsyqOQ0LiVwui9Apm1JV+6hZVOTV/ns4tzlhpkc6MSYIJIkRXashuRfAIt3ULKeoO8",
-	"8xJnK4mzi3Me8TkghZWeTU4npw40U4IWpeJT/pv/5PzDZv4ksShVLCqbxUlIeE5Wblia4KUOZ2/0c8mn",
-	"rbyIBwyB7AsjFyEYawvaTxNlmavET4w/ktH3WZ777/8IKZ/y/8X3aWBc54DxUAK4bBvMYgX+Q/Bgf5Rf",
-	"T0/3pEJNE69D21hegNXQgVyjRb5wyD//jjqFBG5AiXM9F7mSDBus3L7P97/vWwL0rmozYCWauZIgA/dj",
-	"d1kybSxLTaWlU+n3w0BhAbXIGQHOARnUghGnqigELu49mFVO/RCphJQIRI5bYkYuHjhug7a1evzKLXHP",
-	"ldwlmuMc8XnonsjRSrUPzIp2fj0AvxdYI8HjUuDZ4fZNEKRzF5HTUfl6MIg1bAaWCda6D7f1dFPZja7u",
-	"xv+l022RfbxZi6wsN7MZSBZ27l/s3Tt8yE9NZXuOegCHeasdrAbVF5AT1riPQVYoIqVnTLTMUVvrCFyq",
-	"ToL49EM7/flwtbzqeJyzC0sqRNDWR9mtva1JW+OaxyfoaqiTpsQbdsE6GPbq2D0F4NHS/MDBeLxuHzBs",
-	"I8w8oE2YbKUr7gZ3P0A+jcTlVtmM2UxRnQMcZ7JSOxcTrGybsO5G7Mwrxyd39AdJRbCiVNPEOASjuv2e",
-	"x2RVr3nzMLOeXgHwX+GRs86KQ1W4a0N/orlrd6JU3fDZxCAv4BsWtCfmdBpyB+ZKtzs3YJpwelaDdVQF",
-	"8sGTvcsMGiBCWscUMRVUOzKuBCWFNxUTWrb1ph1IEjqNm1hSS+yLIO2m61YMebaH7ccpMtZWfSx2uGLE",
-	"t0ZEjiDkgsGdIktBnT8e6VLp63JEbAk2ZoJpuN2t3vHACwsnq/71DAZI8q4Wu1w9Nuy31r5sopPXb/Jt",
-	"NXZ3kUetsCMGd6XjfPRwrb1LtdtYZlXuDi65hTskmdjQZXnpRjdFyKLKrSoF2jg1WJxIYcUmq4tKKv/0",
-	"kDaPFjdKuxMNPJHlwipbhVqhz9SfiK0EhiYbPXtg9kpiYDoBkTL6WsmB+Vp9roDVIkz5/luq1lrSidFz",
-	"QApQb+nD3zd76qDevNRc49rLW/c2CCMsRVP4U3TegQ4NU8QtCr22YneDy/VhZlK/pncwpnT5rR26Ng5d",
-	"DJ5S4rhbQHKRItye3ffDnwm0DIaJfDvYwp39Jewerjfyq3fKWzSySkJu4oV4xCvM63dMmsbxaodJlptU",
-	"LWhyt/jCl1fLfwIAAP//heVWbOMlAAA=",
+	"H4sIAAAAAAAC/+w97XIkt3GvgpqkylLVcMmj7mSL94s+neyTzhZzH75UtKolONO7C3MWGAEY8tZXrHI5",
+	"P/MjryKn4kolcSmvwHujVAOYGcwMZj94XH6c9he5uxigp9Hd6G+8ixIxywUHrlV08C5SyRRm1Px7mLNv",
+	"YI7/5VLkIDUD830igWpIR1Tjp7GQM/wvSqmGHc1mEMWRnucQHURKS8Yn0UUcwducSVBrPcPSxtiiYGlo",
+	"WEaVHhVqTYDEOQeJw1NQiWS5ZoJHB9ERlZqDJEISBfKMJUD0FMgpzMk5VYQpVUBKtAhNmUsYs7fdOb9i",
+	"UmmSTKmkiQapiBiXk8ZEC6Ihy/CDIjSnUkeILTrLM5z9jI7+aX86TZ8+OAstKamGUcZmTI9ykKMZ44UG",
+	"hMCNZFzDBKQZCmfidE0kqUTkds+ZhpnyJq7HuC+olHQeXZiFfiiYhDQ6+C4yO+bwUuK8mrYP/NinsO+r",
+	"FcTJHyHRuKQlzOdM6S5x0pyNEJcNoP9Rwjg6iP5ht6b1XUfou3aypW9SzRsC6MmU8gk8nVGWvYAfCggB",
+	"xuF8BDgiiMWcKnUuZNqlnieFlMA1KUfEpAQLaScRfMzkzNBTYqDo7mPrTWpAvGWXvpXKBVfQfa0ZKEUn",
+	"5oeaag9LuCi+BElECmRKFTkB4ETh2yDZT4FwOCc0TSUoNVgKeLlUP6xH7nV6NyGxyBz56O7sBSJowYAW",
+	"VJ0pWxOsAu1ayC0fc/udDsi3egqSpIDiSpEpPQOHaTbhkBJR6A9Ert1MQwkW9H4EixRWwJlIV1+pDzkV",
+	"MzX55ZUjK/NzSVwh4RZE7lP/qQrDK2MvdlAte7d18RdHdndHnM6g+86/LWaU70igKT3JgOCg8pCxz8VE",
+	"TcU5J4ybLxUohYyZMaWDp3WPnGq9dSlFVtrO/o2UMJagpiMtToF3X+6F/ZnYnwPQ9jz39ZtXfc+03qMc",
+	"1QQk+ELmYLJHRu8elroO46OUzlUXsi/pXJGCa5ZVyoV7ZkC+QT3gnOmpKDRhmnA4A+l+RjqcMc5mxSw6",
+	"eBAHDvlKq5nRt8+BT/Q0Oniw/yvzWPV5HT0ihTEtMh0dfL4X46Ru7b29vb1lsASUB+A4+rsomTZOdo8n",
+	"GX9mBz9Ych63dInlm9VHfe5gX11NcIO7Uufw6Bnu5YA804QpIng2d2wneAKPidJCAu6pgqSQkM2XyxVc",
+	"K65A7H/Jr+iZkEzD0ZTKGU36aTOjJ5C1COTzhyGtWiTm8B61tHDG9Wf7UXe/W4D7j4fA/pJq+vRtLqTu",
+	"4vLpGci5njI+sShLCT1BbkBeKRTIAamOwClVU1CE8tSyOqJWglaESnCsw3iSFakV4q2dTxJRcL1s5y2Y",
+	"h27wRRzRImV6BGelybSSmulmwUefnoGdqEngsWGLdWd8MqXBucaOIka5JQkGq8/cpqbQ/BPgINc2A3Mp",
+	"xiyDZQC8ViCP3FCUJPQM0lGe0WSNl3iJDx3hMyHw3Qm4xnT2gaV2QgMvcUVj3orlPrcIKbxlrZcPshJk",
+	"oMHRZy/jX4eBkeJK+NAysbVQ+X0qpZALdd2ra6pNXu1XGcMaz+gMJBsz8LX+EyEyoHwdf4RITvumkCL7",
+	"cHu6VLtaMJezVxAsQFAthq7Fu5OCpiwzj9M0ZUgjNDvyptWygAA0LB+VCno/Knz1vCSskdPMQ8DgETGi",
+	"E/dySzS/ed7yNzSAakxWv2Y/Yo00vhaUsrBt6oj/KqfE7+yjIWlY5OmaAIbosoFFb0oP6sWY+10tAloW",
+	"gshahID7ErQF4O0K227mc4P7QXqpqS7UJvyfTQH8ZgqcUILmmzEEhDT6o5G2hsSv1V2qqrcqtfEceIo/",
+	"Io5oOjcnEcsaAmTRtrsJl3rtOlrFtSC2Umi7+jjSyC8UQQXcWMRjIc0x5o7XeUxgMBmQYfT+X8nlf17+",
+	"dPn3yx+HUXgRzXSRQhMsUZxkHky8mJ1Yy2dd3Rmf4JN1VihfofIHdDWtaoR9KDxG8PDTJRu1HNlFlhHv",
+	"K2OnGpSWDhNUxEUOHHX3qSikWio5fFyVu9l+v+7blLA7SL0d8nG5NkmGXcqlXnadCnQLC/USITB/gypd",
+	"r2bnPENBs/QF5amYOauIVLqpMUhJDpLQPCeMK02zzGzeKeSaCOMlGnI784AcSVDANW4q04ROKLO+RUVO",
+	"aHJqnErWnauQzSYI5mDIo9g3Mvcffd7wQny2H9+qk6u1AR4SQzvwtRL8DZx8E8LxUXGSscR4cVDCGH1s",
+	"jriiSYI8YQxTRT558dUT8stHD375adcQzSbN0+3Fy/1Hn4cEUSLPWv7KdP/RowdfhMYGUPji5SHJLbjw",
+	"1hIt+eSEKvj8YSGzT0OznLKAyfANzMmzL2MyozpB+xvRfXzK0mMyBZqCNPtiX9v5oJ2kYIpYl0Z3HT1v",
+	"4+AwNI6H32om0iIr1LK3KVRLj1BsEhoXiOI5TJO83u6Fi7U9OXoeWWxaKGKz64tp7SUE2L0MbgV8JgYo",
+	"SlxEIpuTM5qx0jMyo3NyAv5+DMjTWa7n5HzKMij3i8rGGPJbJEX0nawk+GrYl4q83mDaczFhfJm0u10/",
+	"eDNet5qTfKFN7N75I/CQe0Et0E9E2h8qWivIsOJSS2NFi6JAq+3jIvdDA6I3TE8XIuBux3rWjYM2gkEr",
+	"xEB7cPURsICDpXffO6+yeOE1FvwokDdhSoNcW2xcu0yuIVkrPG8fkzbzQRVGBxwX2YAcZUAVZmlAckrm",
+	"opAuRK2F0xntl85j/GERe2Qqnl5J/MZRXshcKPBdBL6r0dprHvZGEll4ubegwrqbfxnga6acYNA/nGlS",
+	"I/tDsYopVC4SoBaxG44L6M2/N9YriusyGkDKsUsjauXAEGBerOManY8rZN6t6RZZz8lRnn/1Jr//8+WP",
+	"l3+9/On9Xy5/XM0n6fwHV3MP1GgNOwauMSLVgn1BuKeeZUGQd9G+VFH8L/wQ/s4Xe9ViK29ZnRDwq8Zc",
+	"D34Vmiysz/weFZgytEsUpkYwTn4oQDJQlYvO+ueGERGSDBt0MIwG5HeF0oQLTRLBNXonMsaBnEigpwqf",
+	"wK+lyLwczEEUt0PgC1MkWvuziKyCm2bZ/Xq409l3QUeyyQQzdjZTDfUP/5eWYEwm64ymQMZSzKI4EKdq",
+	"aaBXFRBL4jtXy9xdJ75jwPJfphXRacR6GvGLBmw1zhdsblhGbCzQXE0cAulVqbA1gbkmPS604msT77nu",
+	"PJTW8vap/uW9vAFv5XY2NmSpMqFtE0MckG9nTGtIydj+cgqQI7swif6TAh4TygkYT4kFixh9RxHBIZBU",
+	"wtU5yFHm3qlWoaI4UlMhdRRHHOk8qwKKwUhLXKZejRKmA17HaxF53TWZyjM67zE9N7NmRvmkKPW5Glmy",
+	"iOLoBKI4alBc/VzBmVath2agJUuQsWc5SEazsFYaIJ+JpCksdrTfmOXRhOZ6M68H5ImX0VFZIGPGmZoS",
+	"IwKRxPHHa7FEPJYMuM4ljEECT5wPmSrFlKZcY1wiY4A8RsC4NstYxFOPERWZAeVkGCFNKtDD6Pb48QnT",
+	"c0SkAiqTKaow5xjWrRSbVIAyvGMdNhw+mBt/K87bSLNHGahq2TXZr7nAc/dL6V6yqFRR7ONwdTZtTv4a",
+	"vzYBk9RAn0Br4lVY+RoyFxo49rDR2u+4RUjle3VJ3iSXJYVkev4Sj3TwaqkOCz3togKjKa6w6GSOhw1N",
+	"UZU2TgQhkagoyeu6pBlNpih3k4wZfj5Et/+QTyTl2ul8JieWMKvpuamNN/8Tk5R6jAlox5+agB9TxOT9",
+	"mmyvodkUEzCvLFWnNCoTLrSJwY/rL8UZ2KC6mYRMQA/5w/0v7GqUHL8ALec7h2MNsgwODchrfsrFOY9L",
+	"49cAYjMkMAI5V0M+AU0e7j2wYUSGWLIPlybdQfTPO4dHz3a+8aNJtMrR/TVQCbJE94n59FVJHF+/eRXF",
+	"rT049GJ13mYYsi/0FLh2fg8CPM0F41oNCCYZHBtkH5Mko2w25OhlbWyC2ZZ6MpeTYDK1HhMUVGZWRVJI",
+	"MirBBl7ds3oK8zIZz7lzmSTHJXkdEwUJPj0Y8h1ybOKux2bqA7fF5ntcsvl1TI6dbLcjDL31DonLAUM+",
+	"5C8AqRzSMlyE+0TJ8Zs3b3YOazwhPqY0Q14BG/j8/JeP9j4l51OhYMiPQUohj5H2jhk3QaqRo6jjuP7K",
+	"LHGMJH/MuCrGY5YgxY8sxuMhN0RmJxt523lsKupMBJZXZGV3Fg0eQiu6ExziIRccjGQ2q5I56BjXxG8d",
+	"IaCUolwY2wpzNvHAwiHmZ2lp1KjvxooyxFYT5VTrPLpAscD4WHS5H7PGTdxYsMQX5hhaB27MKqbN2f4H",
+	"M+KwGnF49CyKozOQ1rSMHgz2BntI/SIHTnMWHUSfma9Q3dBTI4d2B+eQZTuG/3b/eH6qBn9U1i6d2GBj",
+	"RZLP0ugg+g3or89PlfHcWj3EzLK/txeZAAbXzgwz57XlkN1yRmvRrB4yxHCnQVTLOnn57e/JGzjB0ghi",
+	"x8SRKmYzKueN8LtaFH9HPNKJQpF/2GDo6Hucb5fmbNdR/K7NvDIalwiZEEcgZ5TbEKsdW5+4VU4Mk5WT",
+	"Lya+59QoZSZVZsiR18iUKS3kPMavHGkqp/U05JFNspgNyAsrEpQVFkkrf9fSY3MfGznCkT0BQelfi3R+",
+	"bRsZzEO+aJ63WhZw0SGmhwGusNNUSXAXcfTwGonO5iAHiO2ZlT2Vq6RMGXO4RZHFeCKkdOrtw70Hmwfq",
+	"NcczSEj2J6z2K0FEVYApZSi9eURZK91A99nmoftKyBOWpsDtgWhJtlJ3zflXeZ+MKDbyG8F7dDM7qkFy",
+	"mpl6bpAE3MBaTYsOvnvX0Bi+q9L2v7/43pc1lsR948gXK9UzbXlSGY5hcfISeKowb6NjuQWqZA2Om/WJ",
+	"ttQIzzFXrW6eZaqcEEXHVIpiMiVdsHbdoJDU8AqANyQzAoXTK0mMvc1AYNcIUVHIrkbluqeYeSuvPkZ5",
+	"9XDvi82D96pJSbiDNLO54YyjimEg2b8JSIQgM8rnTmFxJAYpmn9azglFq85SvhfXTARKM8aJZ/pFsTPf",
+	"DPv6PzSg7ERAL+7jIfFSU6nJTJy1/GjWkA+Wpq92gpSiuv8keQEpwEzVh0CPeDKapjpndfamByPTwbOg",
+	"U5m/qSOht9nATZ8M/b0IAhQUbBxwY8fAE3fiO9M5rsxeIbfy/37L/8p7r6mxBa1PGR3fxr95HyWkH4G4",
+	"ojysSsd7VOoZFjTYUTanWYIuJIeUSDaZakLP6ZxQ1LqNe+Gk4GkGA/KcyonxzpuHhjyXkFOkAYd2dMpM",
+	"pCh4ah/+l2dHBP397AwOHNFYEYHMuL+3b7wBQ46/WGB+oYitl4ox7RoDBYrkIstqHX3IA2+6+87+fZZe",
+	"uMYRzNSIWb3AeNHFOc8EDVr/japYrLzfpD/Hq+zvoW63eRdxtL+3f32U69fsLVwZEXcCKIfK7W3qJ89d",
+	"SVKArMzslQu4DIy4F4q7qkwVdLjYyt57IHsPuUcipdbdIpX7KHAtbxBY0Fij9CKWhbUrimBPMC1yIrtp",
+	"npaMklNJZ6ANw333zgZ40Eddh3fKaaO2yudz2ZIEpIvvNyjnlskbh3PlBmzZ/wPZ/+HmwXNbhrCMzRkv",
+	"ys5L6dVtQTwwxJhQX3eoDowrsNluedL38tuXbsC9YLo/sby5c9X0J4wjErsLdPbN08Js+EVwsHodpp2Y",
+	"wLWLlLZO7C1X3neuvCHF4GmlFSAYRi+4agjBMWfV8OFqYsHPOOvzBdVBQtKJERJbMGpj2mWOsGMOE8Zk",
+	"ashdmPyxnYLamLpNxMJzbV5V9zLeHz44qvtibi6C0G78eStBhE4/zwAhtZt33nagoE0Y24DBzzrAaQm5",
+	"EUJaTRzVGZ59JkCZBLpBNmy0jQt7ABygA4JjFSYkuTaBps2aURwwpclW05r8PzXYssHPjQ1+A12j+Beq",
+	"JJ4gR5gcqySQ3fnEi9GbSV2ZQW6bqpSeRSeXXXy/yvHKWXKqSJEPeekszb2c6ar/Doe3psO8Ji4XO3Qe",
+	"2xoJnw+v/zjurcO44RN5BVHgUoerXb2lk3grXX5u0sXyyLoCpjpyMRcX/9spO8G4E7eVvG8SkW0WoWtM",
+	"HLteuLaEqZt5LXjVgBrzDBVkZ+B30i1DKIOOZMHFbIb7RlNFvXsfQs5b+5YqRjEJSpMxk1v2+pjZCzmh",
+	"zVxIHSXBN2KJbnDcYy4/U6pAaq/6eC2sufgWD3Nq2k97t7kMOVPOsx4TJToBQfxs1ABT3o0hvIRybMok",
+	"IRy48zuYb8p+DnS0X+msfrAhEBbk4NlddenQ2/N6K1BuQqAYwUAoL4VKQKaEj+bdd6cwd3GxuqKgq4ci",
+	"SSstckXOhTzFjWWzGaSMandFQFMm2CYknkxYmkrv2MYd+Vv6vQ9O73LTGl7vMiLt7eQ9YyZLvIu5aZVA",
+	"leGsD4tSNZkWFXC1+w7/YIwNO6g3+bZlSnMc8drGylevZim4a82+5cF7wIO4vU0GxA/1Dt4z3rM0S6iN",
+	"7wTqV9bgP8snHxom7gtc+RVwgdiUb8c2e+xqU05Hz2DIcatKw9aUdNriunGhkLzgnGRiwrh/DZPDCCrq",
+	"JZ+GdPLnV2H8LdvfZ7Yvz917y/rPV2L86kQs9LSsNdjp1K71lwXcQEHAHSgFWF4EUJfd1e0fs/nt2quf",
+	"3UziuidDfZF3U1xb3fAgxRlLISVl3UwKNUffaCZ/mcWPtYI2gd+0+HC9BBy2TmAsJBBqW+eTwrb/Mc9U",
+	"tHQXpE4ngd8IlN7s/d4ye5Qvk6rHUlAJsD4hZbqhcMHnM1GoGl9GMLuyJpePYvzbQjbvJRhyN8gitsro",
+	"LZ9nekBMl6VSgTDeufoEKrtomAPIaBCmPXphWr3Yei6lJUs0SBt9+6EQmoa0BrOK6fK9ISnZaF11w+Kx",
+	"2b08QFMWx1Wy0M9YFN5o3ebE9ug5BwnENTe0HVBMg8iaY/trM9uF6ra40+rMpbhyPuxyAXM9SBTf9bLO",
+	"OkWXTUxVFVXlG60nw3aduF5U369VI6WmNGacVOIpUUtaAAy5bV/cqP4v7xilynGWHVtRXrsfwJD3NQTo",
+	"KHyPGxMhDsjEvcWQex2F3J0xkCvCtCLNdibVpTb2FU2CjW1sOxjyJy6dsL5QxqGgbOMck1woxU4y01at",
+	"OjJdG4RgekPdNW9j2Q3dNoErydr9DYHQL3KfdChJuVtHbzPd0BOKXOiK4bZ28T0ojnrtdTdiqlT7svLa",
+	"Oqa0uvXWBB9wmFXt46uYbD3nvW1RYC/abjoDXhWSN5JP7DHEuNFn0VYN+QgWHoTGl9bvJNik3tu4Leiu",
+	"6b1mgOcF+Ohj1uW6iYQUyYVm6vaV7jujalpy0MJ1TWy03V6Vz0ShF9V8WNd5u/G77yUnJ4C96vGT70jv",
+	"DGNaQTZ+7EpErJVtitfnXtVHwDtuIPxApluh0fFLz7WGvvwJpMSuvLTdc5BPRaE7jLrVSD7qpMx4ldPy",
+	"uZggXfXWRy/k19Lg23VyeEeCAr1TXvwVPi3dYda53WxDB2jvhW03fJj23+a2qIzKILSyMnx/O8mlwA+3",
+	"nSn28JY086a/e9sv7MZPe8dHJpG0Qa3uhry1RQiKDtzlpfJDQSU9yov1bkJ4tC88vE0B0rlQcLkQ2Qbr",
+	"7pDAukNcjLRRcXBhlbJuQ+eVGdpdgbiIf82AV2Xb6k3wbeuKyhvm1PZ9lcGzBN+eOGTdqVD6jVsFr6ZQ",
+	"IqJqCF52+rtjvGKBdHaky7iq4VZrMIm9e3MRl7gRm2KQ5jWkN1wS0bl7NLAjvReNPhu7qI8XAQCXmVJk",
+	"qRG0JzYeELtmgsbAtpfvKdEM/ljPrg1+9ztJAwElU9+aGmVlcJtxB5v11PVT3yGf+R3iYEt3bqvXMna9",
+	"/e73ULn9cbkdcwJUZgykJaoyfUfRGRB3SyzeV4XWhM3ldDYEdaHMSWGu9mCzOjmDVQukbrAuzZJzKfDs",
+	"1hpmuQ6GEOvbZzcmVtr38t740du5XzfY7bxi9LLJ+daIvqFOQ40+wl4bQqtxwtacv608EahOQEsbDRNA",
+	"yA+y8v1bQoMNVLCstbx4epMl1v5dpiHBkGhsslZfbDMTShMJib0Mp1CQbsuutw70TkF2oNcBbZLS2qyy",
+	"a6vgdkx8Si0yFHDYtzjqJhio5474APK/9XuvqW155pZxgrWSwTIon5cIvE0gb/KYvV90TYZ65/7r1CyH",
+	"GMpR+EptRat5r7mv6MNQVo/F05ab7lHFVblp97jY+UqsLTiEGBrvCFj3VCyN3p3qivegGvkHN8zeD7/5",
+	"LI1XpbvSwDe4WnZGe5Jb5enqapV4OXcvpJkWSZQ7U1FCcMoVyMHQW3+9Hv66yLcxKzLNcir1LsrjnZRq",
+	"umjX8TpUsVLb5jjKqGa6SANtMF5bqq8GhB4WfLLk6WpE4HHHUCOWBq+i/qGoE6aYyVwbM6+eLBH8DKSy",
+	"qF6Rhq/Xp9PCetkfcCS96+Hb7mH7S1nxAe0bZm8aTXGkJeXejJ1mKP7P1aXjSGCE8fyquV1NPLRx8HOK",
+	"JN0HdWP/huoyp6KQ2dwr2jNl/wpSUuRxWa1QN5yy95wzrYY8B7ljr0Enkmp38bkrI1Sm+QChJBPnIO3E",
+	"j8tp3JVCiAKvglDXwHjFg1dLYse57MuMJYAiRb4sd32l7PG4eX19WKXBg8W6T1ssRj5RxnOGfBzb5rrw",
+	"Vn8aVQdWVSa0m0+pnNHECblgW8NXU1BA3EXppH7AfKzXnAHFKtA5GUaXf7/86f2/k8sfL//v/V8u/3b5",
+	"P5c/DiPX0nxKNXY5V8J0OtRTmFVxLPx9Nh/5KwiRBRtCMKW/cu9wVL/BBn0MrdV6OyKW4zw8xURk6bY5",
+	"4sfvbFjoinNWxrhLH56GWVKPanRNDDUobNPjRlsVthe7pQh9551DCWbuNwzn3H6r/7oc0xxZ7vq4ZLr1",
+	"ktwPL0lFTbfTySIvl/dikrQiqvsnDA9TDOdVb+VUsYpJegThIqVl913mbg5c4kG118gHpOZyV2q9wmq+",
+	"VMb1Z/tR3NX9VvKmViQnYSbOtoLifgiKNrfaSuv7y6kvDPH5zFq5NJaxq3crQ+guhDvAgpu6guGD1KS9",
+	"G1WT/MsYfCrddnfeSrifi4RD2eK9QvV2S9QQ206l11vy0uu5QjLQLS8J5cq6qUAyk+GFDvizsufMLMas",
+	"4umQU4VuFM9/Qi7/4/Kny/+6/N/LvxF0r1z+7fK/yfs/X/54+dfLn97/5f2/DaM+R4mB6MhCvck0pmqZ",
+	"Pt+Ij5qtU2TrFKmcIn6fIo/7HM0uc4XUhLchJ0i9wC05Prw3DBmp+IPF4W37O0qxt3V23Meb2O32eQnZ",
+	"9oS8c6UT6wmblzY8lDsB0REuzXN99535u5I3oSF4lhsxbuLNJ2RZgWBh3/Le/XA0mi27U+W463GZ5QhC",
+	"/bO85ygvdJ9v4LYY6s6oDHs3pDI07mK0g7a2/1Y6rSGdthrJB7sekPjE2Qoy0y6Ba1pR2NocKdIiwQ8O",
+	"sCiOCplFB9FU61wd7O5W3ofBNBNjNleDt/M/RRffX/z/AIz5aUWJ1gAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
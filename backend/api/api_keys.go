@@ -0,0 +1,196 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	db "voice_assistant/db/sqlc"
+	"voice_assistant/tools"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// API key limits enforced on issue, matching CreateApiKeyRequest.
+const (
+	maxAPIKeyOwnerLength        = 128
+	defaultAPIKeyRateLimit      = 60
+	maxAPIKeyRateLimitPerMinute = 10000
+)
+
+func apiKeyResponse(k db.ListAPIKeysRow) ApiKey {
+	response := ApiKey{
+		Id:                 k.KeyID.Bytes,
+		Prefix:             k.KeyPrefix,
+		Owner:              k.Owner,
+		Scopes:             k.Scopes,
+		RateLimitPerMinute: int(k.RateLimitPerMinute),
+		CreatedAt:          k.CreatedAt.Time,
+	}
+	if k.ExpiresAt.Valid {
+		response.ExpiresAt = &k.ExpiresAt.Time
+	}
+	if k.LastUsedAt.Valid {
+		response.LastUsedAt = &k.LastUsedAt.Time
+	}
+	if k.RevokedAt.Valid {
+		response.RevokedAt = &k.RevokedAt.Time
+	}
+	return response
+}
+
+func (s *Server) ListApiKeys(w http.ResponseWriter, r *http.Request) {
+	rows, err := s.db.ListAPIKeys(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error listing API keys", "handler", "ListApiKeys", "err", err)
+		http.Error(w, `{"message": "failed to list API keys"}`, http.StatusInternalServerError)
+		return
+	}
+
+	keys := make([]ApiKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, apiKeyResponse(row))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(ApiKeyList{ApiKeys: keys}); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ListApiKeys", "err", err)
+	}
+}
+
+func (s *Server) CreateApiKey(w http.ResponseWriter, r *http.Request) {
+	adminID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "CreateApiKey", "err", err)
+		return
+	}
+
+	var createRequest CreateApiKeyRequest
+	if err := json.Unmarshal(bodyBytes, &createRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "CreateApiKey", "err", err)
+		return
+	}
+
+	owner := strings.TrimSpace(createRequest.Owner)
+	if owner == "" || len([]rune(owner)) > maxAPIKeyOwnerLength {
+		http.Error(w, `{"message": "owner must be 1 to 128 characters"}`, http.StatusBadRequest)
+		return
+	}
+
+	var scopes []string
+	for _, scope := range createRequest.Scopes {
+		if !slices.Contains(tools.APIKeyScopes, string(scope)) {
+			http.Error(w, `{"message": "API keys can only be granted the chat scope"}`, http.StatusBadRequest)
+			return
+		}
+		if !slices.Contains(scopes, string(scope)) {
+			scopes = append(scopes, string(scope))
+		}
+	}
+	if len(scopes) == 0 {
+		http.Error(w, `{"message": "at least one scope is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	rateLimit := defaultAPIKeyRateLimit
+	if createRequest.RateLimitPerMinute != nil {
+		rateLimit = *createRequest.RateLimitPerMinute
+	}
+	if rateLimit < 1 || rateLimit > maxAPIKeyRateLimitPerMinute {
+		http.Error(w, `{"message": "rate_limit_per_minute must be between 1 and 10000"}`, http.StatusBadRequest)
+		return
+	}
+
+	var ttlSeconds pgtype.Float8
+	if createRequest.ExpiresInDays != nil {
+		if *createRequest.ExpiresInDays < 1 {
+			http.Error(w, `{"message": "expires_in_days must be at least 1"}`, http.StatusBadRequest)
+			return
+		}
+		ttl := time.Duration(*createRequest.ExpiresInDays) * 24 * time.Hour
+		ttlSeconds = pgtype.Float8{Float64: ttl.Seconds(), Valid: true}
+	}
+
+	keyID, err := uuid.NewRandom()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error generating key ID", "handler", "CreateApiKey", "err", err)
+		http.Error(w, `{"message": "failed to issue API key"}`, http.StatusInternalServerError)
+		return
+	}
+	apiKey, err := tools.GenerateAPIKey()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error generating API key", "handler", "CreateApiKey", "err", err)
+		http.Error(w, `{"message": "failed to issue API key"}`, http.StatusInternalServerError)
+		return
+	}
+
+	created, err := s.db.CreateAPIKey(r.Context(), db.CreateAPIKeyParams{
+		KeyID:              pgtype.UUID{Bytes: keyID, Valid: true},
+		KeyHash:            s.jwtAuth.HashAPIKey(apiKey),
+		KeyPrefix:          apiKey[:tools.APIKeyDisplayLength],
+		Owner:              owner,
+		Scopes:             scopes,
+		RateLimitPerMinute: int32(rateLimit),
+		CreatedBy:          adminID,
+		TtlSeconds:         ttlSeconds,
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error storing API key", "handler", "CreateApiKey", "owner", owner, "err", err)
+		http.Error(w, `{"message": "failed to issue API key"}`, http.StatusInternalServerError)
+		return
+	}
+	s.recordAuditEvent(r, auditAPIKeyIssued, adminID, pgtype.UUID{}, map[string]any{
+		"key_id": keyID.String(),
+		"owner":  owner,
+		"scopes": scopes,
+	})
+
+	response := CreateApiKeyResponse{
+		Key:    apiKey,
+		ApiKey: apiKeyResponse(db.ListAPIKeysRow(created)),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "CreateApiKey", "err", err)
+	}
+}
+
+func (s *Server) RevokeApiKey(w http.ResponseWriter, r *http.Request, keyId uuid.UUID) {
+	adminID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	revoked, err := s.db.RevokeAPIKey(r.Context(), pgtype.UUID{Bytes: keyId, Valid: true})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error revoking API key", "handler", "RevokeApiKey", "key_id", keyId, "err", err)
+		http.Error(w, `{"message": "failed to revoke API key"}`, http.StatusInternalServerError)
+		return
+	}
+	if revoked == 0 {
+		http.Error(w, `{"message": "API key not found or already revoked"}`, http.StatusNotFound)
+		return
+	}
+	s.recordAuditEvent(r, auditAPIKeyRevoked, adminID, pgtype.UUID{}, map[string]any{
+		"key_id": keyId.String(),
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
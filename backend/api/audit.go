@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	db "voice_assistant/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Audit event types, as stored in audit_events.event_type.
+const (
+	auditRefreshTokenReuse = "refresh_token_reuse"
+	auditUserLocked        = "user_locked"
+	auditUserUnlocked      = "user_unlocked"
+	auditPasswordChanged   = "password_changed"
+	auditEmailChanged      = "email_changed"
+	auditAccountDeleted    = "account_deleted"
+	auditAPIKeyIssued      = "api_key_issued"
+	auditAPIKeyRevoked     = "api_key_revoked"
+)
+
+// recordAuditEvent stores a security-relevant event. Failures are logged and
+// otherwise ignored so that auditing never blocks the request it describes.
+func (s *Server) recordAuditEvent(r *http.Request, eventType string, userID, sessionID pgtype.UUID, details map[string]any) {
+	if details == nil {
+		details = map[string]any{}
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding audit event details", "event_type", eventType, "err", err)
+		detailsJSON = []byte("{}")
+	}
+
+	err = s.db.CreateAuditEvent(r.Context(), db.CreateAuditEventParams{
+		EventType: eventType,
+		UserID:    userID,
+		SessionID: sessionID,
+		IpAddress: clientIP(r),
+		UserAgent: truncate(r.UserAgent(), maxDeviceNameLength),
+		Details:   detailsJSON,
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error recording audit event", "event_type", eventType, "user_id", userID, "err", err)
+	}
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	db "voice_assistant/db/sqlc"
+	"voice_assistant/logging"
+	"voice_assistant/mail"
+	"voice_assistant/util"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Purposes of verification codes, as stored in verification_codes.purpose.
+const (
+	purposeEmailVerification = string(EmailVerification)
+	purposePasswordReset     = string(PasswordReset)
+	// purposeEmailChange codes are sent to the new address and remember it.
+	purposeEmailChange = "email_change"
+	// purposeGuestUpgrade codes are sent to the address a guest wants to use
+	// and remember it; it is only written to the account once confirmed.
+	purposeGuestUpgrade = "guest_upgrade"
+)
+
+var codeTemplates = map[string]string{
+	purposeEmailVerification: mail.TemplateConfirmEmail,
+	purposePasswordReset:     mail.TemplatePasswordReset,
+	purposeEmailChange:       mail.TemplateChangeEmail,
+	purposeGuestUpgrade:      mail.TemplateConfirmEmail,
+}
+
+// remembersAddress reports whether codes for purpose carry the address they
+// were sent to, because it is not yet the address of the account.
+func remembersAddress(purpose string) bool {
+	return purpose == purposeEmailChange || purpose == purposeGuestUpgrade
+}
+
+// codePolicy limits how long codes live, how often they can be guessed and how
+// often new ones can be requested.
+type codePolicy struct {
+	ttl         time.Duration
+	maxAttempts int
+	resendAfter time.Duration
+	hourlyLimit int
+}
+
+func newCodePolicy(config util.Config) codePolicy {
+	return codePolicy{
+		ttl:         config.VerificationCodeTTL,
+		maxAttempts: config.VerificationCodeMaxAttempts,
+		resendAfter: config.VerificationCodeResendAfter,
+		hourlyLimit: config.VerificationCodeHourlyLimit,
+	}
+}
+
+// errCodeRateLimited is returned by issueCode when the user asked for codes too often.
+type errCodeRateLimited struct {
+	retryAfter time.Duration
+}
+
+func (e *errCodeRateLimited) Error() string {
+	return fmt.Sprintf("too many codes requested, retry in %s", e.retryAfter)
+}
+
+var (
+	// errCodeInvalid means there is no usable code: never sent, expired, used or locked.
+	errCodeInvalid = errors.New("code is invalid or expired")
+	// errCodeMismatch means the code was wrong; the attempt has been counted.
+	errCodeMismatch = errors.New("code is incorrect")
+)
+
+// issueCode creates a new code for purpose, invalidating earlier ones, and mails
+// it to email. For purposeEmailChange, email is the new address.
+func (s *Server) issueCode(r *http.Request, userID pgtype.UUID, email, purpose string) error {
+	code, err := s.createCode(r.Context(), s.db, userID, email, purpose)
+	if err != nil {
+		return err
+	}
+	return s.sendCode(r, codeTemplates[purpose], email, code)
+}
+
+// createCode stores a new code for purpose through q, so it can be part of a
+// transaction, and returns it for sending.
+func (s *Server) createCode(ctx context.Context, q *db.Queries, userID pgtype.UUID, email, purpose string) (string, error) {
+	stats, err := q.GetVerificationCodeSendStats(ctx, db.GetVerificationCodeSendStatsParams{UserID: userID, Purpose: purpose})
+	if err != nil {
+		return "", fmt.Errorf("get code send stats: %w", err)
+	}
+	if stats.SentLastHour > 0 {
+		if wait := s.codePolicy.resendAfter - seconds(stats.SecondsSinceLast); wait > 0 {
+			return "", &errCodeRateLimited{retryAfter: wait}
+		}
+		if stats.SentLastHour >= int64(s.codePolicy.hourlyLimit) {
+			// The window frees up when the oldest code in it turns an hour old.
+			return "", &errCodeRateLimited{retryAfter: time.Hour - seconds(stats.SecondsSinceFirst)}
+		}
+	}
+
+	code, err := generateNumericCode(6)
+	if err != nil {
+		return "", err
+	}
+	err = q.CreateVerificationCode(ctx, db.CreateVerificationCodeParams{
+		UserID:      userID,
+		Purpose:     purpose,
+		CodeHash:    s.jwtAuth.HashVerificationCode(purpose, code),
+		MaxAttempts: int32(s.codePolicy.maxAttempts),
+		TtlSeconds:  s.codePolicy.ttl.Seconds(),
+		NewEmail:    pgtype.Text{String: email, Valid: remembersAddress(purpose)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("save code: %w", err)
+	}
+	return code, nil
+}
+
+// checkCode validates and consumes the latest code for purpose and returns it.
+// Every check counts as an attempt, claimed before the code is compared so
+// that parallel guesses cannot get past the limit; once it is reached a new
+// code has to be requested. remaining is only set for errCodeMismatch.
+func (s *Server) checkCode(ctx context.Context, userID pgtype.UUID, purpose, code string) (active db.ClaimVerificationCodeAttemptRow, remaining int32, err error) {
+	active, err = s.db.ClaimVerificationCodeAttempt(ctx, db.ClaimVerificationCodeAttemptParams{UserID: userID, Purpose: purpose})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			return active, 0, errCodeInvalid
+		}
+		return active, 0, fmt.Errorf("claim code attempt: %w", err)
+	}
+
+	if !s.jwtAuth.VerificationCodeMatches(purpose, code, active.CodeHash) {
+		return active, active.RemainingAttempts, errCodeMismatch
+	}
+
+	// Guard against the same code being redeemed twice concurrently.
+	consumed, err := s.db.ConsumeVerificationCode(ctx, active.ID)
+	if err != nil {
+		return active, 0, fmt.Errorf("consume code: %w", err)
+	}
+	if consumed == 0 {
+		return active, 0, errCodeInvalid
+	}
+	return active, 0, nil
+}
+
+func seconds(v float64) time.Duration {
+	return time.Duration(v * float64(time.Second))
+}
+
+// writeCodeError answers a failed checkCode with 400, or 500 for internal errors.
+func writeCodeError(w http.ResponseWriter, r *http.Request, handler string, remaining int32, err error) {
+	switch {
+	case errors.Is(err, errCodeInvalid):
+		http.Error(w, `{"message": "code is invalid or expired, request a new one"}`, http.StatusBadRequest)
+	case errors.Is(err, errCodeMismatch) && remaining > 0:
+		http.Error(w, fmt.Sprintf(`{"message": "code is incorrect, %d attempts left"}`, remaining), http.StatusBadRequest)
+	case errors.Is(err, errCodeMismatch):
+		http.Error(w, `{"message": "code is incorrect and has been invalidated, request a new one"}`, http.StatusBadRequest)
+	default:
+		slog.ErrorContext(r.Context(), "Error checking code", "handler", handler, "err", err)
+		http.Error(w, `{"message": "failed to check code"}`, http.StatusInternalServerError)
+	}
+}
+
+// writeIssueCodeError answers a failed issueCode with 429 and Retry-After, or 500.
+func writeIssueCodeError(w http.ResponseWriter, r *http.Request, handler, email string, err error) {
+	var limited *errCodeRateLimited
+	if errors.As(err, &limited) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limited.retryAfter.Seconds()))))
+		http.Error(w, `{"message": "too many codes requested, try again later"}`, http.StatusTooManyRequests)
+		return
+	}
+	slog.ErrorContext(r.Context(), "Error issuing code", "handler", handler, "email", logging.Email(email), "err", err)
+	http.Error(w, `{"message": "failed to send code"}`, http.StatusInternalServerError)
+}
+
+func (s *Server) ResendCode(w http.ResponseWriter, r *http.Request) {
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "ResendCode", "err", err)
+		return
+	}
+
+	var resendCodeRequest ResendCodeRequest
+	if err := json.Unmarshal(bodyBytes, &resendCodeRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "ResendCode", "err", err)
+		return
+	}
+
+	purpose := string(resendCodeRequest.Purpose)
+	if resendCodeRequest.Email == "" || codeTemplates[purpose] == "" {
+		http.Error(w, `{"message": "email and a valid purpose are required"}`, http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.db.GetUserByEmail(r.Context(), resendCodeRequest.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			slog.WarnContext(r.Context(), "User not found", "handler", "ResendCode", "email", logging.Email(resendCodeRequest.Email))
+			http.Error(w, `{"message": "user not found"}`, http.StatusNotFound)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Database error fetching user", "handler", "ResendCode", "email", logging.Email(resendCodeRequest.Email), "err", err)
+		http.Error(w, `{"message": "internal server error while fetching user data"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if purpose == purposeEmailVerification && user.EmailVerified {
+		http.Error(w, `{"message": "email is already verified"}`, http.StatusConflict)
+		return
+	}
+
+	if err := s.issueCode(r, user.UserID, resendCodeRequest.Email, purpose); err != nil {
+		writeIssueCodeError(w, r, "ResendCode", resendCodeRequest.Email, err)
+		return
+	}
+
+	response := ResendCodeResponse{
+		Message: "A new code has been sent to your email.",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ResendCode", "err", err)
+	}
+}
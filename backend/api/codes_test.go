@@ -0,0 +1,264 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	db "voice_assistant/db/sqlc"
+	"voice_assistant/tools"
+	"voice_assistant/util"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeCode is a row of verification_codes.
+type fakeCode struct {
+	id          int64
+	userID      pgtype.UUID
+	purpose     string
+	hash        string
+	newEmail    pgtype.Text
+	attempts    int32
+	maxAttempts int32
+	used        bool
+	expired     bool
+}
+
+func (c *fakeCode) active() bool {
+	return !c.used && !c.expired && c.attempts < c.maxAttempts
+}
+
+// fakeCodeStore implements the verification code queries checkCode runs,
+// with the same predicates as db/query/verification_codes.sql.
+type fakeCodeStore struct {
+	mu    sync.Mutex
+	codes []*fakeCode // oldest first
+}
+
+func (f *fakeCodeStore) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasPrefix(sql, "-- name: ClaimVerificationCodeAttempt ") {
+		return fakeRow{err: errors.New("unexpected query: " + sql)}
+	}
+	userID, purpose := args[0].(pgtype.UUID), args[1].(string)
+	for i := len(f.codes) - 1; i >= 0; i-- {
+		c := f.codes[i]
+		if c.userID == userID && c.purpose == purpose && c.active() {
+			c.attempts++
+			return fakeRow{values: []any{c.id, c.hash, c.newEmail, c.maxAttempts - c.attempts}}
+		}
+	}
+	return fakeRow{err: pgx.ErrNoRows}
+}
+
+func (f *fakeCodeStore) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasPrefix(sql, "-- name: ConsumeVerificationCode ") {
+		return pgconn.CommandTag{}, errors.New("unexpected query: " + sql)
+	}
+	for _, c := range f.codes {
+		if c.id == args[0].(int64) && !c.used && !c.expired && c.attempts <= c.maxAttempts {
+			c.used = true
+			return pgconn.NewCommandTag("UPDATE 1"), nil
+		}
+	}
+	return pgconn.NewCommandTag("UPDATE 0"), nil
+}
+
+func (f *fakeCodeStore) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query: " + sql)
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r.values[i]))
+	}
+	return nil
+}
+
+func newCodeTestServer(t *testing.T, store *fakeCodeStore) *Server {
+	t.Helper()
+	auth, err := tools.NewJwsAuthenticator(util.Config{JwtSecret: strings.Repeat("s", util.MinHS256SecretLength)})
+	if err != nil {
+		t.Fatalf("NewJwsAuthenticator: %v", err)
+	}
+	return &Server{jwtAuth: *auth, db: db.New(store)}
+}
+
+func TestNewCodePolicy(t *testing.T) {
+	config := util.Config{
+		VerificationCodeTTL:         10 * time.Minute,
+		VerificationCodeMaxAttempts: 3,
+		VerificationCodeResendAfter: 30 * time.Second,
+		VerificationCodeHourlyLimit: 4,
+	}
+	want := codePolicy{ttl: 10 * time.Minute, maxAttempts: 3, resendAfter: 30 * time.Second, hourlyLimit: 4}
+	if got := newCodePolicy(config); got != want {
+		t.Errorf("newCodePolicy = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckCode(t *testing.T) {
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	otherUser := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	tests := []struct {
+		name          string
+		code          *fakeCode // stored with the hash of "123456"; nil for none
+		guess         string
+		wantErr       error
+		wantRemaining int32
+		wantUsed      bool
+	}{
+		{name: "correct code", code: &fakeCode{maxAttempts: 5}, guess: "123456", wantUsed: true},
+		{name: "correct code on the last attempt", code: &fakeCode{attempts: 4, maxAttempts: 5}, guess: "123456", wantUsed: true},
+		{name: "wrong code", code: &fakeCode{maxAttempts: 5}, guess: "654321", wantErr: errCodeMismatch, wantRemaining: 4},
+		{name: "wrong code on the last attempt", code: &fakeCode{attempts: 4, maxAttempts: 5}, guess: "654321", wantErr: errCodeMismatch, wantRemaining: 0},
+		{name: "no code", guess: "123456", wantErr: errCodeInvalid},
+		{name: "attempts used up", code: &fakeCode{attempts: 5, maxAttempts: 5}, guess: "123456", wantErr: errCodeInvalid},
+		{name: "expired", code: &fakeCode{maxAttempts: 5, expired: true}, guess: "123456", wantErr: errCodeInvalid},
+		{name: "already used", code: &fakeCode{maxAttempts: 5, used: true}, guess: "123456", wantErr: errCodeInvalid, wantUsed: true},
+		{name: "code of another purpose", code: &fakeCode{maxAttempts: 5, purpose: purposePasswordReset}, guess: "123456", wantErr: errCodeInvalid},
+		{name: "code of another user", code: &fakeCode{maxAttempts: 5, userID: otherUser}, guess: "123456", wantErr: errCodeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeCodeStore{}
+			s := newCodeTestServer(t, store)
+			if tt.code != nil {
+				tt.code.id = 1
+				if !tt.code.userID.Valid {
+					tt.code.userID = userID
+				}
+				if tt.code.purpose == "" {
+					tt.code.purpose = purposeEmailVerification
+				}
+				tt.code.hash = s.jwtAuth.HashVerificationCode(tt.code.purpose, "123456")
+				store.codes = append(store.codes, tt.code)
+			}
+
+			_, remaining, err := s.checkCode(context.Background(), userID, purposeEmailVerification, tt.guess)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("checkCode error = %v, want %v", err, tt.wantErr)
+			}
+			if remaining != tt.wantRemaining {
+				t.Errorf("remaining = %d, want %d", remaining, tt.wantRemaining)
+			}
+			if tt.code != nil && tt.code.used != tt.wantUsed {
+				t.Errorf("code used = %v, want %v", tt.code.used, tt.wantUsed)
+			}
+		})
+	}
+}
+
+func TestCheckCodeAttemptExhaustion(t *testing.T) {
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	store := &fakeCodeStore{}
+	s := newCodeTestServer(t, store)
+	code := &fakeCode{
+		id:          1,
+		userID:      userID,
+		purpose:     purposePasswordReset,
+		hash:        s.jwtAuth.HashVerificationCode(purposePasswordReset, "123456"),
+		maxAttempts: 3,
+	}
+	store.codes = append(store.codes, code)
+
+	steps := []struct {
+		guess         string
+		wantErr       error
+		wantRemaining int32
+	}{
+		{guess: "000000", wantErr: errCodeMismatch, wantRemaining: 2},
+		{guess: "111111", wantErr: errCodeMismatch, wantRemaining: 1},
+		{guess: "222222", wantErr: errCodeMismatch, wantRemaining: 0},
+		// The right code no longer works once every attempt is used.
+		{guess: "123456", wantErr: errCodeInvalid},
+	}
+	for i, step := range steps {
+		_, remaining, err := s.checkCode(context.Background(), userID, purposePasswordReset, step.guess)
+		if !errors.Is(err, step.wantErr) || remaining != step.wantRemaining {
+			t.Fatalf("attempt %d: checkCode = %d, %v; want %d, %v", i+1, remaining, err, step.wantRemaining, step.wantErr)
+		}
+	}
+	if code.used || code.attempts != 3 {
+		t.Errorf("code used = %v after %d attempts, want unused after 3", code.used, code.attempts)
+	}
+}
+
+func TestCheckCodeConcurrentGuesses(t *testing.T) {
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	store := &fakeCodeStore{}
+	s := newCodeTestServer(t, store)
+	store.codes = append(store.codes, &fakeCode{
+		id:          1,
+		userID:      userID,
+		purpose:     purposeEmailVerification,
+		hash:        s.jwtAuth.HashVerificationCode(purposeEmailVerification, "123456"),
+		maxAttempts: 5,
+	})
+
+	// Every guess claims an attempt first, so no more than maxAttempts of
+	// them are ever compared, however many arrive at once.
+	var compared sync.WaitGroup
+	var mu sync.Mutex
+	mismatches := 0
+	for i := 0; i < 20; i++ {
+		compared.Add(1)
+		go func() {
+			defer compared.Done()
+			if _, _, err := s.checkCode(context.Background(), userID, purposeEmailVerification, "000000"); errors.Is(err, errCodeMismatch) {
+				mu.Lock()
+				mismatches++
+				mu.Unlock()
+			}
+		}()
+	}
+	compared.Wait()
+	if mismatches != 5 {
+		t.Errorf("%d guesses were compared, want 5", mismatches)
+	}
+}
+
+func TestWriteCodeError(t *testing.T) {
+	tests := []struct {
+		name        string
+		remaining   int32
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "invalid", err: errCodeInvalid, wantStatus: http.StatusBadRequest, wantMessage: "request a new one"},
+		{name: "mismatch with attempts left", remaining: 2, err: errCodeMismatch, wantStatus: http.StatusBadRequest, wantMessage: "2 attempts left"},
+		{name: "mismatch on the last attempt", err: errCodeMismatch, wantStatus: http.StatusBadRequest, wantMessage: "has been invalidated"},
+		{name: "internal error", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError, wantMessage: "failed to check code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeCodeError(w, httptest.NewRequest(http.MethodPost, "/", nil), "Test", tt.remaining, tt.err)
+			if w.Code != tt.wantStatus || !strings.Contains(w.Body.String(), tt.wantMessage) {
+				t.Errorf("writeCodeError = %d %s, want %d containing %q", w.Code, w.Body.String(), tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// syncExportMaxItems is the largest export, counted in chat messages and
+	// audit events, that is returned directly instead of in the background.
+	syncExportMaxItems = 500
+	// exportTimeout bounds how long a background export may take.
+	exportTimeout = 5 * time.Minute
+	// exportRetention is how long a finished export can be downloaded.
+	exportRetention = 24 * time.Hour
+)
+
+// exportJob is a background export. Archives are kept in memory only and
+// dropped by cleanupSessions once exportRetention has passed.
+type exportJob struct {
+	id         uuid.UUID
+	userID     string
+	status     ExportStatusStatus
+	createdAt  time.Time
+	finishedAt time.Time
+	archive    []byte
+}
+
+func (j *exportJob) statusResponse() ExportStatus {
+	response := ExportStatus{Id: j.id, Status: j.status, CreatedAt: j.createdAt}
+	if j.status == Ready {
+		expiresAt := j.finishedAt.Add(exportRetention)
+		response.ExpiresAt = &expiresAt
+	}
+	return response
+}
+
+// collectExport gathers everything stored about a user. It deliberately reads
+// only columns that are safe to hand out: no password hashes, refresh token
+// hashes or verification codes.
+func (s *Server) collectExport(ctx context.Context, userID, sessionID pgtype.UUID) (DataExport, error) {
+	account, err := s.db.GetUserAccount(ctx, userID)
+	if err != nil {
+		return DataExport{}, fmt.Errorf("get account: %w", err)
+	}
+	export := DataExport{
+		GeneratedAt: time.Now().UTC(),
+		Account: ExportAccount{
+			Id:            account.UserID.Bytes,
+			Email:         account.Email,
+			EmailVerified: account.EmailVerified,
+			Roles:         account.Roles,
+			Locked:        account.Locked,
+		},
+		Chats: s.chatTranscripts(uuid.UUID(userID.Bytes).String()),
+	}
+
+	profile, err := s.db.GetUserProfile(ctx, userID)
+	if err == nil {
+		response := profileResponse(profile)
+		export.Profile = &response
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return DataExport{}, fmt.Errorf("get profile: %w", err)
+	}
+
+	sessions, err := s.db.ListAuthSessions(ctx, userID)
+	if err != nil {
+		return DataExport{}, fmt.Errorf("list sessions: %w", err)
+	}
+	export.Sessions = make([]Session, 0, len(sessions))
+	for _, session := range sessions {
+		export.Sessions = append(export.Sessions, Session{
+			Id:         session.SessionID.Bytes,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IpAddress:  session.IpAddress,
+			CreatedAt:  session.CreatedAt.Time,
+			LastUsedAt: session.LastUsedAt.Time,
+			Current:    sessionID.Valid && session.SessionID.Bytes == sessionID.Bytes,
+		})
+	}
+
+	if export.FavoritePharmacies, err = s.favoritePharmacies(ctx, userID); err != nil {
+		return DataExport{}, fmt.Errorf("list favorite pharmacies: %w", err)
+	}
+
+	if export.SavedPlaces, err = s.savedPlaces(ctx, userID); err != nil {
+		return DataExport{}, fmt.Errorf("list saved places: %w", err)
+	}
+
+	events, err := s.db.ListAuditEventsByUser(ctx, userID)
+	if err != nil {
+		return DataExport{}, fmt.Errorf("list audit events: %w", err)
+	}
+	export.AuditEvents = make([]ExportAuditEvent, 0, len(events))
+	for _, event := range events {
+		details := map[string]interface{}{}
+		if err := json.Unmarshal(event.Details, &details); err != nil {
+			slog.WarnContext(ctx, "Undecodable audit event details", "event_type", event.EventType, "err", err)
+		}
+		export.AuditEvents = append(export.AuditEvents, ExportAuditEvent{
+			Type:      event.EventType,
+			CreatedAt: event.CreatedAt.Time,
+			IpAddress: event.IpAddress,
+			UserAgent: event.UserAgent,
+			Details:   details,
+		})
+	}
+	return export, nil
+}
+
+// chatTranscripts returns the text of every chat session owned by userID.
+func (s *Server) chatTranscripts(userID string) []ExportChat {
+	s.sessionMutex.RLock()
+	defer s.sessionMutex.RUnlock()
+
+	chats := []ExportChat{}
+	for _, session := range s.chatSessions {
+		if session.UserID != userID {
+			continue
+		}
+		chat := ExportChat{
+			Id:        session.ID,
+			CreatedAt: session.CreatedAt,
+			UpdatedAt: session.UpdatedAt,
+			Messages:  []ExportChatMessage{},
+		}
+		for _, content := range session.History {
+			for _, part := range content.Parts {
+				if part != nil && part.Text != "" {
+					chat.Messages = append(chat.Messages, ExportChatMessage{Role: content.Role, Text: part.Text})
+				}
+			}
+		}
+		chats = append(chats, chat)
+	}
+	return chats
+}
+
+// exportSize estimates the size of a user's export for choosing between a
+// direct response and a background job.
+func (s *Server) exportSize(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	size, err := s.db.CountAuditEventsByUser(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("count audit events: %w", err)
+	}
+	for _, chat := range s.chatTranscripts(uuid.UUID(userID.Bytes).String()) {
+		size += int64(len(chat.Messages))
+	}
+	return size, nil
+}
+
+// exportArchive packs an export as a ZIP with one JSON file per section.
+func exportArchive(export DataExport) ([]byte, error) {
+	sections := []struct {
+		name string
+		data any
+	}{
+		{"export_info.json", map[string]time.Time{"generated_at": export.GeneratedAt}},
+		{"account.json", export.Account},
+		{"profile.json", export.Profile},
+		{"sessions.json", export.Sessions},
+		{"favorite_pharmacies.json", export.FavoritePharmacies},
+		{"saved_places.json", export.SavedPlaces},
+		{"chats.json", export.Chats},
+		{"audit_events.json", export.AuditEvents},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, section := range sections {
+		f, err := zw.Create(section.name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(section.data); err != nil {
+			return nil, fmt.Errorf("encode %s: %w", section.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// runExport builds the archive of a background export job.
+func (s *Server) runExport(ctx context.Context, job *exportJob, userID, sessionID pgtype.UUID) {
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	export, err := s.collectExport(ctx, userID, sessionID)
+	var archive []byte
+	if err == nil {
+		archive, err = exportArchive(export)
+	}
+
+	s.exportMutex.Lock()
+	defer s.exportMutex.Unlock()
+	job.finishedAt = time.Now()
+	if err != nil {
+		slog.ErrorContext(ctx, "Export failed", "export_id", job.id, "user_id", userID, "err", err)
+		job.status = Failed
+		return
+	}
+	job.status = Ready
+	job.archive = archive
+}
+
+// findExportJob returns the caller's export job, or nil.
+func (s *Server) findExportJob(userID string, exportID uuid.UUID) *exportJob {
+	s.exportMutex.Lock()
+	defer s.exportMutex.Unlock()
+	job, ok := s.exportJobs[exportID]
+	if !ok || job.userID != userID {
+		return nil
+	}
+	return job
+}
+
+// cleanupExports drops exports whose retention has passed.
+func (s *Server) cleanupExports() {
+	s.exportMutex.Lock()
+	defer s.exportMutex.Unlock()
+	for id, job := range s.exportJobs {
+		if job.status != Pending && time.Since(job.finishedAt) > exportRetention {
+			delete(s.exportJobs, id)
+		}
+	}
+}
+
+// deleteExports drops every export of userID, finished or not.
+func (s *Server) deleteExports(userID string) {
+	s.exportMutex.Lock()
+	defer s.exportMutex.Unlock()
+	for id, job := range s.exportJobs {
+		if job.userID == userID {
+			delete(s.exportJobs, id)
+		}
+	}
+}
+
+func (s *Server) ExportAccountData(w http.ResponseWriter, r *http.Request) {
+	userID, sessionID, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+	owner := uuid.UUID(userID.Bytes).String()
+
+	size, err := s.exportSize(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error estimating export", "handler", "ExportAccountData", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to export account data"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if size <= syncExportMaxItems {
+		export, err := s.collectExport(r.Context(), userID, sessionID)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Error exporting data", "handler", "ExportAccountData", "user_id", userID, "err", err)
+			http.Error(w, `{"message": "failed to export account data"}`, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(export); err != nil {
+			slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ExportAccountData", "err", err)
+		}
+		return
+	}
+
+	exportID, err := uuid.NewRandom()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error generating export ID", "handler", "ExportAccountData", "err", err)
+		http.Error(w, `{"message": "failed to export account data"}`, http.StatusInternalServerError)
+		return
+	}
+	job := &exportJob{id: exportID, userID: owner, status: Pending, createdAt: time.Now().UTC()}
+
+	s.exportMutex.Lock()
+	for _, other := range s.exportJobs {
+		if other.userID == owner && other.status == Pending {
+			s.exportMutex.Unlock()
+			http.Error(w, `{"message": "an export is already being prepared"}`, http.StatusConflict)
+			return
+		}
+	}
+	s.exportJobs[exportID] = job
+	response := job.statusResponse()
+	s.exportMutex.Unlock()
+
+	// The export outlives the request but keeps its values, such as the
+	// request ID its log lines are tagged with. It is cancelled on shutdown.
+	s.goBackground(r.Context(), func(ctx context.Context) {
+		s.runExport(ctx, job, userID, sessionID)
+	})
+
+	w.Header().Set("Location", "/api/account/export/"+exportID.String())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding accepted response", "handler", "ExportAccountData", "err", err)
+	}
+}
+
+func (s *Server) GetAccountExport(w http.ResponseWriter, r *http.Request, exportId uuid.UUID) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	job := s.findExportJob(uuid.UUID(userID.Bytes).String(), exportId)
+	if job == nil {
+		http.Error(w, `{"message": "export not found"}`, http.StatusNotFound)
+		return
+	}
+	s.exportMutex.Lock()
+	response := job.statusResponse()
+	s.exportMutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "GetAccountExport", "err", err)
+	}
+}
+
+func (s *Server) DownloadAccountExport(w http.ResponseWriter, r *http.Request, exportId uuid.UUID) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	job := s.findExportJob(uuid.UUID(userID.Bytes).String(), exportId)
+	if job == nil {
+		http.Error(w, `{"message": "export not found"}`, http.StatusNotFound)
+		return
+	}
+	s.exportMutex.Lock()
+	status, archive, createdAt := job.status, job.archive, job.createdAt
+	s.exportMutex.Unlock()
+	if status != Ready {
+		http.Error(w, `{"message": "export is not ready"}`, http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="account-export-%s.zip"`, createdAt.Format("2006-01-02")))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(archive); err != nil {
+		slog.ErrorContext(r.Context(), "Error writing archive", "handler", "DownloadAccountExport", "err", err)
+	}
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	db "voice_assistant/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxFavoritePharmacies caps how many favorites a user can keep; the whole
+// list is handed to the model by the my_pharmacies tool.
+const maxFavoritePharmacies = 10
+
+func favoritePharmacyResponse(f db.ListFavoritePharmaciesRow) FavoritePharmacy {
+	return FavoritePharmacy{
+		LocationId:     f.LocationID,
+		Label:          f.Label,
+		PharmacyName:   f.PharmacyName,
+		PharmacyNumber: f.PharmacyNumber,
+		Phone:          f.Phone,
+		Text:           f.Text,
+		Latitude:       f.Latitude,
+		Longitude:      f.Longitude,
+		CreatedAt:      f.CreatedAt.Time,
+	}
+}
+
+func (s *Server) favoritePharmacies(ctx context.Context, userID pgtype.UUID) ([]FavoritePharmacy, error) {
+	rows, err := s.db.ListFavoritePharmacies(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	favorites := make([]FavoritePharmacy, 0, len(rows))
+	for _, row := range rows {
+		favorites = append(favorites, favoritePharmacyResponse(row))
+	}
+	return favorites, nil
+}
+
+// favoritesSummary answers the my_pharmacies tool with the user's favorites,
+// so "моя аптека" is resolved without a vector search.
+func (s *Server) favoritesSummary(ctx context.Context, userID pgtype.UUID) (summary string, count int, err error) {
+	favorites, err := s.favoritePharmacies(ctx, userID)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(favorites) == 0 {
+		return "У пользователя нет сохранённых аптек. Предложи назвать аптеку или найти ближайшую.", 0, nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Сохранённые аптеки пользователя:\n")
+	for i, f := range favorites {
+		b.WriteString(fmt.Sprintf("%d. ", i+1))
+		if f.Label != "" {
+			b.WriteString("«" + f.Label + "»: ")
+		}
+		b.WriteString(f.Text + "\n")
+	}
+	return b.String(), len(favorites), nil
+}
+
+func (s *Server) ListFavoritePharmacies(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	favorites, err := s.favoritePharmacies(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error listing favorites", "handler", "ListFavoritePharmacies", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to list favorite pharmacies"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(FavoritePharmacyList{Favorites: favorites}); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ListFavoritePharmacies", "err", err)
+	}
+}
+
+func (s *Server) CreateFavoritePharmacy(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "CreateFavoritePharmacy", "err", err)
+		return
+	}
+
+	var createRequest CreateFavoritePharmacyRequest
+	if err := json.Unmarshal(bodyBytes, &createRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "CreateFavoritePharmacy", "err", err)
+		return
+	}
+
+	var label string
+	if createRequest.Label != nil {
+		label = strings.TrimSpace(*createRequest.Label)
+	}
+	if len([]rune(label)) > maxProfileTextLength {
+		http.Error(w, `{"message": "label must be at most 64 characters"}`, http.StatusBadRequest)
+		return
+	}
+
+	count, err := s.db.CountFavoritePharmacies(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error counting favorites", "handler", "CreateFavoritePharmacy", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to add favorite pharmacy"}`, http.StatusInternalServerError)
+		return
+	}
+	if count >= maxFavoritePharmacies {
+		http.Error(w, fmt.Sprintf(`{"message": "at most %d favorite pharmacies can be saved"}`, maxFavoritePharmacies), http.StatusBadRequest)
+		return
+	}
+
+	created, err := s.db.CreateFavoritePharmacy(r.Context(), db.CreateFavoritePharmacyParams{
+		UserID:     userID,
+		Label:      label,
+		LocationID: createRequest.LocationId,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			http.Error(w, `{"message": "pharmacy is already a favorite"}`, http.StatusConflict)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Database error adding favorite", "handler", "CreateFavoritePharmacy", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to add favorite pharmacy"}`, http.StatusInternalServerError)
+		return
+	}
+	if created == 0 {
+		http.Error(w, `{"message": "pharmacy not found"}`, http.StatusNotFound)
+		return
+	}
+
+	s.writeFavoritePharmacy(w, r, "CreateFavoritePharmacy", userID, createRequest.LocationId, http.StatusCreated)
+}
+
+func (s *Server) UpdateFavoritePharmacy(w http.ResponseWriter, r *http.Request, locationId int32) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "UpdateFavoritePharmacy", "err", err)
+		return
+	}
+
+	var updateRequest UpdateFavoritePharmacyRequest
+	if err := json.Unmarshal(bodyBytes, &updateRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "UpdateFavoritePharmacy", "err", err)
+		return
+	}
+
+	label := strings.TrimSpace(updateRequest.Label)
+	if len([]rune(label)) > maxProfileTextLength {
+		http.Error(w, `{"message": "label must be at most 64 characters"}`, http.StatusBadRequest)
+		return
+	}
+
+	updated, err := s.db.UpdateFavoritePharmacyLabel(r.Context(), db.UpdateFavoritePharmacyLabelParams{
+		Label:      label,
+		UserID:     userID,
+		LocationID: locationId,
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error renaming favorite", "handler", "UpdateFavoritePharmacy", "location_id", locationId, "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to update favorite pharmacy"}`, http.StatusInternalServerError)
+		return
+	}
+	if updated == 0 {
+		http.Error(w, `{"message": "favorite pharmacy not found"}`, http.StatusNotFound)
+		return
+	}
+
+	s.writeFavoritePharmacy(w, r, "UpdateFavoritePharmacy", userID, locationId, http.StatusOK)
+}
+
+func (s *Server) DeleteFavoritePharmacy(w http.ResponseWriter, r *http.Request, locationId int32) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	deleted, err := s.db.DeleteFavoritePharmacy(r.Context(), db.DeleteFavoritePharmacyParams{UserID: userID, LocationID: locationId})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error removing favorite", "handler", "DeleteFavoritePharmacy", "location_id", locationId, "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to remove favorite pharmacy"}`, http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, `{"message": "favorite pharmacy not found"}`, http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeFavoritePharmacy answers with the stored favorite after a change.
+func (s *Server) writeFavoritePharmacy(w http.ResponseWriter, r *http.Request, handler string, userID pgtype.UUID, locationID int32, status int) {
+	favorite, err := s.db.GetFavoritePharmacy(r.Context(), db.GetFavoritePharmacyParams{UserID: userID, LocationID: locationID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, `{"message": "favorite pharmacy not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error loading favorite", "handler", handler, "location_id", locationID, "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to load favorite pharmacy"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(favoritePharmacyResponse(db.ListFavoritePharmaciesRow(favorite))); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", handler, "err", err)
+	}
+}
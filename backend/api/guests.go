@@ -0,0 +1,253 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	db "voice_assistant/db/sqlc"
+	"voice_assistant/logging"
+	"voice_assistant/metrics"
+	"voice_assistant/tools"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// guestEmailDomain is the reserved (RFC 2606) domain of the placeholder
+// addresses guests are stored with until they upgrade.
+const guestEmailDomain = "guest.invalid"
+
+func guestEmail(userID uuid.UUID) string {
+	return "guest-" + userID.String() + "@" + guestEmailDomain
+}
+
+// isGuest reports whether the request's access token was issued to a guest.
+func isGuest(r *http.Request) bool {
+	roles, _ := r.Context().Value(tools.RolesContextKey).([]string)
+	return slices.Contains(roles, tools.RoleGuest)
+}
+
+func (s *Server) GuestLogin(w http.ResponseWriter, r *http.Request) {
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "GuestLogin", "err", err)
+		return
+	}
+
+	var guestRequest GuestRequest
+	if err := json.Unmarshal(bodyBytes, &guestRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "GuestLogin", "err", err)
+		return
+	}
+
+	if len(guestRequest.DeviceKey) < 32 {
+		http.Error(w, `{"message": "device key must be at least 32 characters"}`, http.StatusBadRequest)
+		return
+	}
+	deviceKeyHash := s.jwtAuth.HashDeviceKey(guestRequest.DeviceKey)
+
+	guest, err := s.db.GetGuestByDeviceKey(r.Context(), deviceKeyHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		guest, err = s.createGuest(w, r, deviceKeyHash)
+		if err != nil {
+			return
+		}
+	} else if err != nil {
+		slog.ErrorContext(r.Context(), "Database error looking up device", "handler", "GuestLogin", "err", err)
+		metrics.LoginAttempt(metrics.LoginGuest, metrics.OutcomeError)
+		http.Error(w, `{"message": "failed to sign in as guest"}`, http.StatusInternalServerError)
+		return
+	}
+
+	accessToken, refreshTokenString, err := s.startSession(r, guest.UserID, guest.Roles, guestRequest.DeviceName)
+	if err != nil {
+		metrics.LoginAttempt(metrics.LoginGuest, sessionOutcome(err))
+		writeStartSessionError(w, r, "GuestLogin", guestEmail(guest.UserID.Bytes), err)
+		return
+	}
+	metrics.LoginAttempt(metrics.LoginGuest, metrics.OutcomeSuccess)
+
+	response := LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshTokenString,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "GuestLogin", "err", err)
+	}
+}
+
+// createGuest creates a guest bound to the device key. Each address may only
+// create a few guests per hour, since every guest comes with its own quota.
+// Errors have already been answered when it returns one.
+func (s *Server) createGuest(w http.ResponseWriter, r *http.Request, deviceKeyHash string) (db.GetGuestByDeviceKeyRow, error) {
+	ip := clientIP(r)
+	recent, err := s.db.CountRecentGuests(r.Context(), ip)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error counting guests", "handler", "GuestLogin", "err", err)
+		metrics.LoginAttempt(metrics.LoginGuest, metrics.OutcomeError)
+		http.Error(w, `{"message": "failed to sign in as guest"}`, http.StatusInternalServerError)
+		return db.GetGuestByDeviceKeyRow{}, err
+	}
+	if limit := s.jwtAuth.Config.GuestHourlyLimitPerIP; limit > 0 && recent >= int64(limit) {
+		slog.WarnContext(r.Context(), "Guest limit reached", "handler", "GuestLogin")
+		metrics.LoginAttempt(metrics.LoginGuest, "limited")
+		w.Header().Set("Retry-After", strconv.Itoa(int(quotaWindow.Seconds())))
+		http.Error(w, `{"message": "too many guest accounts created, try again later"}`, http.StatusTooManyRequests)
+		return db.GetGuestByDeviceKeyRow{}, errors.New("guest limit reached")
+	}
+
+	userID, err := uuid.NewRandom()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error generating userID", "handler", "GuestLogin", "err", err)
+		metrics.LoginAttempt(metrics.LoginGuest, metrics.OutcomeError)
+		http.Error(w, `{"message": "failed to generate user ID"}`, http.StatusInternalServerError)
+		return db.GetGuestByDeviceKeyRow{}, err
+	}
+
+	guest := db.GetGuestByDeviceKeyRow{
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+		Roles:  []string{tools.RoleGuest},
+	}
+	err = s.db.CreateGuest(r.Context(), db.CreateGuestParams{
+		UserID:        guest.UserID,
+		Email:         guestEmail(userID),
+		DeviceKeyHash: deviceKeyHash,
+		IpAddress:     ip,
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error creating guest in DB", "handler", "GuestLogin", "err", err)
+		metrics.LoginAttempt(metrics.LoginGuest, metrics.OutcomeError)
+		http.Error(w, `{"message": "failed to sign in as guest"}`, http.StatusInternalServerError)
+		return db.GetGuestByDeviceKeyRow{}, err
+	}
+	return guest, nil
+}
+
+func (s *Server) UpgradeGuest(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+	if !isGuest(r) {
+		http.Error(w, `{"message": "account is not a guest"}`, http.StatusBadRequest)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "UpgradeGuest", "err", err)
+		return
+	}
+
+	var upgradeRequest UpgradeGuestRequest
+	if err := json.Unmarshal(bodyBytes, &upgradeRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "UpgradeGuest", "err", err)
+		return
+	}
+
+	if upgradeRequest.Email == "" || upgradeRequest.Password == "" {
+		http.Error(w, `{"message": "email and password are required"}`, http.StatusBadRequest)
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(upgradeRequest.Password), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, `{"message": "failed to hash password"}`, http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error hashing password", "handler", "UpgradeGuest", "err", err)
+		return
+	}
+
+	if _, err := s.db.GetUserByEmail(r.Context(), upgradeRequest.Email); err == nil {
+		http.Error(w, `{"message": "user with this email already exists"}`, http.StatusConflict)
+		return
+	} else if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, pgx.ErrNoRows) {
+		slog.ErrorContext(r.Context(), "Database error fetching user", "handler", "UpgradeGuest", "email", logging.Email(upgradeRequest.Email), "err", err)
+		http.Error(w, `{"message": "failed to upgrade account"}`, http.StatusInternalServerError)
+		return
+	}
+
+	// The address is not claimed until it is confirmed: it is kept with the
+	// code, and ConfirmEmail writes it to the account and swaps the guest role
+	// for `user`. Until then the guest keeps its role and device key.
+	upgraded, err := s.db.UpgradeGuest(r.Context(), db.UpgradeGuestParams{
+		UserID:   userID,
+		Password: string(hashedPassword),
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error upgrading guest", "handler", "UpgradeGuest", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to upgrade account"}`, http.StatusInternalServerError)
+		return
+	}
+	if upgraded == 0 {
+		http.Error(w, `{"message": "account is not a guest"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.issueCode(r, userID, upgradeRequest.Email, purposeGuestUpgrade); err != nil {
+		writeIssueCodeError(w, r, "UpgradeGuest", upgradeRequest.Email, err)
+		return
+	}
+
+	response := UpgradeGuestResponse{
+		Message: "A confirmation code has been sent. Confirm the email to finish creating the account.",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "UpgradeGuest", "err", err)
+	}
+}
+
+// errNoPendingUpgrade means no guest is waiting to confirm the address.
+var errNoPendingUpgrade = errors.New("no pending guest upgrade")
+
+// confirmGuestUpgrade redeems a guest upgrade code sent to email and assigns
+// the address to the guest. The address is not claimed until confirmed, so
+// several guests may have asked for it; the code is only checked against the
+// newest of them, so a wrong guess never counts against anyone else's code.
+func (s *Server) confirmGuestUpgrade(ctx context.Context, email, code string) (confirmed db.ConfirmGuestUpgradeRow, remaining int32, err error) {
+	guestID, err := s.db.GetPendingGuestUpgrade(ctx, db.GetPendingGuestUpgradeParams{
+		NewEmail: pgtype.Text{String: email, Valid: true},
+		Purpose:  purposeGuestUpgrade,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			return confirmed, 0, errNoPendingUpgrade
+		}
+		return confirmed, 0, fmt.Errorf("get pending guest upgrade: %w", err)
+	}
+
+	active, remaining, err := s.checkCode(ctx, guestID, purposeGuestUpgrade, code)
+	if err != nil {
+		return confirmed, remaining, err
+	}
+	// The guest asked for another address after it was looked up.
+	if active.NewEmail.String != email {
+		return confirmed, 0, errCodeInvalid
+	}
+	confirmed, err = s.db.ConfirmGuestUpgrade(ctx, db.ConfirmGuestUpgradeParams{UserID: guestID, Email: email})
+	if err != nil {
+		return confirmed, 0, fmt.Errorf("confirm guest upgrade: %w", err)
+	}
+	return confirmed, 0, nil
+}
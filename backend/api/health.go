@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"voice_assistant/health"
+
+	chromago "github.com/amikos-tech/chroma-go/pkg/api/v2"
+)
+
+// ReadinessChecks returns the checks of the dependencies only the server
+// knows how to reach: the pharmacy collection in Chroma and, when checkLLM is
+// set, the chat model. The LLM is optional, since a provider outage degrades
+// chat but leaves the rest of the API working.
+func (s *Server) ReadinessChecks(checkLLM bool) []health.Check {
+	checks := []health.Check{{
+		Name:     "chroma_collection",
+		Required: true,
+		Run: func(ctx context.Context) error {
+			_, err := s.chromaDBClient.GetCollection(ctx, s.chromaCollectionName, chromago.WithEmbeddingFunctionGet(s.ef))
+			return err
+		},
+	}}
+	if checkLLM {
+		checks = append(checks, health.Check{
+			Name: "llm",
+			Run: func(ctx context.Context) error {
+				_, err := s.genaiClient.Models.Get(ctx, s.chatModel, nil)
+				return err
+			},
+		})
+	}
+	return checks
+}
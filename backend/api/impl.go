@@ -8,7 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"math"
 	"math/big"
 	mathrand "math/rand"
 	"net/http"
@@ -19,21 +20,32 @@ import (
 	"time"
 	"unicode"
 	db "voice_assistant/db/sqlc"
+	"voice_assistant/logging"
+	"voice_assistant/mail"
+	"voice_assistant/metrics"
 	"voice_assistant/tools"
+	"voice_assistant/tracing"
+	"voice_assistant/util"
 
 	chromago "github.com/amikos-tech/chroma-go/pkg/api/v2"
+	"github.com/amikos-tech/chroma-go/pkg/embeddings"
 	g "github.com/amikos-tech/chroma-go/pkg/embeddings/gemini"
 	genaiembs "github.com/google/generative-ai-go/genai"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/texttheater/golang-levenshtein/levenshtein"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/genai"
 )
 
 var _ ServerInterface = (*Server)(nil)
 
+var tracer = tracing.Tracer("voice_assistant/api")
+
 type ExtractedQueryParams struct {
 	PharmacyName   string `json:"pharmacy_name"`
 	PharmacyNumber string `json:"pharmacy_number"`
@@ -44,55 +56,155 @@ type ExtractedQueryParams struct {
 
 type ChatSession struct {
 	ID              string
+	UserID          string // owner; chat sessions are keyed by owner and ID
 	History         []*genai.Content
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	CurrentPharmacy *PharmacyContext // Track current pharmacy context
 }
 
+// chatSettings are the tunables of the chat pipeline.
+type chatSettings struct {
+	maxUploadBytes  int64
+	searchResults   int
+	contextResults  int
+	historyTTL      time.Duration // history is dropped once a session is this old
+	sessionTTL      time.Duration // idle sessions are dropped after this
+	cleanupInterval time.Duration
+	llmTimeout      time.Duration
+
+	// Largest edit distances at which a Chroma hit still matches the
+	// extracted query parameters.
+	nameDistance   int
+	numberDistance int
+	cityDistance   int
+	streetDistance int
+	houseDistance  int
+}
+
+func newChatSettings(config util.Config) chatSettings {
+	return chatSettings{
+		maxUploadBytes:  config.ChatMaxUploadBytes,
+		searchResults:   config.ChatSearchResults,
+		contextResults:  config.ChatContextResults,
+		historyTTL:      config.ChatHistoryTTL,
+		sessionTTL:      config.ChatSessionTTL,
+		cleanupInterval: config.CleanupInterval,
+		llmTimeout:      config.LLMTimeout,
+		nameDistance:    config.FuzzyPharmacyNameDistance,
+		numberDistance:  config.FuzzyPharmacyNumberDistance,
+		cityDistance:    config.FuzzyCityDistance,
+		streetDistance:  config.FuzzyStreetDistance,
+		houseDistance:   config.FuzzyHouseNumberDistance,
+	}
+}
+
 type Server struct {
 	jwtAuth              tools.Authenticator
 	genaiClient          *genai.Client
 	chatModel            string
+	chat                 chatSettings
 	chromaDBClient       chromago.Client
 	chromaCollectionName string
-	ef                   *g.GeminiEmbeddingFunction
+	ef                   embeddings.EmbeddingFunction
+	pool                 *pgxpool.Pool
 	db                   *db.Queries
+	mailer               mail.Mailer
+	revocations          *tools.RevocationStore
+	apiKeys              *tools.APIKeyStore
+	codePolicy           codePolicy
+	chatQuota            *chatQuota
 	chatSessions         map[string]*ChatSession
 	sessionMutex         sync.RWMutex
+	exportJobs           map[uuid.UUID]*exportJob
+	exportMutex          sync.Mutex
+	background           context.Context
+	stopBackground       context.CancelFunc
+	workers              sync.WaitGroup
 }
 
-func NewServer(jwtAuth tools.Authenticator, client *genai.Client, clientEmbs *genaiembs.Client, chromaDBClient chromago.Client, chromaCollection string, db *db.Queries) *Server {
-	chatModelName := "gemini-2.0-flash"
+func NewServer(jwtAuth tools.Authenticator, client *genai.Client, clientEmbs *genaiembs.Client, chromaDBClient chromago.Client, chromaCollection string, pool *pgxpool.Pool, mailer mail.Mailer, revocations *tools.RevocationStore, apiKeys *tools.APIKeyStore) *Server {
+	config := jwtAuth.Config
 
-	ef, err := g.NewGeminiEmbeddingFunction(g.WithEnvAPIKey(), g.WithDefaultModel("text-embedding-004"), g.WithClient(clientEmbs))
+	ef, err := g.NewGeminiEmbeddingFunction(
+		g.WithAPIKey(config.GoogleAPIKey),
+		g.WithDefaultModel(embeddings.EmbeddingModel(config.GoogleEmbeddingModelName)),
+		g.WithClient(clientEmbs),
+	)
 	if err != nil {
 		// It's better to handle this error more gracefully, perhaps by returning an error from NewServer
-		log.Fatalf("Error creating Gemini embedding function: %s \n", err)
+		logging.Fatal("Error creating Gemini embedding function", "err", err)
 	}
 
 	s := &Server{
 		jwtAuth:              jwtAuth,
 		genaiClient:          client,
-		chatModel:            chatModelName,
+		chatModel:            config.GoogleChatModelName,
+		chat:                 newChatSettings(config),
 		chromaDBClient:       chromaDBClient,
 		chromaCollectionName: chromaCollection,
-		ef:                   ef,
-		db:                   db,
+		ef:                   tracing.EmbeddingFunction(ef),
+		pool:                 pool,
+		db:                   db.New(pool),
+		mailer:               mailer,
+		revocations:          revocations,
+		apiKeys:              apiKeys,
+		codePolicy:           newCodePolicy(config),
+		chatQuota:            newChatQuota(config),
 		chatSessions:         make(map[string]*ChatSession),
+		exportJobs:           make(map[uuid.UUID]*exportJob),
 	}
+	s.background, s.stopBackground = context.WithCancel(context.Background())
 
-	s.cleanupSessions()
+	metrics.RegisterChatSessions(func() int {
+		s.sessionMutex.RLock()
+		defer s.sessionMutex.RUnlock()
+		return len(s.chatSessions)
+	})
+	s.goBackground(context.Background(), s.cleanupSessions)
 
 	return s
 }
 
+// goBackground runs f in a goroutine that Shutdown waits for. The context f
+// gets carries the values of parent, such as the request ID, and is cancelled
+// when the server shuts down.
+func (s *Server) goBackground(parent context.Context, f func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	stop := context.AfterFunc(s.background, cancel)
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		defer stop()
+		defer cancel()
+		f(ctx)
+	}()
+}
+
+// Shutdown cancels the background workers, such as session cleanup and data
+// exports, and waits for them to return or for ctx to be done. It must be
+// called after the HTTP server stopped handling requests.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopBackground()
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (s *Server) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
 	bodyBytes, err := io.ReadAll(r.Body)
 	defer func() { _ = r.Body.Close() }()
 	if err != nil {
 		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
-		log.Printf("[ConfirmEmail] Error reading request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "ConfirmEmail", "err", err)
 		return
 	}
 
@@ -101,7 +213,7 @@ func (s *Server) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
-		log.Printf("[ConfirmEmail] Error unmarshalling request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "ConfirmEmail", "err", err)
 		return
 	}
 
@@ -110,41 +222,54 @@ func (s *Server) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	refreshTokenString, refreshTokenExpiresAt, err := s.jwtAuth.GenerateRefreshToken()
-	if err != nil {
-		http.Error(w, `{"message": "failed to generate refresh token"}`, http.StatusInternalServerError)
-		log.Printf("[ConfirmEmail] Error generating refresh token for user : %v", err)
-		return
-	}
-	updateCodeByIdParams := db.ConfirmEmailWithTokensParams{
-		Email:        confirmEmailRequest.Email,
-		Code:         pgtype.Text{String: confirmEmailRequest.Code, Valid: true},
-		RefreshToken: pgtype.Text{String: refreshTokenString, Valid: true},
-		ExpiredAt:    pgtype.Timestamp{Time: refreshTokenExpiresAt, Valid: true},
-	}
-
-	pgUserID, err := s.db.ConfirmEmailWithTokens(r.Context(), updateCodeByIdParams)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
-			log.Printf("[ConfirmEmail] User/code not found for email %s, code %s: %v", confirmEmailRequest.Email, confirmEmailRequest.Code, err)
+	var confirmedUser db.ConfirmEmailRow
+	user, err := s.db.GetUserByEmail(r.Context(), confirmEmailRequest.Email)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows):
+		// No account has the address yet; it may be pending for a guest.
+		upgraded, remaining, err := s.confirmGuestUpgrade(r.Context(), confirmEmailRequest.Email, confirmEmailRequest.Code)
+		switch {
+		case errors.Is(err, errNoPendingUpgrade):
+			slog.WarnContext(r.Context(), "User not found", "handler", "ConfirmEmail", "email", logging.Email(confirmEmailRequest.Email))
 			http.Error(w, `{"message": "User for the provided email/code not found or code is invalid"}`, http.StatusNotFound)
 			return
+		case isUniqueViolation(err):
+			slog.WarnContext(r.Context(), "Address taken before guest upgrade was confirmed", "handler", "ConfirmEmail", "email", logging.Email(confirmEmailRequest.Email))
+			http.Error(w, `{"message": "user with this email already exists"}`, http.StatusConflict)
+			return
+		case err != nil:
+			slog.WarnContext(r.Context(), "Code rejected", "handler", "ConfirmEmail", "email", logging.Email(confirmEmailRequest.Email), "err", err)
+			writeCodeError(w, r, "ConfirmEmail", remaining, err)
+			return
 		}
+		confirmedUser = db.ConfirmEmailRow(upgraded)
+	case err != nil:
 		http.Error(w, `{"message": "failed to confirm email address"}`, http.StatusInternalServerError)
-		log.Printf("[ConfirmEmail] Database error for email %s, code %s: %v", confirmEmailRequest.Email, confirmEmailRequest.Code, err)
+		slog.ErrorContext(r.Context(), "Database error", "handler", "ConfirmEmail", "email", logging.Email(confirmEmailRequest.Email), "err", err)
 		return
-	}
+	default:
+		if user.EmailVerified {
+			http.Error(w, `{"message": "email is already verified"}`, http.StatusBadRequest)
+			return
+		}
 
-	u, err := uuid.FromBytes(pgUserID.Bytes[:])
-	if err != nil {
-		http.Error(w, `{"message": "internal server error - user ID conversion failed"}`, http.StatusInternalServerError)
-		return
+		if _, remaining, err := s.checkCode(r.Context(), user.UserID, purposeEmailVerification, confirmEmailRequest.Code); err != nil {
+			slog.WarnContext(r.Context(), "Code rejected", "handler", "ConfirmEmail", "email", logging.Email(confirmEmailRequest.Email), "err", err)
+			writeCodeError(w, r, "ConfirmEmail", remaining, err)
+			return
+		}
+
+		confirmedUser, err = s.db.ConfirmEmail(r.Context(), user.UserID)
+		if err != nil {
+			http.Error(w, `{"message": "failed to confirm email address"}`, http.StatusInternalServerError)
+			slog.ErrorContext(r.Context(), "Database error", "handler", "ConfirmEmail", "email", logging.Email(confirmEmailRequest.Email), "err", err)
+			return
+		}
 	}
 
-	accessToken, err := s.jwtAuth.GenerateToken(u)
+	accessToken, refreshTokenString, err := s.startSession(r, confirmedUser.UserID, confirmedUser.Roles, confirmEmailRequest.DeviceName)
 	if err != nil {
-		http.Error(w, `{"message": "failed to generate access token"}`, http.StatusInternalServerError)
-		log.Printf("[ConfirmEmail] Error generating access token: %v", err)
+		writeStartSessionError(w, r, "ConfirmEmail", confirmEmailRequest.Email, err)
 		return
 	}
 
@@ -156,7 +281,7 @@ func (s *Server) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("[ConfirmEmail] Error encoding success response: %v", err)
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ConfirmEmail", "err", err)
 	}
 }
 
@@ -165,7 +290,7 @@ func (s *Server) Login(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = r.Body.Close() }()
 	if err != nil {
 		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
-		log.Printf("[Login] Error reading request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "Login", "err", err)
 		return
 	}
 
@@ -174,7 +299,7 @@ func (s *Server) Login(w http.ResponseWriter, r *http.Request) {
 	err = json.Unmarshal(bodyBytes, &loginRequest)
 	if err != nil {
 		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
-		log.Printf("[Login] Error unmarshalling request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "Login", "err", err)
 		return
 	}
 
@@ -187,60 +312,39 @@ func (s *Server) Login(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
-			log.Printf("[Login] User not found for email: %s", loginRequest.Email)
+			slog.WarnContext(r.Context(), "User not found", "handler", "Login", "email", logging.Email(loginRequest.Email))
+			metrics.LoginAttempt(metrics.LoginPassword, "unknown_user")
 			http.Error(w, `{"message": "invalid email or password"}`, http.StatusUnauthorized)
 			return
 		}
-		log.Printf("[Login] Database error fetching user details for email %s: %v", loginRequest.Email, err)
+		slog.ErrorContext(r.Context(), "Database error fetching user details", "handler", "Login", "email", logging.Email(loginRequest.Email), "err", err)
+		metrics.LoginAttempt(metrics.LoginPassword, metrics.OutcomeError)
 		http.Error(w, `{"message": "internal server error while fetching user data"}`, http.StatusInternalServerError)
 		return
 	}
 
-	if userDetails.Code.Valid && userDetails.Code.String != "" {
-		log.Printf("[Login] User email not verified for: %s. Code: '%s'", loginRequest.Email, userDetails.Code.String)
-		http.Error(w, `{"message": "Please set a password"}`, http.StatusBadRequest)
-		return
-	}
-
 	err = bcrypt.CompareHashAndPassword([]byte(userDetails.Password), []byte(loginRequest.Password))
 	if err != nil {
-		log.Printf("[Login] Invalid password for email: %s", loginRequest.Email)
+		slog.WarnContext(r.Context(), "Invalid password", "handler", "Login", "email", logging.Email(loginRequest.Email))
+		metrics.LoginAttempt(metrics.LoginPassword, "wrong_password")
 		http.Error(w, `{"message": "invalid email or password"}`, http.StatusUnauthorized)
 		return
 	}
 
-	refreshTokenString, refreshTokenExpiresAt, err := s.jwtAuth.GenerateRefreshToken()
-	if err != nil {
-		http.Error(w, `{"message": "failed to generate refresh token"}`, http.StatusInternalServerError)
-		log.Printf("[Login] Error generating refresh token for user %s: %v", loginRequest.Email, err)
-		return
-	}
-
-	updateTokenParams := db.UpdateRefreshTokenParams{
-		RefreshToken: pgtype.Text{String: refreshTokenString, Valid: true},
-		ExpiredAt:    pgtype.Timestamp{Time: refreshTokenExpiresAt, Valid: true},
-		UserID:       userDetails.UserID,
-	}
-	err = s.db.UpdateRefreshToken(r.Context(), updateTokenParams)
-	if err != nil {
-		log.Printf("[Login] Failed to update refresh token for user %s (ID: %s): %v", loginRequest.Email, userDetails.UserID.Bytes, err)
-		http.Error(w, `{"message": "failed to save session"}`, http.StatusInternalServerError)
+	if !userDetails.EmailVerified {
+		slog.WarnContext(r.Context(), "User email not verified", "handler", "Login", "email", logging.Email(loginRequest.Email))
+		metrics.LoginAttempt(metrics.LoginPassword, "unverified")
+		http.Error(w, `{"message": "email is not verified"}`, http.StatusBadRequest)
 		return
 	}
 
-	appUserID, err := uuid.FromBytes(userDetails.UserID.Bytes[:])
+	accessToken, refreshTokenString, err := s.startSession(r, userDetails.UserID, userDetails.Roles, loginRequest.DeviceName)
 	if err != nil {
-		http.Error(w, `{"message": "internal server error - user ID conversion failed"}`, http.StatusInternalServerError)
-		log.Printf("[Login] Error converting user ID for %s: %v", loginRequest.Email, err)
-		return
-	}
-
-	accessToken, err := s.jwtAuth.GenerateToken(appUserID)
-	if err != nil {
-		http.Error(w, `{"message": "failed to generate access token"}`, http.StatusInternalServerError)
-		log.Printf("[Login] Error generating access token: %v", err)
+		metrics.LoginAttempt(metrics.LoginPassword, sessionOutcome(err))
+		writeStartSessionError(w, r, "Login", loginRequest.Email, err)
 		return
 	}
+	metrics.LoginAttempt(metrics.LoginPassword, metrics.OutcomeSuccess)
 
 	response := LoginResponse{
 		Token:        accessToken,
@@ -250,7 +354,7 @@ func (s *Server) Login(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("[Login] Error encoding success response for user %s: %v", loginRequest.Email, err)
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "Login", "email", logging.Email(loginRequest.Email), "err", err)
 	}
 
 }
@@ -260,7 +364,7 @@ func (s *Server) RefreshTokens(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = r.Body.Close() }()
 	if err != nil {
 		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
-		log.Printf("[ValidateRefreshToken] Error reading request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "ValidateRefreshToken", "err", err)
 		return
 	}
 
@@ -269,7 +373,7 @@ func (s *Server) RefreshTokens(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
-		log.Printf("[ValidateRefreshToken] Error unmarshalling request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "ValidateRefreshToken", "err", err)
 		return
 	}
 
@@ -278,57 +382,62 @@ func (s *Server) RefreshTokens(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	params := db.VerifyRefreshTokenParams{
-		RefreshToken: pgtype.Text{String: refreshRequest.RefreshToken, Valid: true},
-		ExpiredAt:    pgtype.Timestamp{Time: time.Now(), Valid: true},
+	newRefreshTokenString, newRefreshTokenExpiresAt, err := s.jwtAuth.GenerateRefreshToken()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error generating new refresh token", "handler", "RefreshTokens", "err", err)
+		http.Error(w, `{"message": "failed to generate new refresh token"}`, http.StatusInternalServerError)
+		return
 	}
 
-	pgDbUserID, err := s.db.VerifyRefreshToken(r.Context(), params)
-
+	// Rotation is a single UPDATE keyed by the old token's hash, so a token
+	// can be redeemed only once and only for its own session. Sessions stored
+	// before refresh token hashes were keyed are still found by their legacy hash.
+	refreshTokenHash := s.jwtAuth.HashRefreshToken(refreshRequest.RefreshToken)
+	rotated, err := s.db.RotateAuthSession(r.Context(), db.RotateAuthSessionParams{
+		NewRefreshTokenHash: s.jwtAuth.HashRefreshToken(newRefreshTokenString),
+		TtlSeconds:          time.Until(newRefreshTokenExpiresAt).Seconds(),
+		UserAgent:           truncate(r.UserAgent(), maxDeviceNameLength),
+		IpAddress:           clientIP(r),
+		RefreshTokenHashes:  []string{refreshTokenHash, s.jwtAuth.LegacyHashRefreshToken(refreshRequest.RefreshToken)},
+		RefreshTokenHash:    refreshTokenHash,
+	})
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			log.Printf("[RefreshTokens] Invalid or expired refresh token: %s", refreshRequest.RefreshToken)
+		if errors.Is(err, pgx.ErrNoRows) {
+			reused, err := s.revokeReusedRefreshToken(r, refreshTokenHash)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Database error checking refresh token reuse", "handler", "RefreshTokens", "err", err)
+			}
+			if reused {
+				slog.WarnContext(r.Context(), "Rotated refresh token presented again, session family revoked", "handler", "RefreshTokens")
+				metrics.LoginAttempt(metrics.LoginRefresh, "reused_token")
+			} else {
+				slog.WarnContext(r.Context(), "Invalid or expired refresh token", "handler", "RefreshTokens")
+				metrics.LoginAttempt(metrics.LoginRefresh, "invalid_token")
+			}
 			http.Error(w, `{"message": "invalid or expired refresh token"}`, http.StatusUnauthorized)
 			return
 		}
-		log.Printf("[RefreshTokens] Database error verifying refresh token: %v", err)
+		slog.ErrorContext(r.Context(), "Database error rotating refresh token", "handler", "RefreshTokens", "err", err)
+		metrics.LoginAttempt(metrics.LoginRefresh, metrics.OutcomeError)
 		http.Error(w, `{"message": "internal server error while validating token"}`, http.StatusInternalServerError)
 		return
 	}
 
-	appUserID, err := uuid.FromBytes(pgDbUserID.Bytes[:])
+	appUserID, err := uuid.FromBytes(rotated.UserID.Bytes[:])
 	if err != nil {
-		log.Printf("[RefreshTokens] Error converting user ID: %v", err)
+		slog.ErrorContext(r.Context(), "Error converting user ID", "handler", "RefreshTokens", "err", err)
 		http.Error(w, `{"message": "internal server error - user ID conversion failed"}`, http.StatusInternalServerError)
 		return
 	}
 
-	newAccessToken, err := s.jwtAuth.GenerateToken(appUserID)
+	newAccessToken, err := s.jwtAuth.GenerateToken(appUserID, rotated.Roles, rotated.SessionID.Bytes)
 	if err != nil {
-		log.Printf("[RefreshTokens] Error generating new access token for user %s: %v", appUserID, err)
+		slog.ErrorContext(r.Context(), "Error generating new access token", "handler", "RefreshTokens", "user_id", appUserID, "err", err)
 		http.Error(w, `{"message": "failed to generate new access token"}`, http.StatusInternalServerError)
 		return
 	}
 
-	newRefreshTokenString, newRefreshTokenExpiresAt, err := s.jwtAuth.GenerateRefreshToken()
-	if err != nil {
-		log.Printf("[RefreshTokens] Error generating new refresh token for user %s: %v", appUserID, err)
-		http.Error(w, `{"message": "failed to generate new refresh token"}`, http.StatusInternalServerError)
-		return
-	}
-
-	updateTokenParams := db.UpdateRefreshTokenParams{
-		RefreshToken: pgtype.Text{String: newRefreshTokenString, Valid: true},
-		ExpiredAt:    pgtype.Timestamp{Time: newRefreshTokenExpiresAt, Valid: true},
-		UserID:       pgDbUserID,
-	}
-
-	err = s.db.UpdateRefreshToken(r.Context(), updateTokenParams)
-	if err != nil {
-		log.Printf("[RefreshTokens] Failed to update new refresh token for user ID %s: %v", appUserID, err)
-		http.Error(w, `{"message": "failed to save new session"}`, http.StatusInternalServerError)
-		return
-	}
+	metrics.LoginAttempt(metrics.LoginRefresh, metrics.OutcomeSuccess)
 
 	response := RefreshResponse{
 		Token:        newAccessToken,
@@ -338,31 +447,40 @@ func (s *Server) RefreshTokens(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("[RefreshTokens] Error encoding success response for user ID %s: %v", appUserID, err)
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "RefreshTokens", "user_id", appUserID, "err", err)
 	}
 
 }
 
 func (s *Server) Logout(w http.ResponseWriter, r *http.Request) {
-	userIDFromToken, ok := r.Context().Value(tools.UserIDContextKey).(string)
-	if !ok || userIDFromToken == "" {
-		log.Println("[Logout] UserID not found in context. Auth middleware might not have run or token is problematic.")
+	userID, sessionID, ok := authFromContext(r)
+	if !ok {
+		slog.WarnContext(r.Context(), "User ID not found in context, auth middleware might not have run or token is problematic", "handler", "Logout")
 		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
 		return
 	}
-
-	parsedUUID, err := uuid.Parse(userIDFromToken)
-	if err != nil {
-		log.Printf("[Logout] UserID '%s' from token is not a valid UUID format: %v", userIDFromToken, err)
-		http.Error(w, `{"message": "Unauthorized: Invalid user identification format in token"}`, http.StatusUnauthorized)
+	if !requireSessionAccess(w, r) {
 		return
 	}
 
-	pgUserIDToLogout := pgtype.UUID{Bytes: parsedUUID, Valid: true}
-
-	err = s.db.LogoutById(r.Context(), pgUserIDToLogout)
+	// Only the calling device is signed out. Tokens issued before sessions
+	// existed carry no session ID; for those every session is revoked.
+	var err error
+	if sessionID.Valid {
+		_, err = s.db.RevokeAuthSession(r.Context(), db.RevokeAuthSessionParams{SessionID: sessionID, UserID: userID})
+		if err == nil {
+			err = s.revokeSessionTokens(r.Context(), sessionID)
+		}
+	} else {
+		err = s.revokeAllSessions(r.Context(), userID)
+	}
+	if err == nil {
+		if tokenID, _ := r.Context().Value(tools.TokenIDContextKey).(string); tokenID != "" {
+			err = s.revocations.RevokeToken(r.Context(), tokenID)
+		}
+	}
 	if err != nil {
-		log.Printf("[Logout] Database error while invalidating refresh token for UserID '%s': %v", userIDFromToken, err)
+		slog.ErrorContext(r.Context(), "Error while revoking session", "handler", "Logout", "user_id", userID, "err", err)
 		http.Error(w, `{"message": "Logout failed due to a server error"}`, http.StatusInternalServerError)
 		return
 	}
@@ -371,8 +489,31 @@ func (s *Server) Logout(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	responseMessage := map[string]string{"message": "Successfully logged out"}
 	if err := json.NewEncoder(w).Encode(responseMessage); err != nil {
-		log.Printf("[Logout] Error encoding success response for UserID '%s': %v", userIDFromToken, err)
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "Logout", "user_id", userID, "err", err)
+	}
+}
+
+// createUserWithCode creates the user and its email verification code in one
+// transaction and returns the code.
+func (s *Server) createUserWithCode(ctx context.Context, params db.CreateUserParams) (string, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	qtx := s.db.WithTx(tx)
+	if err := qtx.CreateUser(ctx, params); err != nil {
+		return "", fmt.Errorf("create user: %w", err)
+	}
+	code, err := s.createCode(ctx, qtx, params.UserID, params.Email, purposeEmailVerification)
+	if err != nil {
+		return "", err
 	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("commit transaction: %w", err)
+	}
+	return code, nil
 }
 
 func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
@@ -380,7 +521,7 @@ func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = r.Body.Close() }()
 	if err != nil {
 		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
-		log.Printf("[Register] Error reading request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "Register", "err", err)
 		return
 	}
 
@@ -389,7 +530,7 @@ func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
-		log.Printf("[Register] Error unmarshalling request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "Register", "err", err)
 		return
 	}
 
@@ -401,33 +542,30 @@ func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
 	_, err = s.db.GetUserAuthDetailsByEmail(r.Context(), registerRequest.Email)
 	if err == nil {
 		http.Error(w, `{"message": "user with this email already exists"}`, http.StatusConflict)
-		log.Printf("[Register] Attempt to register with existing email: %s", registerRequest.Email)
+		slog.WarnContext(r.Context(), "Attempt to register with existing email", "handler", "Register", "email", logging.Email(registerRequest.Email))
+		metrics.RegistrationAttempt("email_taken")
 		return
 	}
 	if err != sql.ErrNoRows && err != pgx.ErrNoRows {
 		http.Error(w, `{"message": "failed to check email availability"}`, http.StatusInternalServerError)
-		log.Printf("[Register] Error checking email availability for %s: %v", registerRequest.Email, err)
+		slog.ErrorContext(r.Context(), "Error checking email availability", "handler", "Register", "email", logging.Email(registerRequest.Email), "err", err)
+		metrics.RegistrationAttempt(metrics.OutcomeError)
 		return
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registerRequest.Password), bcrypt.DefaultCost)
 	if err != nil {
 		http.Error(w, `{"message": "failed to hash password"}`, http.StatusInternalServerError)
-		log.Printf("[Register] Error hashing password: %v", err)
+		slog.ErrorContext(r.Context(), "Error hashing password", "handler", "Register", "err", err)
+		metrics.RegistrationAttempt(metrics.OutcomeError)
 		return
 	}
 
 	userID, err := uuid.NewRandom()
 	if err != nil {
 		http.Error(w, `{"message": "failed to generate user ID"}`, http.StatusInternalServerError)
-		log.Printf("[Register] Error generating userID: %v", err)
-		return
-	}
-
-	confirmationCode, err := generateNumericCode(6)
-	if err != nil {
-		http.Error(w, `{"message": "failed to generate confirmation code"}`, http.StatusInternalServerError)
-		log.Printf("[Register] Error generating confirmation code: %v", err)
+		slog.ErrorContext(r.Context(), "Error generating userID", "handler", "Register", "err", err)
+		metrics.RegistrationAttempt(metrics.OutcomeError)
 		return
 	}
 
@@ -435,24 +573,39 @@ func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
 		UserID:   pgtype.UUID{Bytes: userID, Valid: true},
 		Email:    registerRequest.Email,
 		Password: string(hashedPassword),
-		Code:     pgtype.Text{String: confirmationCode, Valid: true},
 	}
 
-	err = s.db.CreateUser(r.Context(), createUserParams)
+	// The user and its code are created together, so a failure leaves no
+	// account behind that would block registering again. The code is only
+	// sent once both are committed.
+	code, err := s.createUserWithCode(r.Context(), createUserParams)
 	if err != nil {
+		if isUniqueViolation(err) {
+			http.Error(w, `{"message": "user with this email already exists"}`, http.StatusConflict)
+			metrics.RegistrationAttempt("email_taken")
+			return
+		}
 		http.Error(w, `{"message": "failed to register user"}`, http.StatusInternalServerError)
-		log.Printf("[Register] Error creating user in DB: %v", err)
+		slog.ErrorContext(r.Context(), "Error creating user in DB", "handler", "Register", "err", err)
+		metrics.RegistrationAttempt(metrics.OutcomeError)
 		return
 	}
+	metrics.RegistrationAttempt(metrics.OutcomeSuccess)
 
 	response := RegisterResponse{
-		Message: "Registration successful. Please check your email to verify your account. Your confirmation code is: #" + confirmationCode,
+		Message: "Registration successful. Please check your email to verify your account.",
+	}
+	if err := s.sendCode(r, codeTemplates[purposeEmailVerification], registerRequest.Email, code); err != nil {
+		// The account exists now, so the client has to ask for a new code
+		// rather than register again.
+		slog.ErrorContext(r.Context(), "Error sending confirmation email", "handler", "Register", "email", logging.Email(registerRequest.Email), "err", err)
+		response.Message = "Registration successful, but the confirmation email could not be sent. Request a new code with /api/auth/resend-code."
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("[Register] Error encoding success response: %v", err)
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "Register", "err", err)
 	}
 
 }
@@ -468,7 +621,7 @@ func (s *Server) ValidateToken(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("[ValidateToken] Error encoding success response: %v", err)
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ValidateToken", "err", err)
 	}
 }
 
@@ -477,14 +630,14 @@ func (s *Server) RequestPasswordResetCode(w http.ResponseWriter, r *http.Request
 	defer func() { _ = r.Body.Close() }()
 	if err != nil {
 		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
-		log.Printf("[Register] Error reading request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "Register", "err", err)
 		return
 	}
 	var passwordResetCodeRequest *PasswordResetCodeRequest
 	err = json.Unmarshal(bodyBytes, &passwordResetCodeRequest)
 	if err != nil {
 		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
-		log.Printf("[RequestPasswordResetCode] Error unmarshalling request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "RequestPasswordResetCode", "err", err)
 		return
 	}
 
@@ -493,33 +646,20 @@ func (s *Server) RequestPasswordResetCode(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	pgUserID, err := s.db.GetUserByEmail(r.Context(), passwordResetCodeRequest.Email)
+	user, err := s.db.GetUserByEmail(r.Context(), passwordResetCodeRequest.Email)
 	if err != nil {
 		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
-			log.Printf("[RequestPasswordResetCode] User not found for email: %s", passwordResetCodeRequest.Email)
+			slog.WarnContext(r.Context(), "User not found", "handler", "RequestPasswordResetCode", "email", logging.Email(passwordResetCodeRequest.Email))
 			http.Error(w, `{"message": "user not found"}`, http.StatusNotFound)
 			return
 		}
-		log.Printf("[RequestPasswordResetCode] Database error fetching user for email %s: %v", passwordResetCodeRequest.Email, err)
+		slog.ErrorContext(r.Context(), "Database error fetching user", "handler", "RequestPasswordResetCode", "email", logging.Email(passwordResetCodeRequest.Email), "err", err)
 		http.Error(w, `{"message": "internal server error while fetching user data"}`, http.StatusInternalServerError)
 		return
 	}
 
-	resetCode, err := generateNumericCode(6)
-	if err != nil {
-		log.Printf("[RequestPasswordResetCode] Error generating reset code for email %s: %v", passwordResetCodeRequest.Email, err)
-		http.Error(w, `{"message": "failed to generate reset code"}`, http.StatusInternalServerError)
-		return
-	}
-
-	updateCodeParams := db.UpdateCodeByUserIdParams{
-		Code:   pgtype.Text{String: resetCode, Valid: true},
-		UserID: pgUserID,
-	}
-	err = s.db.UpdateCodeByUserId(r.Context(), updateCodeParams)
-	if err != nil {
-		log.Printf("[RequestPasswordResetCode] Failed to update reset code for user ID %s: %v", pgUserID.Bytes, err)
-		http.Error(w, `{"message": "failed to save reset code"}`, http.StatusInternalServerError)
+	if err := s.issueCode(r, user.UserID, passwordResetCodeRequest.Email, purposePasswordReset); err != nil {
+		writeIssueCodeError(w, r, "RequestPasswordResetCode", passwordResetCodeRequest.Email, err)
 		return
 	}
 
@@ -528,11 +668,11 @@ func (s *Server) RequestPasswordResetCode(w http.ResponseWriter, r *http.Request
 
 	response := PasswordResetCodeResponse{
 		Email:   passwordResetCodeRequest.Email,
-		Message: "Password reset code sent to your email. Your code is #" + resetCode,
+		Message: "Password reset code sent to your email.",
 	}
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("[RequestPasswordResetCode] Error encoding success response for email %s: %v", passwordResetCodeRequest.Email, err)
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "RequestPasswordResetCode", "email", logging.Email(passwordResetCodeRequest.Email), "err", err)
 	}
 }
 
@@ -541,7 +681,7 @@ func (s *Server) ResetPasswordWithCode(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = r.Body.Close() }()
 	if err != nil {
 		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
-		log.Printf("[Register] Error reading request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "Register", "err", err)
 		return
 	}
 	var passwordResetWithCodeRequest *PasswordResetWithCodeRequest
@@ -549,7 +689,7 @@ func (s *Server) ResetPasswordWithCode(w http.ResponseWriter, r *http.Request) {
 	err = json.Unmarshal(bodyBytes, &passwordResetWithCodeRequest)
 	if err != nil {
 		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
-		log.Printf("[RequestPasswordResetCode] Error unmarshalling request body: %v", err)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "RequestPasswordResetCode", "err", err)
 		return
 	}
 
@@ -558,64 +698,52 @@ func (s *Server) ResetPasswordWithCode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pgUserID, err := s.db.GetUserByEmail(r.Context(), passwordResetWithCodeRequest.Email)
+	user, err := s.db.GetUserByEmail(r.Context(), passwordResetWithCodeRequest.Email)
 	if err != nil {
 		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
-			log.Printf("[ResetPasswordWithCode] User not found for email: %s", passwordResetWithCodeRequest.Email)
+			slog.WarnContext(r.Context(), "User not found", "handler", "ResetPasswordWithCode", "email", logging.Email(passwordResetWithCodeRequest.Email))
 			http.Error(w, `{"message": "user not found"}`, http.StatusNotFound)
 			return
 		}
-		log.Printf("[ResetPasswordWithCode] Database error fetching user for email %s: %v", passwordResetWithCodeRequest.Email, err)
+		slog.ErrorContext(r.Context(), "Database error fetching user", "handler", "ResetPasswordWithCode", "email", logging.Email(passwordResetWithCodeRequest.Email), "err", err)
 		http.Error(w, `{"message": "internal server error while fetching user data"}`, http.StatusInternalServerError)
 		return
 	}
 
-	hashedNewPassword, err := bcrypt.GenerateFromPassword([]byte(passwordResetWithCodeRequest.NewPassword), bcrypt.DefaultCost)
-	if err != nil {
-		http.Error(w, `{"message": "failed to hash new password"}`, http.StatusInternalServerError)
-		log.Printf("[ResetPasswordWithCode] Error hashing new password for email %s: %v", passwordResetWithCodeRequest.Email, err)
+	if _, remaining, err := s.checkCode(r.Context(), user.UserID, purposePasswordReset, passwordResetWithCodeRequest.Code); err != nil {
+		slog.WarnContext(r.Context(), "Code rejected", "handler", "ResetPasswordWithCode", "email", logging.Email(passwordResetWithCodeRequest.Email), "err", err)
+		writeCodeError(w, r, "ResetPasswordWithCode", remaining, err)
 		return
 	}
 
-	refreshTokenString, refreshTokenExpiresAt, err := s.jwtAuth.GenerateRefreshToken()
+	hashedNewPassword, err := bcrypt.GenerateFromPassword([]byte(passwordResetWithCodeRequest.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
-		http.Error(w, `{"message": "failed to generate refresh token"}`, http.StatusInternalServerError)
-		log.Printf("[ResetPasswordWithCode] Error generating refresh token for user %s: %v", passwordResetWithCodeRequest.Email, err)
+		http.Error(w, `{"message": "failed to hash new password"}`, http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Error hashing new password", "handler", "ResetPasswordWithCode", "email", logging.Email(passwordResetWithCodeRequest.Email), "err", err)
 		return
 	}
 
-	resetPasswordParams := db.ResetPasswordWithCodeAndSetTokensParams{
-		UserID:       pgUserID,
-		Code:         pgtype.Text{String: passwordResetWithCodeRequest.Code, Valid: true},
-		RefreshToken: pgtype.Text{String: refreshTokenString, Valid: true},
-		ExpiredAt:    pgtype.Timestamp{Time: refreshTokenExpiresAt, Valid: true},
-		Email:        passwordResetWithCodeRequest.Email,
-		Password:     string(hashedNewPassword),
-	}
-
-	_, err = s.db.ResetPasswordWithCodeAndSetTokens(r.Context(), resetPasswordParams)
+	resetUser, err := s.db.ResetPassword(r.Context(), db.ResetPasswordParams{
+		UserID:   user.UserID,
+		Password: string(hashedNewPassword),
+	})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
-			log.Printf("[ResetPasswordWithCode] Failed to update password: invalid code or email. Email: %s, Code: %s", passwordResetWithCodeRequest.Email, passwordResetWithCodeRequest.Code)
-			http.Error(w, `{"message": "Invalid request: code is incorrect or already used"}`, http.StatusBadRequest)
-			return
-		}
-		log.Printf("[ResetPasswordWithCode] Database error resetting password for email %s: %v", passwordResetWithCodeRequest.Email, err)
+		slog.ErrorContext(r.Context(), "Database error resetting password", "handler", "ResetPasswordWithCode", "email", logging.Email(passwordResetWithCodeRequest.Email), "err", err)
 		http.Error(w, `{"message": "failed to reset password"}`, http.StatusInternalServerError)
 		return
 	}
 
-	appUserID, err := uuid.FromBytes(pgUserID.Bytes[:])
-	if err != nil {
-		log.Printf("[ResetPasswordWithCode] Error converting user ID: %v", err)
-		http.Error(w, `{"message": "internal server error - user ID conversion failed"}`, http.StatusInternalServerError)
+	// Whoever knew the old password must not stay signed in, not even until
+	// their access token expires.
+	if err := s.revokeAllSessions(r.Context(), resetUser.UserID); err != nil {
+		slog.ErrorContext(r.Context(), "Database error revoking sessions", "handler", "ResetPasswordWithCode", "email", logging.Email(passwordResetWithCodeRequest.Email), "err", err)
+		http.Error(w, `{"message": "failed to reset password"}`, http.StatusInternalServerError)
 		return
 	}
 
-	accessToken, err := s.jwtAuth.GenerateToken(appUserID)
+	accessToken, refreshTokenString, err := s.startSession(r, resetUser.UserID, resetUser.Roles, passwordResetWithCodeRequest.DeviceName)
 	if err != nil {
-		http.Error(w, `{"message": "failed to generate access token"}`, http.StatusInternalServerError)
-		log.Printf("[ResetPasswordWithCode] Error generating access token for user %s: %v", passwordResetWithCodeRequest.Email, err)
+		writeStartSessionError(w, r, "ResetPasswordWithCode", passwordResetWithCodeRequest.Email, err)
 		return
 	}
 
@@ -627,8 +755,18 @@ func (s *Server) ResetPasswordWithCode(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("[ResetPasswordWithCode] Error encoding success response for email %s: %v", passwordResetWithCodeRequest.Email, err)
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ResetPasswordWithCode", "email", logging.Email(passwordResetWithCodeRequest.Email), "err", err)
+	}
+}
+
+// sendCode mails a one-time code using the template in the language the client accepts.
+func (s *Server) sendCode(r *http.Request, template, email, code string) error {
+	lang := mail.LanguageFromHeader(r.Header.Get("Accept-Language"))
+	msg, err := mail.Render(template, lang, email, mail.CodeData{Code: code})
+	if err != nil {
+		return err
 	}
+	return s.mailer.Send(r.Context(), msg)
 }
 
 func generateNumericCode(length int) (string, error) {
@@ -691,14 +829,17 @@ func validateChatHistory(history []*genai.Content) []*genai.Content {
 	return validHistory
 }
 
-// buildSystemPrompt creates the system prompt string with dynamic lat/lon.
-func buildSystemPrompt(lat, lon float64, pc *PharmacyContext) string {
+// buildSystemPrompt creates the system prompt string with dynamic lat/lon,
+// the user's saved preferences and the names of their saved places.
+func buildSystemPrompt(lat, lon float64, pc *PharmacyContext, profile db.UserProfile, places []SavedPlace) string {
 	promptText := `──────────────── 1. Когда вызывать инструмент ────────────────
 
 find_nearest_pharmacy
 
 • фразы «ближайшая / рядом / поблизости / возле меня / к моему местоположению»
 
+• «возле дома / рядом с работой» — с аргументом place (см. «Сохранённые места»)
+
 • в запросе НЕТ других параметров
 find_pharmacies
 
@@ -707,9 +848,14 @@ find_pharmacies
 ИЛИ указан ≥ 1 параметр (название, номер, город, улица, дом, телефон)
 
 • вызывай даже по одному параметру
+my_pharmacies
+
+• «моя аптека / мои аптеки / позвони в мою аптеку / моя аптека открыта?»
+
+• сохранённые аптеки пользователя; НЕ вызывай для них find_pharmacies
 return_transcription
 
-• если НЕ был вызван find_nearest_pharmacy И НЕ был вызван find_pharmacies
+• если НЕ был вызван find_nearest_pharmacy, find_pharmacies И my_pharmacies
 
 • во всех остальных случаях, когда не подходит ни один из вышеуказанных инструментов
 
@@ -793,16 +939,23 @@ find_nearest_pharmacy →
 дом:       %s
 `, pc.Name, pc.Number, pc.City, pc.Street, pc.House)
 	}
-	return promptText + m
+	return promptText + m + profilePrompt(profile) + savedPlacesPrompt(places)
 }
 
-func (s *Server) getOrCreateSession(sessionID string) *ChatSession {
+// chatSessionKey scopes chat session IDs to their owner, so one user cannot
+// continue another user's conversation by guessing its ID.
+func chatSessionKey(userID, sessionID string) string {
+	return userID + "/" + sessionID
+}
+
+func (s *Server) getOrCreateSession(userID, sessionID string) *ChatSession {
 	s.sessionMutex.Lock()
 	defer s.sessionMutex.Unlock()
 
-	if session, ok := s.chatSessions[sessionID]; ok {
+	key := chatSessionKey(userID, sessionID)
+	if session, ok := s.chatSessions[key]; ok {
 		session.UpdatedAt = time.Now()
-		if time.Since(session.CreatedAt) > 15*time.Minute {
+		if time.Since(session.CreatedAt) > s.chat.historyTTL {
 			session.History = nil
 			session.CreatedAt = time.Now()
 		}
@@ -811,29 +964,65 @@ func (s *Server) getOrCreateSession(sessionID string) *ChatSession {
 
 	session := &ChatSession{
 		ID:              sessionID,
+		UserID:          userID,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 		CurrentPharmacy: nil,
 	}
-	s.chatSessions[sessionID] = session
+	s.chatSessions[key] = session
 	return session
 }
 
+// deleteChatSessions drops every chat session owned by userID.
+func (s *Server) deleteChatSessions(userID string) {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+	for key, session := range s.chatSessions {
+		if session.UserID == userID {
+			delete(s.chatSessions, key)
+		}
+	}
+}
+
 // Clean up old sessions periodically
-func (s *Server) cleanupSessions() {
-	ticker := time.NewTicker(30 * time.Minute)
-	go func() {
-		for range ticker.C {
-			s.sessionMutex.Lock()
-			now := time.Now()
-			for id, session := range s.chatSessions {
-				if now.Sub(session.UpdatedAt) > 1*time.Hour {
-					delete(s.chatSessions, id)
-				}
+func (s *Server) cleanupSessions(ctx context.Context) {
+	ticker := time.NewTicker(s.chat.cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.sessionMutex.Lock()
+		now := time.Now()
+		for id, session := range s.chatSessions {
+			if now.Sub(session.UpdatedAt) > s.chat.sessionTTL {
+				delete(s.chatSessions, id)
 			}
-			s.sessionMutex.Unlock()
 		}
-	}()
+		s.sessionMutex.Unlock()
+		s.cleanupExports()
+		s.chatQuota.prune(now)
+		s.apiKeys.Prune(now)
+
+		if n, err := s.db.DeleteStaleVerificationCodes(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error deleting stale verification codes", "err", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "Deleted stale verification codes", "count", n)
+		}
+		if n, err := s.db.DeleteStaleAuthSessions(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error deleting stale auth sessions", "err", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "Deleted stale auth sessions", "count", n)
+		}
+		if n, err := s.db.DeleteInactiveGuests(ctx); err != nil {
+			slog.ErrorContext(ctx, "Error deleting inactive guests", "err", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "Deleted inactive guests", "count", n)
+		}
+	}
 }
 
 // Add this struct to track pharmacy context
@@ -951,6 +1140,9 @@ func (s *Server) pharmacyExists(ctx context.Context, h pharmacyHint) (bool, erro
 
 // high-level helper
 func (s *Server) validateAssistantAnswer(ctx context.Context, txt string) bool {
+	ctx, span := tracer.Start(ctx, "chat.validate_answer")
+	defer span.End()
+
 	hints := extractPharmacyHints(txt)
 	if len(hints) == 0 { // в ответе вообще нет данных об аптеке
 		return true
@@ -965,13 +1157,65 @@ func (s *Server) validateAssistantAnswer(ctx context.Context, txt string) bool {
 	return true
 }
 
+// sendMessage sends one message of a chat round, giving the LLM at most
+// llmTimeout to answer, and records how long it took.
+func (s *Server) sendMessage(ctx context.Context, chat *genai.Chat, round string, part genai.Part) (*genai.GenerateContentResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.chat.llmTimeout)
+	defer cancel()
+	ctx, span := tracer.Start(ctx, "chat.llm",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("chat.round", round)),
+	)
+	start := time.Now()
+	resp, err := chat.SendMessage(ctx, part)
+	metrics.ObserveLLMCall(round, time.Since(start), err)
+	tracing.End(span, err)
+	return resp, err
+}
+
+// queryChroma runs one pharmacy search; query is "primary" or "fallback".
+func queryChroma(ctx context.Context, collection chromago.Collection, query string, opts ...chromago.CollectionQueryOption) (chromago.QueryResult, error) {
+	ctx, span := tracer.Start(ctx, "chroma.query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("chroma.query", query)),
+	)
+	retrieved, err := collection.Query(ctx, opts...)
+	metrics.ObserveChromaQuery(query, err)
+	tracing.End(span, err)
+	return retrieved, err
+}
+
+// selectTool records the tool the LLM chose on the metrics and the request span.
+func selectTool(ctx context.Context, tool string) {
+	metrics.ToolSelected(tool)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("chat.tool", tool))
+}
+
 // ---------------------------------------
 
 func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	if err := r.ParseMultipartForm(32 << 20); err != nil {
-		log.Printf("[Chat] multipart parse error: %v", err)
+	userID, _ := ctx.Value(tools.UserIDContextKey).(string)
+	// API keys are rate limited per key by the authenticator instead.
+	if !tools.IsAPIKeyRequest(r) {
+		if retryAfter, ok := s.chatQuota.take(userID, isGuest(r), time.Now()); !ok {
+			slog.WarnContext(ctx, "Chat quota used up", "handler", "Chat", "user_id", userID)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, `{"message":"chat quota used up, try again later"}`, http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.chat.maxUploadBytes)
+	if err := r.ParseMultipartForm(s.chat.maxUploadBytes); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			slog.WarnContext(ctx, "Upload too large", "handler", "Chat", "limit", tooLarge.Limit)
+			http.Error(w, `{"message":"audio upload too large"}`, http.StatusBadRequest)
+			return
+		}
+		slog.ErrorContext(ctx, "Multipart parse error", "handler", "Chat", "err", err)
 		http.Error(w, `{"message":"invalid multipart form"}`, http.StatusBadRequest)
 		return
 	}
@@ -986,7 +1230,7 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 		userLat, errLat = strconv.ParseFloat(latStr, 64)
 		userLon, errLon = strconv.ParseFloat(lonStr, 64)
 		if errLat != nil || errLon != nil {
-			log.Printf("[Chat] bad lat/lon: %s %s", latStr, lonStr)
+			slog.WarnContext(ctx, "Bad latitude or longitude", "handler", "Chat")
 			http.Error(w, `{"message":"invalid latitude or longitude"}`, http.StatusBadRequest)
 			return
 		}
@@ -997,12 +1241,31 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 	if sessionID == "" {
 		sessionID = generateSessionID()
 	}
-	session := s.getOrCreateSession(sessionID)
+	session := s.getOrCreateSession(userID, sessionID)
+
+	// Preferences and saved places are best effort: a failed lookup must not
+	// block the chat.
+	var (
+		profile db.UserProfile
+		places  []SavedPlace
+	)
+	accountID, _, hasAccount := authFromContext(r)
+	if hasAccount {
+		userCtx, span := tracer.Start(ctx, "chat.load_user_context")
+		var profileErr, placesErr error
+		if profile, profileErr = s.loadUserProfile(userCtx, accountID); profileErr != nil {
+			slog.ErrorContext(ctx, "Error loading profile", "handler", "Chat", "user_id", userID, "err", profileErr)
+		}
+		if places, placesErr = s.savedPlaces(userCtx, accountID); placesErr != nil {
+			slog.ErrorContext(ctx, "Error loading saved places", "handler", "Chat", "user_id", userID, "err", placesErr)
+		}
+		tracing.End(span, errors.Join(profileErr, placesErr))
+	}
 
 	// --------------- 3. AUDIO FILE ----------------------
 	audioFile, fileHeader, err := r.FormFile("audio")
 	if err != nil {
-		log.Printf("[Chat] audio file error: %v", err)
+		slog.ErrorContext(ctx, "Audio file error", "handler", "Chat", "err", err)
 		http.Error(w, `{"message":"audio file is required"}`, http.StatusBadRequest)
 		return
 	}
@@ -1010,7 +1273,7 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 
 	audioBytes, err := io.ReadAll(audioFile)
 	if err != nil {
-		log.Printf("[Chat] audio read error: %v", err)
+		slog.ErrorContext(ctx, "Audio read error", "handler", "Chat", "err", err)
 		http.Error(w, `{"message":"failed to read audio"}`, http.StatusInternalServerError)
 		return
 	}
@@ -1040,15 +1303,16 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 	findNearestTool := &genai.Tool{
 		FunctionDeclarations: []*genai.FunctionDeclaration{{
 			Name:        "find_nearest_pharmacy",
-			Description: "Returns the three closest pharmacies to the user's coordinates.",
+			Description: "Returns the three closest pharmacies to the user's coordinates, or to one of the user's saved places.",
 			Parameters: &genai.Schema{
 				Type: genai.TypeObject,
 				Properties: map[string]*genai.Schema{
 					"user_query_transcription": {Type: genai.TypeString, Description: "The full transcribed text of the user's audio query. This field is mandatory."},
-					"latitude":                 {Type: genai.TypeNumber, Description: "Latitude of the user's location. Mandatory."},
-					"longitude":                {Type: genai.TypeNumber, Description: "Longitude of the user's location. Mandatory."},
+					"latitude":                 {Type: genai.TypeNumber, Description: "Latitude of the user's location."},
+					"longitude":                {Type: genai.TypeNumber, Description: "Longitude of the user's location."},
+					"place":                    {Type: genai.TypeString, Description: "Name of a saved place (e.g. \"дом\", \"работа\") as listed in the system instruction, when the user asks near it instead of near their current location. Optional."},
 				},
-				Required: []string{"user_query_transcription", "latitude", "longitude"},
+				Required: []string{"user_query_transcription"},
 			},
 		}},
 	}
@@ -1067,29 +1331,43 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 		}},
 	}
 
+	myPharmaciesTool := &genai.Tool{
+		FunctionDeclarations: []*genai.FunctionDeclaration{{
+			Name:        "my_pharmacies",
+			Description: "Returns the pharmacies the user saved as favorites. Use it when the user says \"my pharmacy\" (моя аптека, мои аптеки) instead of searching.",
+			Parameters: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"user_query_transcription": {Type: genai.TypeString, Description: "The full transcribed text of the user's audio query. This field is mandatory."},
+				},
+				Required: []string{"user_query_transcription"},
+			},
+		}},
+	}
+
 	chatConfig := &genai.GenerateContentConfig{
-		Tools: []*genai.Tool{findPharmacyTool, findNearestTool, returnTranscriptionTool},
+		Tools: []*genai.Tool{findPharmacyTool, findNearestTool, myPharmaciesTool, returnTranscriptionTool},
 		ToolConfig: &genai.ToolConfig{
 			FunctionCallingConfig: &genai.FunctionCallingConfig{
 				Mode: genai.FunctionCallingConfigModeAuto,
 			},
 		},
-		SystemInstruction: &genai.Content{Parts: []*genai.Part{{Text: buildSystemPrompt(userLat, userLon, session.CurrentPharmacy)}}},
+		SystemInstruction: &genai.Content{Parts: []*genai.Part{{Text: buildSystemPrompt(userLat, userLon, session.CurrentPharmacy, profile, places)}}},
 	}
 
 	// --------------- 6. HISTORY MANAGEMENT --------------
 	chatSession, err := s.genaiClient.Chats.Create(ctx, s.chatModel, chatConfig, validateChatHistory(session.History))
 
 	if err != nil {
-		log.Printf("[Chat] LLM session error: %v", err)
+		slog.ErrorContext(ctx, "LLM session error", "handler", "Chat", "err", err)
 		http.Error(w, `{"message":"failed to init AI chat"}`, http.StatusInternalServerError)
 		return
 	}
 
-	log.Println("[Chat] LLM round 1…")
-	resp1, err := chatSession.SendMessage(ctx, audioDataPart)
+	slog.InfoContext(ctx, "LLM round 1", "handler", "Chat")
+	resp1, err := s.sendMessage(ctx, chatSession, "1", audioDataPart)
 	if err != nil {
-		log.Printf("[Chat] LLM round-1 error: %v", err)
+		slog.ErrorContext(ctx, "LLM round 1 error", "handler", "Chat", "err", err)
 		http.Error(w, `{"message":"audio processing failed"}`, http.StatusInternalServerError)
 		return
 	}
@@ -1117,7 +1395,8 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 	switch {
 	// ------- find_pharmacies ---------------------------
 	case functionCallToExecute != nil && functionCallToExecute.Name == "find_pharmacies":
-		log.Println("[Chat] LLM round 1 - tool: find_pharmacies")
+		slog.InfoContext(ctx, "LLM round 1 chose a tool", "handler", "Chat", "tool", "find_pharmacies")
+		selectTool(ctx, "find_pharmacies")
 		args := functionCallToExecute.Args
 		if t, ok := args["user_query_transcription"].(string); ok {
 			userQuery = t
@@ -1152,9 +1431,11 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// ---------- Chroma vector search (same as before) ----------
-		collection, err := s.chromaDBClient.GetCollection(ctx, s.chromaCollectionName, chromago.WithEmbeddingFunctionGet(s.ef))
+		collectionCtx, span := tracer.Start(ctx, "chroma.get_collection", trace.WithSpanKind(trace.SpanKindClient))
+		collection, err := s.chromaDBClient.GetCollection(collectionCtx, s.chromaCollectionName, chromago.WithEmbeddingFunctionGet(s.ef))
+		tracing.End(span, err)
 		if err != nil {
-			log.Printf("[Chat] Chroma collection error: %v", err)
+			slog.ErrorContext(ctx, "Chroma collection error", "handler", "Chat", "err", err)
 			http.Error(w, `{"message":"pharmacy DB access failed"}`, http.StatusInternalServerError)
 			return
 		}
@@ -1194,11 +1475,11 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 			strictClauses = append(strictClauses, chromago.EqString("pharmacy_number", ep.PharmacyNumber))
 		}
 
-		log.Printf("[Chat] RAG Context for LLM (call 1): %s", queryTextBuilder.String())
+		slog.DebugContext(ctx, "RAG context for LLM call 1", "handler", "Chat", "context", logging.Text(queryTextBuilder.String()))
 
 		queryOpts := []chromago.CollectionQueryOption{
 			chromago.WithQueryTexts(strings.TrimSpace(queryTextBuilder.String())),
-			chromago.WithNResults(10),
+			chromago.WithNResults(s.chat.searchResults),
 		}
 		if len(strictClauses) > 0 {
 			filter := strictClauses[0]
@@ -1209,9 +1490,9 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 		}
 		queryOpts = append(queryOpts, chromago.WithIncludeQuery(chromago.IncludeDocuments, chromago.IncludeMetadatas))
 
-		retrieved, err := collection.Query(ctx, queryOpts...)
+		retrieved, err := queryChroma(ctx, collection, "primary", queryOpts...)
 		if err != nil {
-			log.Printf("[Chat] Chroma query error: %v", err)
+			slog.ErrorContext(ctx, "Chroma query error", "handler", "Chat", "err", err)
 			http.Error(w, `{"message":"pharmacy query failed"}`, http.StatusInternalServerError)
 			return
 		}
@@ -1237,15 +1518,15 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 
 			fallbackOpts := []chromago.CollectionQueryOption{
 				chromago.WithQueryTexts(strings.TrimSpace(queryTextBuilder.String())),
-				chromago.WithNResults(10),
+				chromago.WithNResults(s.chat.searchResults),
 				chromago.WithIncludeQuery(chromago.IncludeDocuments, chromago.IncludeMetadatas),
 			}
 			if len(orClauses) > 0 {
 				fallbackOpts = append(fallbackOpts, chromago.WithWhereQuery(chromago.Or(orClauses...)))
 			}
-			retrieved, err = collection.Query(ctx, fallbackOpts...)
+			retrieved, err = queryChroma(ctx, collection, "fallback", fallbackOpts...)
 			if err != nil {
-				log.Printf("[Chat] Chroma fallback error: %v", err)
+				slog.ErrorContext(ctx, "Chroma fallback error", "handler", "Chat", "err", err)
 				http.Error(w, `{"message":"pharmacy query fallback failed"}`, http.StatusInternalServerError)
 				return
 			}
@@ -1261,23 +1542,23 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 				ok := true
 				if ep.PharmacyName != "" {
 					val, _ := meta.GetString("pharmacy_name")
-					ok = ok && fuzzyEqual(val, ep.PharmacyName, 4)
+					ok = ok && fuzzyEqual(val, ep.PharmacyName, s.chat.nameDistance)
 				}
 				if ok && ep.PharmacyNumber != "" {
 					val, _ := meta.GetString("pharmacy_number")
-					ok = ok && fuzzyEqual(val, ep.PharmacyNumber, 1)
+					ok = ok && fuzzyEqual(val, ep.PharmacyNumber, s.chat.numberDistance)
 				}
 				if ok && ep.City != "" {
 					val, _ := meta.GetString("city")
-					ok = ok && fuzzyEqual(val, ep.City, 2)
+					ok = ok && fuzzyEqual(val, ep.City, s.chat.cityDistance)
 				}
 				if ok && ep.Street != "" {
 					val, _ := meta.GetString("street")
-					ok = ok && fuzzyEqual(val, ep.Street, 2)
+					ok = ok && fuzzyEqual(val, ep.Street, s.chat.streetDistance)
 				}
 				if ok && ep.HouseNumber != "" {
 					val, _ := meta.GetString("house_number")
-					ok = ok && fuzzyEqual(val, ep.HouseNumber, 1)
+					ok = ok && fuzzyEqual(val, ep.HouseNumber, s.chat.houseDistance)
 				}
 				if ok {
 					finalDocs = append(finalDocs, docs[di])
@@ -1292,7 +1573,7 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 			rag.WriteString("Найденная информация:\n")
 			for i, d := range finalDocs {
 				rag.WriteString(fmt.Sprintf("%d. %s\n", i+1, d.ContentString()))
-				if i == 4 {
+				if i == s.chat.contextResults-1 {
 					break
 				}
 			}
@@ -1300,7 +1581,7 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 
 		resolved = len(finalDocs) == 1
 
-		log.Printf("[Chat] RAG Context for LLM (call 2): %s", rag.String())
+		slog.DebugContext(ctx, "RAG context for LLM call 2", "handler", "Chat", "context", logging.Text(rag.String()))
 
 		fnResp := genai.FunctionResponse{
 			Name:     "find_pharmacies",
@@ -1308,10 +1589,10 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 		}
 		toolPart := genai.Part{FunctionResponse: &fnResp}
 
-		log.Println("[Chat] LLM round 2 (find_pharmacies)…")
-		resp2, err := chatSession.SendMessage(ctx, toolPart)
+		slog.InfoContext(ctx, "LLM round 2", "handler", "Chat", "tool", "find_pharmacies")
+		resp2, err := s.sendMessage(ctx, chatSession, "2", toolPart)
 		if err != nil {
-			log.Printf("[Chat] LLM round-2 error: %v", err)
+			slog.ErrorContext(ctx, "LLM round 2 error", "handler", "Chat", "err", err)
 			http.Error(w, `{"message":"final answer failed"}`, http.StatusInternalServerError)
 			return
 		}
@@ -1319,21 +1600,36 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 
 		// ------- find_nearest_pharmacy ---------------------
 	case functionCallToExecute != nil && functionCallToExecute.Name == "find_nearest_pharmacy":
-		log.Println("[Chat] LLM round 1 - tool: find_nearest_pharmacy")
+		slog.InfoContext(ctx, "LLM round 1 chose a tool", "handler", "Chat", "tool", "find_nearest_pharmacy")
+		selectTool(ctx, "find_nearest_pharmacy")
+
+		// A saved place replaces the phone's coordinates as the search origin.
+		originLat, originLon := userLat, userLon
+		summaryHeader := "Ближайшие аптеки(в своём ответе пиши каждую с новой строки):\n"
+		if placeName, _ := functionCallToExecute.Args["place"].(string); strings.TrimSpace(placeName) != "" {
+			place, found := resolveSavedPlace(places, placeName)
+			if !found {
+				assistantResponseText = fmt.Sprintf("Место «%s» не сохранено. Добавьте его в приложении, чтобы искать аптеки рядом с ним.", strings.TrimSpace(placeName))
+				break
+			}
+			slog.InfoContext(ctx, "Nearest search around saved place", "handler", "Chat")
+			originLat, originLon = place.Latitude, place.Longitude
+			summaryHeader = "Ближайшие аптеки к месту «" + place.Name + "»(в своём ответе пиши каждую с новой строки):\n"
+		}
 
-		if userLat == 0 && userLon == 0 {
+		if originLat == 0 && originLon == 0 {
 			assistantResponseText = "Координаты не переданы. Невозможно найти ближайшую аптеку."
 			break
 		}
 
 		getNearestPharmacy := &db.GetNearestPharmacyParams{
-			StMakepoint:   userLon,
-			StMakepoint_2: userLat,
+			StMakepoint:   originLon,
+			StMakepoint_2: originLat,
 		}
 
 		nearestList, err := s.db.GetNearestPharmacy(ctx, *getNearestPharmacy)
 		if err != nil {
-			log.Printf("[Chat] nearest query error: %v", err)
+			slog.ErrorContext(ctx, "Nearest query error", "handler", "Chat", "err", err)
 			http.Error(w, `{"message":"nearest pharmacy search failed"}`, http.StatusInternalServerError)
 			return
 		}
@@ -1343,7 +1639,7 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 		}
 
 		var summary strings.Builder
-		summary.WriteString("Ближайшие аптеки(в своём ответе пиши каждую с новой строки):\n")
+		summary.WriteString(summaryHeader)
 		for i, p := range nearestList {
 			if i > 0 {
 				summary.WriteString("\n")
@@ -1361,19 +1657,56 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 		}
 		toolPart := genai.Part{FunctionResponse: &fnResp}
 
-		log.Println("[Chat] LLM round 2 (nearest)…")
-		resp2, err := chatSession.SendMessage(ctx, toolPart)
+		slog.InfoContext(ctx, "LLM round 2", "handler", "Chat", "tool", "find_nearest_pharmacy")
+		resp2, err := s.sendMessage(ctx, chatSession, "2", toolPart)
 		if err != nil {
-			log.Printf("[Chat] LLM round-2 nearest error: %v", err)
+			slog.ErrorContext(ctx, "LLM round 2 error", "handler", "Chat", "tool", "find_nearest_pharmacy", "err", err)
 			http.Error(w, `{"message":"final nearest answer failed"}`, http.StatusInternalServerError)
 			return
 		}
 		assistantResponseText = resp2.Text()
 		resolved = true
 
+	// ------- my_pharmacies ------------------------------
+	case functionCallToExecute != nil && functionCallToExecute.Name == "my_pharmacies":
+		slog.InfoContext(ctx, "LLM round 1 chose a tool", "handler", "Chat", "tool", "my_pharmacies")
+		selectTool(ctx, "my_pharmacies")
+		if t, ok := functionCallToExecute.Args["user_query_transcription"].(string); ok {
+			userQuery = t
+		}
+		if !hasAccount {
+			assistantResponseText = "Войдите в аккаунт, чтобы пользоваться сохранёнными аптеками."
+			break
+		}
+
+		summary, count, err := s.favoritesSummary(ctx, accountID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Favorites query error", "handler", "Chat", "err", err)
+			http.Error(w, `{"message":"favorite pharmacies lookup failed"}`, http.StatusInternalServerError)
+			return
+		}
+
+		fnResp := genai.FunctionResponse{
+			Name:     "my_pharmacies",
+			Response: map[string]any{"search_results_summary": summary},
+		}
+		toolPart := genai.Part{FunctionResponse: &fnResp}
+
+		slog.InfoContext(ctx, "LLM round 2", "handler", "Chat", "tool", "my_pharmacies")
+		resp2, err := s.sendMessage(ctx, chatSession, "2", toolPart)
+		if err != nil {
+			slog.ErrorContext(ctx, "LLM round 2 error", "handler", "Chat", "tool", "my_pharmacies", "err", err)
+			http.Error(w, `{"message":"final answer failed"}`, http.StatusInternalServerError)
+			return
+		}
+		assistantResponseText = resp2.Text()
+		// With several favorites the model may ask which one is meant.
+		resolved = count <= 1
+
 	// ------- return_transcription -----------------------
 	case functionCallToExecute != nil && functionCallToExecute.Name == "return_transcription":
-		log.Println("[Chat] LLM round 1 - tool: return_transcription")
+		slog.InfoContext(ctx, "LLM round 1 chose a tool", "handler", "Chat", "tool", "return_transcription")
+		selectTool(ctx, "return_transcription")
 		args := functionCallToExecute.Args
 		if t, ok := args["user_query_transcription"].(string); ok {
 			userQuery = t
@@ -1382,7 +1715,8 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 
 	// ------- no tool -----------------------------------
 	default:
-		log.Println("[Chat] LLM round 1 - no tool")
+		slog.InfoContext(ctx, "LLM round 1 chose no tool", "handler", "Chat")
+		selectTool(ctx, "none")
 		resolved = true
 		assistantResponseText = resp1.Text()
 	}
@@ -1395,7 +1729,8 @@ func (s *Server) Chat(w http.ResponseWriter, r *http.Request) {
 	assistantResponseText = re.ReplaceAllString(assistantResponseText, "")
 
 	if ok := s.validateAssistantAnswer(ctx, assistantResponseText); !ok {
-		log.Print("[Chat] validation failed – pharmacy not found in DB")
+		slog.WarnContext(ctx, "Validation failed, pharmacy not found in DB", "handler", "Chat")
+		metrics.AnswerRejected()
 
 		assistantResponseText = "Извините, я не смог подтвердить информацию об аптеке. " +
 			"Повторите, пожалуйста, свой вопрос."
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+func (s *Server) GetJwks(w http.ResponseWriter, r *http.Request) {
+	keys := s.jwtAuth.JWKS()
+	response := JsonWebKeySet{Keys: make([]JsonWebKey, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, JsonWebKey{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Use: key.Use,
+			Alg: key.Alg,
+			N:   optional(key.N),
+			E:   optional(key.E),
+			Crv: optional(key.Crv),
+			X:   optional(key.X),
+		})
+	}
+
+	// Verifiers may cache the set for a few minutes, so a new key should be
+	// listed in JWT_VERIFICATION_KEY_FILES for a while before it signs tokens.
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "GetJwks", "err", err)
+	}
+}
+
+// optional returns nil for an empty string, so omitempty drops the field.
+func optional(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	db "voice_assistant/db/sqlc"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxSavedPlaces caps how many places a user can save; their names are
+// listed in every system prompt.
+const maxSavedPlaces = 10
+
+func savedPlaceResponse(p db.ListSavedPlacesRow) SavedPlace {
+	return SavedPlace{
+		Id:        p.PlaceID.Bytes,
+		Name:      p.Name,
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+		CreatedAt: p.CreatedAt.Time,
+	}
+}
+
+func (s *Server) savedPlaces(ctx context.Context, userID pgtype.UUID) ([]SavedPlace, error) {
+	rows, err := s.db.ListSavedPlaces(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	places := make([]SavedPlace, 0, len(rows))
+	for _, row := range rows {
+		places = append(places, savedPlaceResponse(row))
+	}
+	return places, nil
+}
+
+// resolveSavedPlace finds the place the model named. The model is asked for
+// the saved name, but may pass an inflected form ("работы"), so a close
+// unambiguous match is accepted too.
+func resolveSavedPlace(places []SavedPlace, name string) (SavedPlace, bool) {
+	for _, p := range places {
+		if normalize(p.Name) == normalize(name) {
+			return p, true
+		}
+	}
+	var match *SavedPlace
+	for i, p := range places {
+		if fuzzyEqual(p.Name, name, 2) {
+			if match != nil {
+				return SavedPlace{}, false
+			}
+			match = &places[i]
+		}
+	}
+	if match == nil {
+		return SavedPlace{}, false
+	}
+	return *match, true
+}
+
+// savedPlacesPrompt tells the model which place names it can pass to
+// find_nearest_pharmacy.
+func savedPlacesPrompt(places []SavedPlace) string {
+	if len(places) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(places))
+	for _, p := range places {
+		names = append(names, "«"+p.Name+"»")
+	}
+	return `
+────────────── Сохранённые места ──────────────
+` + strings.Join(names, ", ") + `
+Для запросов «аптека возле дома / рядом с работой» вызывай find_nearest_pharmacy и передай в place название места так, как оно сохранено.
+`
+}
+
+// readSavedPlaceRequest decodes and checks the body of a create or update.
+func readSavedPlaceRequest(w http.ResponseWriter, r *http.Request, handler string) (SavedPlaceRequest, bool) {
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", handler, "err", err)
+		return SavedPlaceRequest{}, false
+	}
+
+	var placeRequest SavedPlaceRequest
+	if err := json.Unmarshal(bodyBytes, &placeRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", handler, "err", err)
+		return SavedPlaceRequest{}, false
+	}
+
+	placeRequest.Name = strings.TrimSpace(placeRequest.Name)
+	if placeRequest.Name == "" || len([]rune(placeRequest.Name)) > maxProfileTextLength {
+		http.Error(w, `{"message": "name must be 1 to 64 characters"}`, http.StatusBadRequest)
+		return SavedPlaceRequest{}, false
+	}
+	if hasControlChars(placeRequest.Name) {
+		http.Error(w, `{"message": "name must not contain line breaks or control characters"}`, http.StatusBadRequest)
+		return SavedPlaceRequest{}, false
+	}
+	if placeRequest.Latitude < -90 || placeRequest.Latitude > 90 || placeRequest.Longitude < -180 || placeRequest.Longitude > 180 {
+		http.Error(w, `{"message": "invalid latitude or longitude"}`, http.StatusBadRequest)
+		return SavedPlaceRequest{}, false
+	}
+	return placeRequest, true
+}
+
+func (s *Server) ListSavedPlaces(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	places, err := s.savedPlaces(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error listing places", "handler", "ListSavedPlaces", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to list saved places"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(SavedPlaceList{Places: places}); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ListSavedPlaces", "err", err)
+	}
+}
+
+func (s *Server) CreateSavedPlace(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	placeRequest, ok := readSavedPlaceRequest(w, r, "CreateSavedPlace")
+	if !ok {
+		return
+	}
+
+	count, err := s.db.CountSavedPlaces(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error counting places", "handler", "CreateSavedPlace", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to save place"}`, http.StatusInternalServerError)
+		return
+	}
+	if count >= maxSavedPlaces {
+		http.Error(w, fmt.Sprintf(`{"message": "at most %d places can be saved"}`, maxSavedPlaces), http.StatusBadRequest)
+		return
+	}
+
+	placeID, err := uuid.NewRandom()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Error generating place ID", "handler", "CreateSavedPlace", "err", err)
+		http.Error(w, `{"message": "failed to save place"}`, http.StatusInternalServerError)
+		return
+	}
+
+	place, err := s.db.CreateSavedPlace(r.Context(), db.CreateSavedPlaceParams{
+		PlaceID:   pgtype.UUID{Bytes: placeID, Valid: true},
+		UserID:    userID,
+		Name:      placeRequest.Name,
+		Longitude: placeRequest.Longitude,
+		Latitude:  placeRequest.Latitude,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			http.Error(w, `{"message": "a place with this name already exists"}`, http.StatusConflict)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Database error saving place", "handler", "CreateSavedPlace", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to save place"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(savedPlaceResponse(db.ListSavedPlacesRow(place))); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "CreateSavedPlace", "err", err)
+	}
+}
+
+func (s *Server) UpdateSavedPlace(w http.ResponseWriter, r *http.Request, placeId uuid.UUID) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	placeRequest, ok := readSavedPlaceRequest(w, r, "UpdateSavedPlace")
+	if !ok {
+		return
+	}
+
+	place, err := s.db.UpdateSavedPlace(r.Context(), db.UpdateSavedPlaceParams{
+		Name:      placeRequest.Name,
+		Longitude: placeRequest.Longitude,
+		Latitude:  placeRequest.Latitude,
+		PlaceID:   pgtype.UUID{Bytes: placeId, Valid: true},
+		UserID:    userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, `{"message": "place not found"}`, http.StatusNotFound)
+			return
+		}
+		if isUniqueViolation(err) {
+			http.Error(w, `{"message": "a place with this name already exists"}`, http.StatusConflict)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Database error updating place", "handler", "UpdateSavedPlace", "place_id", placeId, "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to update place"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(savedPlaceResponse(db.ListSavedPlacesRow(place))); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "UpdateSavedPlace", "err", err)
+	}
+}
+
+func (s *Server) DeleteSavedPlace(w http.ResponseWriter, r *http.Request, placeId uuid.UUID) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	deleted, err := s.db.DeleteSavedPlace(r.Context(), db.DeleteSavedPlaceParams{
+		PlaceID: pgtype.UUID{Bytes: placeId, Valid: true},
+		UserID:  userID,
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error deleting place", "handler", "DeleteSavedPlace", "place_id", placeId, "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to delete place"}`, http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, `{"message": "place not found"}`, http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
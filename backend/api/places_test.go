@@ -0,0 +1,40 @@
+package api
+
+import "testing"
+
+func TestResolveSavedPlace(t *testing.T) {
+	places := []SavedPlace{
+		{Name: "Дом"},
+		{Name: "Работа"},
+		{Name: "Дача"},
+		{Name: "Дача мамы"},
+	}
+
+	tests := []struct {
+		name   string
+		places []SavedPlace
+		query  string
+		want   string
+		wantOK bool
+	}{
+		{name: "exact", places: places, query: "Работа", want: "Работа", wantOK: true},
+		{name: "case and punctuation", places: places, query: " работа! ", want: "Работа", wantOK: true},
+		{name: "inflected form", places: places, query: "работы", want: "Работа", wantOK: true},
+		{name: "exact wins over fuzzy", places: places, query: "дача", want: "Дача", wantOK: true},
+		{name: "ambiguous fuzzy match", places: []SavedPlace{{Name: "Дача"}, {Name: "Даче"}}, query: "Дачу", wantOK: false},
+		{name: "too far", places: places, query: "Университет", wantOK: false},
+		{name: "no places", places: nil, query: "Дом", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := resolveSavedPlace(tt.places, tt.query)
+			if ok != tt.wantOK {
+				t.Fatalf("resolveSavedPlace(%q) ok = %v, want %v", tt.query, ok, tt.wantOK)
+			}
+			if got.Name != tt.want {
+				t.Errorf("resolveSavedPlace(%q) = %q, want %q", tt.query, got.Name, tt.want)
+			}
+		})
+	}
+}
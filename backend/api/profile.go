@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode"
+	db "voice_assistant/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxProfileTextLength bounds the free-text profile fields, in runes.
+const maxProfileTextLength = 64
+
+// hasControlChars reports whether s contains line breaks or other control
+// characters. Free text that ends up in the system instruction has to stay on
+// its line, or it could start an instruction section of its own.
+func hasControlChars(s string) bool {
+	return strings.ContainsFunc(s, func(r rune) bool {
+		return unicode.IsControl(r) || unicode.In(r, unicode.Zl, unicode.Zp)
+	})
+}
+
+// loadUserProfile returns the user's profile, or an empty one with every
+// preference unset when the user never saved it.
+func (s *Server) loadUserProfile(ctx context.Context, userID pgtype.UUID) (db.UserProfile, error) {
+	profile, err := s.db.GetUserProfile(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.UserProfile{UserID: userID}, nil
+	}
+	return profile, err
+}
+
+func profileResponse(profile db.UserProfile) UserProfile {
+	response := UserProfile{
+		DisplayName:  profile.DisplayName,
+		Language:     UserProfileLanguage(profile.Language),
+		DefaultCity:  profile.DefaultCity,
+		AnswerLength: UserProfileAnswerLength(profile.AnswerLength),
+		Units:        UserProfileUnits(profile.Units),
+	}
+	if profile.UpdatedAt.Valid {
+		response.UpdatedAt = &profile.UpdatedAt.Time
+	}
+	return response
+}
+
+// profilePrompt renders the user's preferences as a section of the system
+// instruction. Unset preferences are left out.
+func profilePrompt(profile db.UserProfile) string {
+	var lines []string
+	if profile.DisplayName != "" {
+		lines = append(lines, "имя пользователя: "+profile.DisplayName+" (обращайся по имени)")
+	}
+	switch profile.Language {
+	case "ru":
+		lines = append(lines, "язык ответа: русский")
+	case "be":
+		lines = append(lines, "язык ответа: белорусский")
+	case "en":
+		lines = append(lines, "язык ответа: английский (answer in English)")
+	}
+	if profile.DefaultCity != "" {
+		lines = append(lines, "город по умолчанию: "+profile.DefaultCity+" (если город не назван, ищи в нём)")
+	}
+	switch profile.AnswerLength {
+	case "short":
+		lines = append(lines, "длина ответа: коротко, одно-два предложения")
+	case "detailed":
+		lines = append(lines, "длина ответа: подробно")
+	}
+	switch profile.Units {
+	case "metric":
+		lines = append(lines, "расстояния: в метрах и километрах")
+	case "imperial":
+		lines = append(lines, "расстояния: в футах и милях")
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return `
+────────────── Предпочтения пользователя ──────────────
+` + strings.Join(lines, "\n") + "\n"
+}
+
+func (s *Server) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	profile, err := s.loadUserProfile(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error loading profile", "handler", "GetProfile", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to load profile"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(profileResponse(profile)); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "GetProfile", "err", err)
+	}
+}
+
+func (s *Server) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	defer func() { _ = r.Body.Close() }()
+	if err != nil {
+		http.Error(w, `{"message": "could not read request body"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error reading request body", "handler", "UpdateProfile", "err", err)
+		return
+	}
+
+	var updateRequest UpdateUserProfileRequest
+	if err := json.Unmarshal(bodyBytes, &updateRequest); err != nil {
+		http.Error(w, `{"message": "could not bind request body: `+err.Error()+`"}`, http.StatusBadRequest)
+		slog.ErrorContext(r.Context(), "Error unmarshalling request body", "handler", "UpdateProfile", "err", err)
+		return
+	}
+
+	profile, err := s.loadUserProfile(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error loading profile", "handler", "UpdateProfile", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to load profile"}`, http.StatusInternalServerError)
+		return
+	}
+
+	params := db.UpsertUserProfileParams{
+		UserID:       userID,
+		DisplayName:  profile.DisplayName,
+		Language:     profile.Language,
+		DefaultCity:  profile.DefaultCity,
+		AnswerLength: profile.AnswerLength,
+		Units:        profile.Units,
+	}
+	if updateRequest.DisplayName != nil {
+		params.DisplayName = strings.TrimSpace(*updateRequest.DisplayName)
+	}
+	if updateRequest.DefaultCity != nil {
+		params.DefaultCity = strings.TrimSpace(*updateRequest.DefaultCity)
+	}
+	if len([]rune(params.DisplayName)) > maxProfileTextLength || len([]rune(params.DefaultCity)) > maxProfileTextLength {
+		http.Error(w, `{"message": "display name and default city must be at most 64 characters"}`, http.StatusBadRequest)
+		return
+	}
+	if hasControlChars(params.DisplayName) || hasControlChars(params.DefaultCity) {
+		http.Error(w, `{"message": "display name and default city must not contain line breaks or control characters"}`, http.StatusBadRequest)
+		return
+	}
+	if updateRequest.Language != nil {
+		params.Language = string(*updateRequest.Language)
+	}
+	if updateRequest.AnswerLength != nil {
+		params.AnswerLength = string(*updateRequest.AnswerLength)
+	}
+	if updateRequest.Units != nil {
+		params.Units = string(*updateRequest.Units)
+	}
+
+	profile, err = s.db.UpsertUserProfile(r.Context(), params)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error saving profile", "handler", "UpdateProfile", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to save profile"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(profileResponse(profile)); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "UpdateProfile", "err", err)
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"sync"
+	"time"
+	"voice_assistant/util"
+)
+
+// quotaWindow is the length of the fixed window chat quotas are counted in.
+const quotaWindow = time.Hour
+
+// chatQuota limits how many chat requests a user can make per hour. Guests
+// get a stricter limit than registered users; a limit of zero means
+// unlimited. Counters live in memory only, so they reset on restart.
+type chatQuota struct {
+	userLimit  int
+	guestLimit int
+
+	mu      sync.Mutex
+	windows map[string]*quotaCounter
+}
+
+type quotaCounter struct {
+	start time.Time
+	count int
+}
+
+func newChatQuota(config util.Config) *chatQuota {
+	return &chatQuota{
+		userLimit:  config.ChatHourlyLimit,
+		guestLimit: config.GuestChatHourlyLimit,
+		windows:    make(map[string]*quotaCounter),
+	}
+}
+
+// take counts one request for userID. When the limit is used up it reports
+// how long until the window frees up instead.
+func (q *chatQuota) take(userID string, guest bool, now time.Time) (retryAfter time.Duration, ok bool) {
+	limit := q.userLimit
+	if guest {
+		limit = q.guestLimit
+	}
+	if limit <= 0 {
+		return 0, true
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c, exists := q.windows[userID]
+	if !exists || now.Sub(c.start) >= quotaWindow {
+		c = &quotaCounter{start: now}
+		q.windows[userID] = c
+	}
+	if c.count >= limit {
+		return c.start.Add(quotaWindow).Sub(now), false
+	}
+	c.count++
+	return 0, true
+}
+
+// prune drops counters whose window has ended.
+func (q *chatQuota) prune(now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for userID, c := range q.windows {
+		if now.Sub(c.start) >= quotaWindow {
+			delete(q.windows, userID)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"testing"
+	"time"
+	"voice_assistant/util"
+)
+
+func TestChatQuotaTake(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		userLimit      int
+		guestLimit     int
+		guest          bool
+		taken          int
+		at             time.Time
+		wantOK         bool
+		wantRetryAfter time.Duration
+	}{
+		{name: "under user limit", userLimit: 3, guestLimit: 1, taken: 2, at: now, wantOK: true},
+		{name: "user limit used up", userLimit: 3, guestLimit: 1, taken: 3, at: now.Add(20 * time.Minute), wantRetryAfter: 40 * time.Minute},
+		{name: "guest gets guest limit", userLimit: 3, guestLimit: 1, guest: true, taken: 1, at: now, wantRetryAfter: time.Hour},
+		{name: "window ended", userLimit: 3, guestLimit: 1, taken: 3, at: now.Add(time.Hour), wantOK: true},
+		{name: "zero user limit is unlimited", userLimit: 0, guestLimit: 1, taken: 100, at: now, wantOK: true},
+		{name: "zero guest limit is unlimited", userLimit: 3, guestLimit: 0, guest: true, taken: 100, at: now, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newChatQuota(util.Config{ChatHourlyLimit: tt.userLimit, GuestChatHourlyLimit: tt.guestLimit})
+			for i := 0; i < tt.taken; i++ {
+				if _, ok := q.take("user", tt.guest, now); !ok {
+					t.Fatalf("take #%d refused", i+1)
+				}
+			}
+			retryAfter, ok := q.take("user", tt.guest, tt.at)
+			if ok != tt.wantOK {
+				t.Fatalf("take() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if retryAfter != tt.wantRetryAfter {
+				t.Errorf("take() retryAfter = %s, want %s", retryAfter, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestChatQuotaCountsUsersSeparately(t *testing.T) {
+	now := time.Now()
+	q := newChatQuota(util.Config{ChatHourlyLimit: 1, GuestChatHourlyLimit: 1})
+	if _, ok := q.take("alice", false, now); !ok {
+		t.Fatal("first request of alice refused")
+	}
+	if _, ok := q.take("bob", false, now); !ok {
+		t.Fatal("first request of bob refused once alice hit the limit")
+	}
+}
+
+func TestChatQuotaPrune(t *testing.T) {
+	now := time.Now()
+	q := newChatQuota(util.Config{ChatHourlyLimit: 1})
+	q.take("old", false, now.Add(-time.Hour))
+	q.take("current", false, now.Add(-time.Minute))
+
+	q.prune(now)
+
+	if _, ok := q.windows["old"]; ok {
+		t.Error("prune kept a counter whose window ended")
+	}
+	if _, ok := q.windows["current"]; !ok {
+		t.Error("prune dropped a counter whose window is still open")
+	}
+}
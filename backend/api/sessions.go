@@ -0,0 +1,289 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+	db "voice_assistant/db/sqlc"
+	"voice_assistant/logging"
+	"voice_assistant/metrics"
+	"voice_assistant/tools"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxDeviceNameLength bounds the client-supplied device name and user agent.
+const maxDeviceNameLength = 128
+
+// errAccountLocked is returned by startSession for accounts locked by an admin.
+var errAccountLocked = errors.New("account is locked")
+
+// startSession opens a new auth session for a user who just proved their
+// identity and returns the access and refresh tokens bound to it.
+func (s *Server) startSession(r *http.Request, userID pgtype.UUID, roles []string, deviceName *string) (accessToken, refreshToken string, err error) {
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		return "", "", fmt.Errorf("generate session ID: %w", err)
+	}
+	refreshToken, refreshTokenExpiresAt, err := s.jwtAuth.GenerateRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	name := ""
+	if deviceName != nil {
+		name = truncate(*deviceName, maxDeviceNameLength)
+	}
+	created, err := s.db.CreateAuthSession(r.Context(), db.CreateAuthSessionParams{
+		SessionID:        pgtype.UUID{Bytes: sessionID, Valid: true},
+		UserID:           userID,
+		DeviceName:       name,
+		UserAgent:        truncate(r.UserAgent(), maxDeviceNameLength),
+		IpAddress:        clientIP(r),
+		RefreshTokenHash: s.jwtAuth.HashRefreshToken(refreshToken),
+		TtlSeconds:       time.Until(refreshTokenExpiresAt).Seconds(),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("save session: %w", err)
+	}
+	if created == 0 {
+		return "", "", errAccountLocked
+	}
+
+	accessToken, err = s.jwtAuth.GenerateToken(userID.Bytes, roles, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// sessionOutcome labels a failed startSession in the login metrics.
+func sessionOutcome(err error) string {
+	if errors.Is(err, errAccountLocked) {
+		return "locked"
+	}
+	return metrics.OutcomeError
+}
+
+// writeStartSessionError answers a failed startSession with 403 for locked
+// accounts, or 500.
+func writeStartSessionError(w http.ResponseWriter, r *http.Request, handler, email string, err error) {
+	if errors.Is(err, errAccountLocked) {
+		slog.WarnContext(r.Context(), "Refused session for locked account", "handler", handler, "email", logging.Email(email))
+		http.Error(w, `{"message": "account is locked"}`, http.StatusForbidden)
+		return
+	}
+	slog.ErrorContext(r.Context(), "Error creating session", "handler", handler, "email", logging.Email(email), "err", err)
+	http.Error(w, `{"message": "failed to create session"}`, http.StatusInternalServerError)
+}
+
+// revokeSessionTokens denylists the access tokens issued for sessions that
+// were just revoked, so they stop working before they expire.
+func (s *Server) revokeSessionTokens(ctx context.Context, sessionIDs ...pgtype.UUID) error {
+	ids := make([]string, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		ids = append(ids, uuid.UUID(sessionID.Bytes).String())
+	}
+	return s.revocations.RevokeSessions(ctx, ids...)
+}
+
+// revokeAllSessions revokes every session of a user along with its access tokens.
+func (s *Server) revokeAllSessions(ctx context.Context, userID pgtype.UUID) error {
+	sessionIDs, err := s.db.RevokeAllAuthSessions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	return s.revokeSessionTokens(ctx, sessionIDs...)
+}
+
+// revokeReusedRefreshToken handles a refresh token that did not match a live
+// session. If it is one that was already rotated, either the client or an
+// attacker holds a stolen copy; since the server cannot tell which, the whole
+// family (the session every token in the chain belongs to) is revoked and the
+// incident is recorded. reused reports whether that happened.
+func (s *Server) revokeReusedRefreshToken(r *http.Request, tokenHash string) (reused bool, err error) {
+	rotation, err := s.db.GetRotatedRefreshToken(r.Context(), tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("look up rotated token: %w", err)
+	}
+
+	revoked, err := s.db.RevokeAuthSessionFamily(r.Context(), rotation.SessionID)
+	if err != nil {
+		return true, fmt.Errorf("revoke session family: %w", err)
+	}
+	if err := s.revokeSessionTokens(r.Context(), rotation.SessionID); err != nil {
+		return true, err
+	}
+	s.recordAuditEvent(r, auditRefreshTokenReuse, rotation.UserID, rotation.SessionID, map[string]any{
+		"session_revoked": revoked > 0,
+	})
+	return true, nil
+}
+
+// authFromContext returns the user and session the request's access token was
+// issued for. The session is invalid for tokens issued before sessions existed.
+func authFromContext(r *http.Request) (userID, sessionID pgtype.UUID, ok bool) {
+	userIDFromToken, _ := r.Context().Value(tools.UserIDContextKey).(string)
+	parsedUserID, err := uuid.Parse(userIDFromToken)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, false
+	}
+	userID = pgtype.UUID{Bytes: parsedUserID, Valid: true}
+
+	sessionIDFromToken, _ := r.Context().Value(tools.SessionIDContextKey).(string)
+	if parsedSessionID, err := uuid.Parse(sessionIDFromToken); err == nil {
+		sessionID = pgtype.UUID{Bytes: parsedSessionID, Valid: true}
+	}
+	return userID, sessionID, true
+}
+
+// canManageSessions reports whether the token may sign out or manage the
+// sessions of its account. Full accounts need the account scope; guests, whose
+// tokens only carry chat, are let in too so they can sign out a lost device.
+func canManageSessions(r *http.Request) bool {
+	roles, _ := r.Context().Value(tools.RolesContextKey).([]string)
+	return isGuest(r) || slices.Contains(tools.ScopesForRoles(roles), tools.ScopeAccount)
+}
+
+// requireSessionAccess answers 403 unless canManageSessions.
+func requireSessionAccess(w http.ResponseWriter, r *http.Request) bool {
+	if canManageSessions(r) {
+		return true
+	}
+	http.Error(w, `{"message": "forbidden: the token does not grant the account scope"}`, http.StatusForbidden)
+	return false
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+func (s *Server) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, sessionID, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+	if !requireSessionAccess(w, r) {
+		return
+	}
+
+	sessions, err := s.db.ListAuthSessions(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error listing sessions", "handler", "ListSessions", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to list sessions"}`, http.StatusInternalServerError)
+		return
+	}
+
+	response := SessionList{Sessions: make([]Session, 0, len(sessions))}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, Session{
+			Id:         session.SessionID.Bytes,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IpAddress:  session.IpAddress,
+			CreatedAt:  session.CreatedAt.Time,
+			LastUsedAt: session.LastUsedAt.Time,
+			Current:    sessionID.Valid && session.SessionID.Bytes == sessionID.Bytes,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "ListSessions", "err", err)
+	}
+}
+
+func (s *Server) RevokeSession(w http.ResponseWriter, r *http.Request, sessionId uuid.UUID) {
+	userID, _, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+	if !requireSessionAccess(w, r) {
+		return
+	}
+
+	revoked, err := s.db.RevokeAuthSession(r.Context(), db.RevokeAuthSessionParams{
+		SessionID: pgtype.UUID{Bytes: sessionId, Valid: true},
+		UserID:    userID,
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error revoking session", "handler", "RevokeSession", "session_id", sessionId, "err", err)
+		http.Error(w, `{"message": "failed to revoke session"}`, http.StatusInternalServerError)
+		return
+	}
+	if revoked == 0 {
+		http.Error(w, `{"message": "session not found"}`, http.StatusNotFound)
+		return
+	}
+	if err := s.revokeSessionTokens(r.Context(), pgtype.UUID{Bytes: sessionId, Valid: true}); err != nil {
+		slog.ErrorContext(r.Context(), "Error revoking access tokens", "handler", "RevokeSession", "session_id", sessionId, "err", err)
+		http.Error(w, `{"message": "failed to revoke session"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID, sessionID, ok := authFromContext(r)
+	if !ok {
+		http.Error(w, `{"message": "Unauthorized: User identification not found"}`, http.StatusUnauthorized)
+		return
+	}
+	if !requireSessionAccess(w, r) {
+		return
+	}
+	if !sessionID.Valid {
+		http.Error(w, `{"message": "the access token is not bound to a session, log in again"}`, http.StatusBadRequest)
+		return
+	}
+
+	revoked, err := s.db.RevokeOtherAuthSessions(r.Context(), db.RevokeOtherAuthSessionsParams{
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Database error revoking sessions", "handler", "RevokeOtherSessions", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to revoke sessions"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := s.revokeSessionTokens(r.Context(), revoked...); err != nil {
+		slog.ErrorContext(r.Context(), "Error revoking access tokens", "handler", "RevokeOtherSessions", "user_id", userID, "err", err)
+		http.Error(w, `{"message": "failed to revoke sessions"}`, http.StatusInternalServerError)
+		return
+	}
+
+	response := RevokeSessionsResponse{Revoked: len(revoked)}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding success response", "handler", "RevokeOtherSessions", "err", err)
+	}
+}
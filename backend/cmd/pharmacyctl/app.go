@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+	dbCon "voice_assistant/db/sqlc"
+	"voice_assistant/pharmacy"
+	"voice_assistant/util"
+
+	chromago "github.com/amikos-tech/chroma-go/pkg/api/v2"
+	"github.com/amikos-tech/chroma-go/pkg/embeddings"
+	g "github.com/amikos-tech/chroma-go/pkg/embeddings/gemini"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Stores a command can operate on.
+const (
+	targetAll      = "all"
+	targetPostgres = "postgres"
+	targetChroma   = "chroma"
+)
+
+// Geocoders selectable with -geocoder.
+const (
+	geocoderOffline   = "offline"
+	geocoderNominatim = "nominatim"
+)
+
+// app holds the configuration and lazily opened connections shared by all commands.
+type app struct {
+	config    util.Config
+	configDir string
+	dryRun    bool
+	dataFile  string
+	coordFile string
+	// checkpointFile is only registered by commands that upload to Chroma.
+	checkpointFile string
+	// geocoderName is only registered by commands that need coordinates.
+	geocoderName string
+	// geocodeDelay overrides the spacing between Nominatim requests when set.
+	geocodeDelay time.Duration
+
+	pool         *pgxpool.Pool
+	queries      *dbCon.Queries
+	chromaClient chromago.Client
+}
+
+// newFlagSet registers the flags every command understands.
+func newFlagSet(name string, a *app) *flag.FlagSet {
+	fs := flag.NewFlagSet("pharmacyctl "+name, flag.ContinueOnError)
+	fs.StringVar(&a.configDir, "config", ".", "directory containing config.yaml")
+	fs.StringVar(&a.dataFile, "file", "", "dataset JSONL file (default PHARMACY_DATA_FILE)")
+	fs.StringVar(&a.coordFile, "coordinates", "", "coordinates JSONL file (default PHARMACY_COORDINATES_FILE)")
+	fs.BoolVar(&a.dryRun, "dry-run", false, "report what would change without writing anything")
+	return fs
+}
+
+// addCheckpointFlag registers -checkpoint for commands that upload to Chroma.
+func (a *app) addCheckpointFlag(fs *flag.FlagSet) {
+	fs.StringVar(&a.checkpointFile, "checkpoint", "", "upload checkpoint file (default <file>.chroma-checkpoint.json)")
+}
+
+// addGeocoderFlag registers -geocoder for commands that resolve coordinates.
+func (a *app) addGeocoderFlag(fs *flag.FlagSet) {
+	fs.StringVar(&a.geocoderName, "geocoder", "", "offline (coordinates file) or nominatim (cached HTTP lookups) (default PHARMACY_GEOCODER)")
+}
+
+// parse parses args and loads the configuration.
+func (a *app) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	config, err := util.LoadConfig(a.configDir)
+	if err != nil {
+		return fmt.Errorf("could not load config: %w", err)
+	}
+	a.config = config
+
+	if a.dataFile == "" {
+		a.dataFile = config.PharmacyDataFile
+	}
+	if a.coordFile == "" {
+		a.coordFile = config.PharmacyCoordinatesFile
+	}
+	if a.geocoderName == "" {
+		a.geocoderName = config.PharmacyGeocoder
+	}
+	if a.checkpointFile == "" {
+		a.checkpointFile = a.dataFile + ".chroma-checkpoint.json"
+	}
+	if a.dryRun {
+		log.Println("Dry run: no changes will be written.")
+	}
+	return nil
+}
+
+func (a *app) close() {
+	if a.pool != nil {
+		a.pool.Close()
+	}
+	if a.chromaClient != nil {
+		if err := a.chromaClient.Close(); err != nil {
+			log.Printf("Error closing Chroma client: %v", err)
+		}
+	}
+}
+
+func (a *app) postgres(ctx context.Context) (*dbCon.Queries, error) {
+	if a.queries != nil {
+		return a.queries, nil
+	}
+	if a.config.DbSource == "" {
+		return nil, fmt.Errorf("DB_SOURCE is not configured")
+	}
+	pool, err := pgxpool.New(ctx, a.config.DbSource)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to database: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("could not reach database: %w", err)
+	}
+	a.pool = pool
+	a.queries = dbCon.New(pool)
+	return a.queries, nil
+}
+
+func (a *app) chroma() (chromago.Client, error) {
+	if a.chromaClient != nil {
+		return a.chromaClient, nil
+	}
+	if a.config.ChromaBaseURL == "" {
+		return nil, fmt.Errorf("CHROMA_BASE_URL is not configured")
+	}
+	client, err := chromago.NewHTTPClient(chromago.WithBaseURL(a.config.ChromaBaseURL))
+	if err != nil {
+		return nil, fmt.Errorf("could not create Chroma client: %w", err)
+	}
+	a.chromaClient = client
+	return client, nil
+}
+
+func (a *app) embeddingFunction() (*g.GeminiEmbeddingFunction, error) {
+	ef, err := g.NewGeminiEmbeddingFunction(
+		g.WithAPIKey(a.config.GoogleAPIKey),
+		g.WithDefaultModel(embeddings.EmbeddingModel(a.config.GoogleEmbeddingModelName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create Gemini embedding function: %w", err)
+	}
+	return ef, nil
+}
+
+// collection opens the configured Chroma collection, creating it when create is set.
+func (a *app) collection(ctx context.Context, create bool) (chromago.Collection, error) {
+	client, err := a.chroma()
+	if err != nil {
+		return nil, err
+	}
+	ef, err := a.embeddingFunction()
+	if err != nil {
+		return nil, err
+	}
+	name := a.config.ChromaCollectionName
+	if create {
+		coll, err := client.GetOrCreateCollection(ctx, name, chromago.WithEmbeddingFunctionCreate(ef))
+		if err != nil {
+			return nil, fmt.Errorf("could not get or create collection %q: %w", name, err)
+		}
+		return coll, nil
+	}
+	coll, err := client.GetCollection(ctx, name, chromago.WithEmbeddingFunctionGet(ef))
+	if err != nil {
+		return nil, fmt.Errorf("could not get collection %q: %w", name, err)
+	}
+	return coll, nil
+}
+
+// loadRecords reads the dataset file and reports undecodable lines.
+func (a *app) loadRecords() ([]pharmacy.Record, error) {
+	records, lineErrs, err := pharmacy.ReadRecords(a.dataFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, le := range lineErrs {
+		log.Printf("Warning: %s %v, skipping", a.dataFile, le)
+	}
+	return records, nil
+}
+
+// uploadableRecords drops records without an original_id, which cannot be
+// given a stable Chroma document ID.
+func uploadableRecords(records []pharmacy.Record) []pharmacy.Record {
+	out := make([]pharmacy.Record, 0, len(records))
+	for _, rec := range records {
+		if rec.DocumentID() == "" {
+			log.Printf("Warning: line %d has no %s, skipping", rec.Line, pharmacy.KeyOriginalID)
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+// loadCoordinates reads the coordinates file keyed by record text.
+func (a *app) loadCoordinates() (map[string]*pharmacy.Coordinates, error) {
+	coords, lineErrs, err := pharmacy.ReadCoordinates(a.coordFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, le := range lineErrs {
+		log.Printf("Warning: %s %v, skipping", a.coordFile, le)
+	}
+	return coords, nil
+}
+
+// geocoder builds the geocoder selected with -geocoder. The offline geocoder
+// only reads the coordinates file; nominatim answers from the on-disk cache
+// first and only queries the service for addresses it has never seen.
+func (a *app) geocoder(name string) (pharmacy.Geocoder, error) {
+	switch name {
+	case geocoderOffline, "":
+		g, lineErrs, err := pharmacy.NewOfflineGeocoder(a.coordFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, le := range lineErrs {
+			log.Printf("Warning: %s %v, skipping", a.coordFile, le)
+		}
+		return g, nil
+	case geocoderNominatim:
+		if a.config.NominatimURL == "" {
+			return nil, fmt.Errorf("NOMINATIM_URL is not configured")
+		}
+		if a.config.GeocodeCacheFile == "" {
+			return nil, fmt.Errorf("GEOCODE_CACHE_FILE is not configured")
+		}
+		cache, err := pharmacy.OpenFileCache(a.config.GeocodeCacheFile)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Geocoding via %s, %d addresses cached in %s.", a.config.NominatimURL, cache.Len(), a.config.GeocodeCacheFile)
+		nominatim := pharmacy.NewNominatimGeocoder(a.config.NominatimURL)
+		if a.geocodeDelay > 0 {
+			nominatim.MinInterval = a.geocodeDelay
+		}
+		return &pharmacy.CachedGeocoder{Next: nominatim, Cache: cache}, nil
+	}
+	return nil, fmt.Errorf("invalid geocoder %q: want %s or %s", name, geocoderOffline, geocoderNominatim)
+}
+
+func validTarget(flagName, target string) error {
+	switch target {
+	case targetAll, targetPostgres, targetChroma:
+		return nil
+	}
+	return fmt.Errorf("invalid -%s %q: want %s, %s or %s", flagName, target, targetAll, targetPostgres, targetChroma)
+}
+
+func includes(target, store string) bool {
+	return target == targetAll || target == store
+}
+
+func progress(label string, total int) *pharmacy.Progress {
+	return pharmacy.NewProgress(os.Stderr, label, total)
+}
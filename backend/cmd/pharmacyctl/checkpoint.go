@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"voice_assistant/pharmacy"
+)
+
+// checkpoint remembers which documents of an interrupted Chroma upload were
+// already written, so the next run can skip them instead of embedding again.
+// It is deleted once an upload completes.
+type checkpoint struct {
+	path string
+
+	Collection string `json:"collection"`
+	// Completed maps document IDs to the content hash that was uploaded.
+	Completed map[string]string `json:"completed"`
+}
+
+// loadCheckpoint reads the checkpoint at path. A missing file, or one written
+// for another collection, yields an empty checkpoint.
+func loadCheckpoint(path, collection string) (*checkpoint, error) {
+	cp := &checkpoint{path: path, Collection: collection, Completed: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint %s: %w", path, err)
+	}
+
+	var stored checkpoint
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode checkpoint %s: %w", path, err)
+	}
+	if stored.Collection != collection {
+		log.Printf("Ignoring checkpoint %s written for collection %q.", path, stored.Collection)
+		return cp, nil
+	}
+	if stored.Completed != nil {
+		cp.Completed = stored.Completed
+	}
+	log.Printf("Resuming from checkpoint %s: %d documents already uploaded.", path, len(cp.Completed))
+	return cp, nil
+}
+
+// resumed reports whether the checkpoint carries progress from an earlier run.
+func (cp *checkpoint) resumed() bool {
+	return len(cp.Completed) > 0
+}
+
+func (cp *checkpoint) done(rec pharmacy.Record) bool {
+	return cp.Completed[rec.DocumentID()] == rec.ContentHash()
+}
+
+func (cp *checkpoint) markDone(records []pharmacy.Record) {
+	for _, rec := range records {
+		cp.Completed[rec.DocumentID()] = rec.ContentHash()
+	}
+}
+
+// save writes the checkpoint atomically.
+func (cp *checkpoint) save() error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if err := pharmacy.WriteFileAtomic(cp.path, data); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (cp *checkpoint) remove() {
+	if err := os.Remove(cp.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Warning: could not remove checkpoint %s: %v", cp.path, err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"voice_assistant/pharmacy"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	first := pharmacy.Record{Text: "Аптека 1", Metadata: map[string]any{pharmacy.KeyOriginalID: "1"}}
+	second := pharmacy.Record{Text: "Аптека 2", Metadata: map[string]any{pharmacy.KeyOriginalID: "2"}}
+	changed := pharmacy.Record{Text: "Аптека 1, новый адрес", Metadata: first.Metadata}
+
+	// saved stores a checkpoint of collection with records uploaded.
+	saved := func(collection string, records ...pharmacy.Record) func(t *testing.T, path string) {
+		return func(t *testing.T, path string) {
+			cp, err := loadCheckpoint(path, collection)
+			if err != nil {
+				t.Fatalf("loadCheckpoint: %v", err)
+			}
+			cp.markDone(records)
+			if err := cp.save(); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name        string
+		write       func(t *testing.T, path string)
+		wantErr     bool
+		wantResumed bool
+		wantDone    []pharmacy.Record
+		wantPending []pharmacy.Record
+	}{
+		{
+			name:        "no checkpoint",
+			write:       func(*testing.T, string) {},
+			wantPending: []pharmacy.Record{first, second},
+		},
+		{
+			name:        "resumes uploaded records",
+			write:       saved("pharmacies", first),
+			wantResumed: true,
+			wantDone:    []pharmacy.Record{first},
+			wantPending: []pharmacy.Record{second},
+		},
+		{
+			name:        "changed record is uploaded again",
+			write:       saved("pharmacies", first, second),
+			wantResumed: true,
+			wantDone:    []pharmacy.Record{second},
+			wantPending: []pharmacy.Record{changed},
+		},
+		{
+			name:        "checkpoint of another collection is ignored",
+			write:       saved("other", first, second),
+			wantPending: []pharmacy.Record{first, second},
+		},
+		{
+			name: "removed checkpoint",
+			write: func(t *testing.T, path string) {
+				saved("pharmacies", first)(t, path)
+				cp, _ := loadCheckpoint(path, "pharmacies")
+				cp.remove()
+			},
+			wantPending: []pharmacy.Record{first},
+		},
+		{
+			name: "corrupt checkpoint",
+			write: func(t *testing.T, path string) {
+				if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "upload.checkpoint.json")
+			tt.write(t, path)
+
+			cp, err := loadCheckpoint(path, "pharmacies")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("loadCheckpoint succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadCheckpoint: %v", err)
+			}
+			if cp.resumed() != tt.wantResumed {
+				t.Errorf("resumed = %v, want %v", cp.resumed(), tt.wantResumed)
+			}
+			for _, rec := range tt.wantDone {
+				if !cp.done(rec) {
+					t.Errorf("%q not done, want it skipped", rec.Text)
+				}
+			}
+			for _, rec := range tt.wantPending {
+				if cp.done(rec) {
+					t.Errorf("%q done, want it uploaded", rec.Text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"voice_assistant/pharmacy"
+)
+
+// coordinateTolerance is roughly 10 cm; smaller differences are rounding noise.
+const coordinateTolerance = 1e-6
+
+// difference is one line of diff output:
+// "-" present in the dataset only, "+" present in the store only, "~" present in both but different.
+type difference struct {
+	op    string
+	store string
+	text  string
+	note  string
+}
+
+func (d difference) String() string {
+	if d.note != "" {
+		return fmt.Sprintf("%s %s: %s (%s)", d.op, d.store, d.text, d.note)
+	}
+	return fmt.Sprintf("%s %s: %s", d.op, d.store, d.text)
+}
+
+// runDiff compares the dataset files with the stores and exits non-zero when they differ.
+func runDiff(ctx context.Context, args []string) error {
+	a := &app{}
+	fs := newFlagSet("diff", a)
+	target := fs.String("target", targetAll, "store to compare: all, postgres or chroma")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	defer a.close()
+	if err := validTarget("target", *target); err != nil {
+		return err
+	}
+
+	records, err := a.loadRecords()
+	if err != nil {
+		return err
+	}
+
+	var diffs []difference
+	if includes(*target, targetPostgres) {
+		d, err := a.diffPostgres(ctx, records)
+		if err != nil {
+			return fmt.Errorf("postgres: %w", err)
+		}
+		diffs = append(diffs, d...)
+	}
+	if includes(*target, targetChroma) {
+		d, err := a.diffChroma(ctx, records)
+		if err != nil {
+			return fmt.Errorf("chroma: %w", err)
+		}
+		diffs = append(diffs, d...)
+	}
+
+	for _, d := range diffs {
+		fmt.Fprintln(os.Stdout, d)
+	}
+	log.Printf("%d differences.", len(diffs))
+	if len(diffs) > 0 {
+		return errProblems
+	}
+	return nil
+}
+
+func (a *app) diffPostgres(ctx context.Context, records []pharmacy.Record) ([]difference, error) {
+	coords, err := a.loadCoordinates()
+	if err != nil {
+		return nil, err
+	}
+	queries, err := a.postgres(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locations, err := queries.ListLocations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list locations: %w", err)
+	}
+
+	stored := make(map[string]pharmacy.Coordinates, len(locations))
+	for _, loc := range locations {
+		stored[loc.Text] = pharmacy.Coordinates{Latitude: loc.Latitude, Longitude: loc.Longitude}
+	}
+
+	var diffs []difference
+	inDataset := make(map[string]bool, len(records))
+	for _, rec := range records {
+		inDataset[rec.Text] = true
+		got, ok := stored[rec.Text]
+		if !ok {
+			diffs = append(diffs, difference{op: "-", store: targetPostgres, text: rec.Text})
+			continue
+		}
+		if want := coords[rec.Text]; want != nil && !sameCoordinates(*want, got) {
+			diffs = append(diffs, difference{op: "~", store: targetPostgres, text: rec.Text,
+				note: fmt.Sprintf("stored %.6f,%.6f, dataset %.6f,%.6f", got.Latitude, got.Longitude, want.Latitude, want.Longitude)})
+		}
+	}
+	for _, text := range sortedKeys(stored) {
+		if !inDataset[text] {
+			diffs = append(diffs, difference{op: "+", store: targetPostgres, text: text})
+		}
+	}
+	return diffs, nil
+}
+
+func (a *app) diffChroma(ctx context.Context, records []pharmacy.Record) ([]difference, error) {
+	coll, err := a.collection(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := listChromaDocuments(ctx, coll)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string]pharmacy.Record, len(docs))
+	var diffs []difference
+	for _, d := range docs {
+		if _, dup := stored[d.Record.Text]; dup {
+			diffs = append(diffs, difference{op: "+", store: targetChroma, text: d.Record.Text, note: "duplicate id " + string(d.ID)})
+			continue
+		}
+		stored[d.Record.Text] = d.Record
+	}
+
+	inDataset := make(map[string]bool, len(records))
+	for _, rec := range records {
+		inDataset[rec.Text] = true
+		got, ok := stored[rec.Text]
+		if !ok {
+			diffs = append(diffs, difference{op: "-", store: targetChroma, text: rec.Text})
+			continue
+		}
+		for _, key := range pharmacy.MetadataKeys {
+			if want, have := rec.Field(key), got.Field(key); want != have {
+				diffs = append(diffs, difference{op: "~", store: targetChroma, text: rec.Text,
+					note: fmt.Sprintf("%s: stored %q, dataset %q", key, have, want)})
+			}
+		}
+	}
+	for _, text := range sortedKeys(stored) {
+		if !inDataset[text] {
+			diffs = append(diffs, difference{op: "+", store: targetChroma, text: text})
+		}
+	}
+	return diffs, nil
+}
+
+func sameCoordinates(a, b pharmacy.Coordinates) bool {
+	return math.Abs(a.Latitude-b.Latitude) <= coordinateTolerance &&
+		math.Abs(a.Longitude-b.Longitude) <= coordinateTolerance
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
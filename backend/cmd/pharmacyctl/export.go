@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"voice_assistant/pharmacy"
+)
+
+// runExport writes the contents of one store in the format it is imported from:
+// Postgres as a coordinates file, Chroma as a dataset file.
+func runExport(ctx context.Context, args []string) error {
+	a := &app{}
+	fs := newFlagSet("export", a)
+	source := fs.String("source", targetChroma, "store to export: postgres or chroma")
+	out := fs.String("out", "-", "output JSONL file, - for stdout")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	defer a.close()
+
+	switch *source {
+	case targetPostgres:
+		items, err := a.exportPostgres(ctx)
+		if err != nil {
+			return err
+		}
+		return writeExport(*out, items, a.dryRun)
+	case targetChroma:
+		items, err := a.exportChroma(ctx)
+		if err != nil {
+			return err
+		}
+		return writeExport(*out, items, a.dryRun)
+	}
+	return fmt.Errorf("invalid -source %q: want %s or %s", *source, targetPostgres, targetChroma)
+}
+
+func (a *app) exportPostgres(ctx context.Context) ([]pharmacy.CoordinateRecord, error) {
+	queries, err := a.postgres(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locations, err := queries.ListLocations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list locations: %w", err)
+	}
+	items := make([]pharmacy.CoordinateRecord, 0, len(locations))
+	for _, loc := range locations {
+		items = append(items, pharmacy.CoordinateRecord{
+			Text:        loc.Text,
+			Coordinates: &pharmacy.Coordinates{Latitude: loc.Latitude, Longitude: loc.Longitude},
+		})
+	}
+	log.Printf("Exporting %d Postgres locations.", len(items))
+	return items, nil
+}
+
+func (a *app) exportChroma(ctx context.Context) ([]pharmacy.Record, error) {
+	coll, err := a.collection(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := listChromaDocuments(ctx, coll)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]pharmacy.Record, 0, len(docs))
+	for _, d := range docs {
+		items = append(items, d.Record)
+	}
+	log.Printf("Exporting %d Chroma documents.", len(items))
+	return items, nil
+}
+
+func writeExport[T any](out string, items []T, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	if out == "-" {
+		return pharmacy.EncodeJSONL(os.Stdout, items)
+	}
+	return pharmacy.WriteJSONL(out, items)
+}
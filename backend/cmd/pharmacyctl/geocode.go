@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"voice_assistant/pharmacy"
+)
+
+// runGeocode resolves coordinates for the dataset and rewrites the coordinates file.
+// By default only records without coordinates are looked up. Lookups go through
+// the geocode cache, so rerunning only queries Nominatim for new addresses.
+func runGeocode(ctx context.Context, args []string) error {
+	a := &app{}
+	flags := newFlagSet("geocode", a)
+	all := flags.Bool("all", false, "geocode every record, not only those without coordinates")
+	flags.DurationVar(&a.geocodeDelay, "delay", pharmacy.NominatimInterval, "minimum pause between Nominatim requests (the public instance allows 1 req/s)")
+	if err := a.parse(flags, args); err != nil {
+		return err
+	}
+
+	records, err := a.loadRecords()
+	if err != nil {
+		return err
+	}
+	coords, _, err := pharmacy.ReadCoordinates(a.coordFile)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		coords = make(map[string]*pharmacy.Coordinates)
+	}
+
+	var pending []pharmacy.Record
+	for _, rec := range records {
+		if *all || coords[rec.Text] == nil {
+			pending = append(pending, rec)
+		}
+	}
+	log.Printf("%d records, %d to geocode.", len(records), len(pending))
+	if a.dryRun || len(pending) == 0 {
+		return nil
+	}
+
+	geocoder, err := a.geocoder(geocoderNominatim)
+	if err != nil {
+		return err
+	}
+	p := progress("geocode", len(pending))
+	for _, rec := range pending {
+		c, err := geocoder.Geocode(ctx, rec)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("Warning: line %d: %v", rec.Line, err)
+			p.Fail(1)
+			continue
+		}
+		coords[rec.Text] = &c
+		p.Add(1)
+	}
+	p.Finish()
+
+	// Keep the output in dataset order so the file diffs cleanly.
+	out := make([]pharmacy.CoordinateRecord, 0, len(records))
+	for _, rec := range records {
+		out = append(out, pharmacy.CoordinateRecord{Text: rec.Text, Coordinates: coords[rec.Text]})
+	}
+	if err := pharmacy.WriteJSONL(a.coordFile, out); err != nil {
+		return err
+	}
+	log.Printf("Wrote %s.", a.coordFile)
+	return nil
+}
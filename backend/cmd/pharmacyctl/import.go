@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	dbCon "voice_assistant/db/sqlc"
+	"voice_assistant/pharmacy"
+
+	chromago "github.com/amikos-tech/chroma-go/pkg/api/v2"
+)
+
+func runImport(ctx context.Context, args []string) error {
+	a := &app{}
+	fs := newFlagSet("import", a)
+	target := fs.String("target", targetAll, "store to import into: all, postgres or chroma")
+	a.addCheckpointFlag(fs)
+	a.addGeocoderFlag(fs)
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	defer a.close()
+	if err := validTarget("target", *target); err != nil {
+		return err
+	}
+
+	records, err := a.loadRecords()
+	if err != nil {
+		return err
+	}
+
+	if includes(*target, targetPostgres) {
+		if err := a.importPostgres(ctx, records); err != nil {
+			return fmt.Errorf("postgres: %w", err)
+		}
+	}
+	if includes(*target, targetChroma) {
+		if err := a.importChroma(ctx, records); err != nil {
+			return fmt.Errorf("chroma: %w", err)
+		}
+	}
+	return nil
+}
+
+// importPostgres inserts every record whose text is not yet in the locations table.
+func (a *app) importPostgres(ctx context.Context, records []pharmacy.Record) error {
+	geocoder, err := a.geocoder(a.geocoderName)
+	if err != nil {
+		return err
+	}
+	queries, err := a.postgres(ctx)
+	if err != nil {
+		return err
+	}
+	existing, err := queries.ListLocations(ctx)
+	if err != nil {
+		return fmt.Errorf("list locations: %w", err)
+	}
+	stored := make(map[string]bool, len(existing))
+	for _, loc := range existing {
+		stored[loc.Text] = true
+	}
+
+	var missing []pharmacy.Record
+	for _, rec := range records {
+		if !stored[rec.Text] {
+			missing = append(missing, rec)
+		}
+	}
+	log.Printf("Postgres: %d records in dataset, %d already stored, %d to insert.", len(records), len(records)-len(missing), len(missing))
+	if a.dryRun || len(missing) == 0 {
+		return nil
+	}
+
+	coords, err := resolveCoordinates(ctx, geocoder, missing)
+	if err != nil {
+		return err
+	}
+	return insertLocations(ctx, queries, missing, coords, progress("import postgres", len(missing)))
+}
+
+// resolveCoordinates geocodes every record up front, so slow lookups never run
+// inside a database transaction. Records without a match are left out of the
+// result and skipped by insertLocations; any other failure aborts the run.
+func resolveCoordinates(ctx context.Context, geocoder pharmacy.Geocoder, records []pharmacy.Record) (map[string]*pharmacy.Coordinates, error) {
+	coords := make(map[string]*pharmacy.Coordinates, len(records))
+	p := progress("geocode", len(records))
+	defer p.Finish()
+	for _, rec := range records {
+		c, err := geocoder.Geocode(ctx, rec)
+		if errors.Is(err, pharmacy.ErrNotFound) {
+			p.Skip(1)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", rec.Line, err)
+		}
+		coords[rec.Text] = &c
+		p.Add(1)
+	}
+	return coords, nil
+}
+
+func insertLocations(ctx context.Context, queries *dbCon.Queries, records []pharmacy.Record, coords map[string]*pharmacy.Coordinates, p *pharmacy.Progress) error {
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c := coords[rec.Text]
+		if c == nil {
+			log.Printf("Warning: no coordinates for line %d (%s), skipping", rec.Line, rec.Address())
+			p.Skip(1)
+			continue
+		}
+		err := queries.InsertLocation(ctx, dbCon.InsertLocationParams{
+			Text:           rec.Text,
+			PharmacyNumber: rec.Field(pharmacy.KeyPharmacyNumber),
+			Phone:          rec.Field(pharmacy.KeyPhoneNumber),
+			PharmacyName:   rec.Field(pharmacy.KeyPharmacyName),
+			Longitude:      c.Longitude,
+			Latitude:       c.Latitude,
+		})
+		if err != nil {
+			log.Printf("Error inserting line %d: %v", rec.Line, err)
+			p.Fail(1)
+			continue
+		}
+		p.Add(1)
+	}
+	p.Finish()
+
+	if p.Failed() > 0 {
+		return fmt.Errorf("%d records could not be inserted", p.Failed())
+	}
+	return nil
+}
+
+// importChroma upserts every record that is missing from the collection or
+// whose text or metadata changed. Copies of dataset records stored under
+// other IDs (left behind by uploads that used random IDs) are removed.
+func (a *app) importChroma(ctx context.Context, records []pharmacy.Record) error {
+	records = uploadableRecords(records)
+
+	// A dry run must not create the collection as a side effect.
+	coll, err := a.collection(ctx, !a.dryRun)
+	if err != nil {
+		return err
+	}
+	docs, err := listChromaDocuments(ctx, coll)
+	if err != nil {
+		return err
+	}
+
+	storedHash := make(map[string]string, len(docs))
+	for _, d := range docs {
+		storedHash[string(d.ID)] = d.Record.ContentHash()
+	}
+	datasetIDs := make(map[string]bool, len(records))
+	datasetTexts := make(map[string]bool, len(records))
+	var pending []pharmacy.Record
+	for _, rec := range records {
+		datasetIDs[rec.DocumentID()] = true
+		datasetTexts[rec.Text] = true
+		if storedHash[rec.DocumentID()] != rec.ContentHash() {
+			pending = append(pending, rec)
+		}
+	}
+	var stale []chromago.DocumentID
+	for _, d := range docs {
+		if !datasetIDs[string(d.ID)] && datasetTexts[d.Record.Text] {
+			stale = append(stale, d.ID)
+		}
+	}
+
+	log.Printf("Chroma: %d records in dataset, %d up to date, %d to upsert, %d stale copies to delete.",
+		len(records), len(records)-len(pending), len(pending), len(stale))
+	if a.dryRun {
+		return nil
+	}
+
+	if len(stale) > 0 {
+		if err := deleteChromaDocuments(ctx, coll, stale); err != nil {
+			return err
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	cp, err := loadCheckpoint(a.checkpointFile, a.config.ChromaCollectionName)
+	if err != nil {
+		return err
+	}
+	p := progress("import chroma", len(pending))
+	err = upsertChromaRecords(ctx, coll, pending, cp, p)
+	p.Finish()
+	if err != nil {
+		return err
+	}
+	cp.remove()
+	return nil
+}
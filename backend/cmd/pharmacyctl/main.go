@@ -0,0 +1,97 @@
+// Command pharmacyctl manages the pharmacy dataset stored in Postgres
+// (locations table, used for nearest-pharmacy queries) and in Chroma
+// (embedded documents, used for RAG search).
+//
+// Usage:
+//
+//	pharmacyctl <command> [flags]
+//
+// Commands:
+//
+//	import    load records missing from Postgres and/or Chroma
+//	export    dump Postgres or Chroma contents as JSONL
+//	validate  check the dataset files (and optionally the stores) for problems
+//	diff      compare the dataset files with both stores
+//	geocode   resolve missing coordinates via Nominatim (cached on disk)
+//	reindex   wipe and rebuild Postgres and/or Chroma from the dataset
+//
+// Every command reads its defaults from config.yaml / environment (see util.Config).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// errProblems is returned by commands that completed but found problems
+// (validation failures, differences); it maps to exit status 1 without
+// printing an additional error line.
+var errProblems = errors.New("problems found")
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"import", "load records missing from Postgres and/or Chroma", runImport},
+	{"export", "dump Postgres or Chroma contents as JSONL", runExport},
+	{"validate", "check the dataset files (and optionally the stores) for problems", runValidate},
+	{"diff", "compare the dataset files with both stores", runDiff},
+	{"geocode", "resolve missing coordinates via Nominatim (cached on disk)", runGeocode},
+	{"reindex", "wipe and rebuild Postgres and/or Chroma from the dataset", runReindex},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage()
+		return 2
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err := cmd.run(ctx, args[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 2
+		case errors.Is(err, errProblems):
+			return 1
+		default:
+			fmt.Fprintf(os.Stderr, "pharmacyctl %s: %v\n", cmd.name, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "pharmacyctl: unknown command %q\n\n", args[0])
+	usage()
+	return 2
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: pharmacyctl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'pharmacyctl <command> -h' for command flags.")
+}
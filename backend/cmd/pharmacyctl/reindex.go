@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	dbCon "voice_assistant/db/sqlc"
+	"voice_assistant/pharmacy"
+)
+
+// runReindex drops the stored data and loads the dataset from scratch.
+func runReindex(ctx context.Context, args []string) error {
+	a := &app{}
+	fs := newFlagSet("reindex", a)
+	target := fs.String("target", targetAll, "store to rebuild: all, postgres or chroma")
+	a.addCheckpointFlag(fs)
+	a.addGeocoderFlag(fs)
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	defer a.close()
+	if err := validTarget("target", *target); err != nil {
+		return err
+	}
+
+	records, err := a.loadRecords()
+	if err != nil {
+		return err
+	}
+
+	if includes(*target, targetPostgres) {
+		if err := a.reindexPostgres(ctx, records); err != nil {
+			return fmt.Errorf("postgres: %w", err)
+		}
+	}
+	if includes(*target, targetChroma) {
+		if err := a.reindexChroma(ctx, records); err != nil {
+			return fmt.Errorf("chroma: %w", err)
+		}
+	}
+	return nil
+}
+
+// reindexPostgres replaces the locations table in a single transaction,
+// so a failed run leaves the previous data in place.
+func (a *app) reindexPostgres(ctx context.Context, records []pharmacy.Record) error {
+	geocoder, err := a.geocoder(a.geocoderName)
+	if err != nil {
+		return err
+	}
+	queries, err := a.postgres(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Postgres: replacing locations with %d records.", len(records))
+	if a.dryRun {
+		return nil
+	}
+
+	coords, err := resolveCoordinates(ctx, geocoder, records)
+	if err != nil {
+		return err
+	}
+
+	tx, err := a.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	qtx := queries.WithTx(tx)
+	// Favorites reference locations by id, which the reload changes; they are
+	// re-attached to the new rows by pharmacy name and number.
+	favorites, err := qtx.ListFavoritePharmacyKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("list favorite pharmacies: %w", err)
+	}
+	if err := qtx.DeleteAllLocations(ctx); err != nil {
+		return fmt.Errorf("delete locations: %w", err)
+	}
+	if err := insertLocations(ctx, qtx, records, coords, progress("reindex postgres", len(records))); err != nil {
+		return err
+	}
+	if err := restoreFavorites(ctx, qtx, favorites); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// restoreFavorites re-attaches favorite pharmacies saved before a reload.
+// Favorites of pharmacies that are no longer in the data are dropped.
+func restoreFavorites(ctx context.Context, queries *dbCon.Queries, favorites []dbCon.ListFavoritePharmacyKeysRow) error {
+	var dropped int
+	for _, f := range favorites {
+		restored, err := queries.RestoreFavoritePharmacy(ctx, dbCon.RestoreFavoritePharmacyParams{
+			UserID:         f.UserID,
+			Label:          f.Label,
+			CreatedAt:      f.CreatedAt,
+			PharmacyName:   f.PharmacyName,
+			PharmacyNumber: f.PharmacyNumber,
+			Text:           f.Text,
+		})
+		if err != nil {
+			return fmt.Errorf("restore favorite pharmacy: %w", err)
+		}
+		if restored == 0 {
+			dropped++
+		}
+	}
+	if len(favorites) > 0 {
+		log.Printf("Postgres: restored %d of %d favorite pharmacies.", len(favorites)-dropped, len(favorites))
+	}
+	return nil
+}
+
+// reindexChroma deletes the collection and uploads every record again.
+// When a checkpoint from an interrupted reindex exists, the collection is kept
+// and the upload resumes after the last completed batch.
+func (a *app) reindexChroma(ctx context.Context, records []pharmacy.Record) error {
+	records = uploadableRecords(records)
+
+	client, err := a.chroma()
+	if err != nil {
+		return err
+	}
+	name := a.config.ChromaCollectionName
+	cp, err := loadCheckpoint(a.checkpointFile, name)
+	if err != nil {
+		return err
+	}
+	if cp.resumed() {
+		log.Printf("Chroma: resuming reindex of collection %q.", name)
+	} else {
+		log.Printf("Chroma: recreating collection %q with %d records.", name, len(records))
+	}
+	if a.dryRun {
+		return nil
+	}
+
+	if !cp.resumed() {
+		if err := client.DeleteCollection(ctx, name); err != nil {
+			log.Printf("Warning: could not delete collection %q (it may not exist): %v", name, err)
+		}
+	}
+	coll, err := a.collection(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	p := progress("reindex chroma", len(records))
+	err = upsertChromaRecords(ctx, coll, records, cp, p)
+	p.Finish()
+	if err != nil {
+		return err
+	}
+	cp.remove()
+	return nil
+}
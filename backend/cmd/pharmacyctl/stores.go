@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"voice_assistant/pharmacy"
+
+	chromago "github.com/amikos-tech/chroma-go/pkg/api/v2"
+	chhttp "github.com/amikos-tech/chroma-go/pkg/commons/http"
+	"github.com/googleapis/gax-go/v2/apierror"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	chromaPageSize  = 100
+	chromaBatchSize = 100
+)
+
+// chromaDocument is a document read back from the collection.
+type chromaDocument struct {
+	ID     chromago.DocumentID
+	Record pharmacy.Record
+}
+
+// listChromaDocuments pages through the whole collection.
+func listChromaDocuments(ctx context.Context, coll chromago.Collection) ([]chromaDocument, error) {
+	var out []chromaDocument
+	for offset := 0; ; offset += chromaPageSize {
+		var res chromago.GetResult
+		err := pharmacy.Retry(ctx, pharmacy.DefaultBackoff, isTransient, func() error {
+			var err error
+			res, err = coll.Get(ctx,
+				chromago.WithIncludeGet(chromago.IncludeDocuments, chromago.IncludeMetadatas),
+				chromago.WithLimitGet(chromaPageSize),
+				chromago.WithOffsetGet(offset),
+			)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list collection documents at offset %d: %w", offset, err)
+		}
+
+		ids := res.GetIDs()
+		docs := res.GetDocuments()
+		metas := res.GetMetadatas()
+		for i, id := range ids {
+			rec := pharmacy.Record{Metadata: make(map[string]any)}
+			if i < len(docs) && docs[i] != nil {
+				rec.Text = docs[i].ContentString()
+			}
+			if i < len(metas) && metas[i] != nil {
+				for _, key := range pharmacy.MetadataKeys {
+					if v, ok := metas[i].GetRaw(key); ok {
+						rec.Metadata[key] = v
+					}
+				}
+			}
+			out = append(out, chromaDocument{ID: id, Record: rec})
+		}
+
+		if len(ids) < chromaPageSize {
+			return out, nil
+		}
+	}
+}
+
+// upsertChromaRecords writes records in batches under their deterministic IDs.
+// Batches already recorded in cp are skipped, and cp is saved after every
+// successful batch. A batch that still fails after retries is counted as failed
+// and the upload moves on, so one bad batch does not discard the rest of the run.
+func upsertChromaRecords(ctx context.Context, coll chromago.Collection, records []pharmacy.Record, cp *checkpoint, p *pharmacy.Progress) error {
+	for start := 0; start < len(records); start += chromaBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+chromaBatchSize, len(records))
+
+		var batch []pharmacy.Record
+		for _, rec := range records[start:end] {
+			if cp.done(rec) {
+				p.Skip(1)
+				continue
+			}
+			batch = append(batch, rec)
+		}
+		if len(batch) == 0 {
+			continue
+		}
+
+		ids := make([]chromago.DocumentID, 0, len(batch))
+		texts := make([]string, 0, len(batch))
+		metas := make([]chromago.DocumentMetadata, 0, len(batch))
+		for _, rec := range batch {
+			dm, err := chromago.NewDocumentMetadataFromMap(pharmacy.CleanMetadata(rec.Metadata))
+			if err != nil {
+				return fmt.Errorf("metadata for line %d: %w", rec.Line, err)
+			}
+			ids = append(ids, chromago.DocumentID(rec.DocumentID()))
+			texts = append(texts, rec.Text)
+			metas = append(metas, dm)
+		}
+
+		err := pharmacy.Retry(ctx, pharmacy.DefaultBackoff, isTransient, func() error {
+			err := coll.Upsert(ctx,
+				chromago.WithIDs(ids...),
+				chromago.WithTexts(texts...),
+				chromago.WithMetadatas(metas...),
+			)
+			if err != nil && isTransient(err) {
+				log.Printf("Warning: batch starting at record %d failed, retrying: %v", start, err)
+			}
+			return err
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			log.Printf("Error: batch starting at record %d failed: %v", start, err)
+			p.Fail(len(batch))
+			continue
+		}
+
+		cp.markDone(batch)
+		if err := cp.save(); err != nil {
+			return err
+		}
+		p.Add(len(batch))
+	}
+
+	if p.Failed() > 0 {
+		return fmt.Errorf("%d documents could not be uploaded; rerun to resume from %s", p.Failed(), cp.path)
+	}
+	return nil
+}
+
+// deleteChromaDocuments removes documents by ID.
+func deleteChromaDocuments(ctx context.Context, coll chromago.Collection, ids []chromago.DocumentID) error {
+	for start := 0; start < len(ids); start += chromaBatchSize {
+		batch := ids[start:min(start+chromaBatchSize, len(ids))]
+		err := pharmacy.Retry(ctx, pharmacy.DefaultBackoff, isTransient, func() error {
+			return coll.Delete(ctx, chromago.WithIDsDelete(batch...))
+		})
+		if err != nil {
+			return fmt.Errorf("delete documents: %w", err)
+		}
+	}
+	return nil
+}
+
+// isTransient reports whether a Chroma or Gemini error is worth retrying:
+// connection failures, rate limiting and server-side errors.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var chromaErr *chhttp.ChromaError
+	if errors.As(err, &chromaErr) {
+		// Chroma reports connection failures with code 0.
+		return chromaErr.ErrorCode == 0 || retryableStatus(chromaErr.ErrorCode)
+	}
+
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) {
+		if code := apiErr.HTTPCode(); code > 0 {
+			return retryableStatus(code)
+		}
+		switch apiErr.GRPCStatus().Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded, codes.Internal:
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= http.StatusInternalServerError
+}
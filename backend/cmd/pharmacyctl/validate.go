@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"voice_assistant/pharmacy"
+)
+
+// runValidate checks the dataset files and exits non-zero when any issue is
+// found. With -stores it also reports records missing from Postgres or Chroma.
+func runValidate(ctx context.Context, args []string) error {
+	a := &app{}
+	fs := newFlagSet("validate", a)
+	stores := fs.String("stores", "", "also check that every record is stored: all, postgres or chroma")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	defer a.close()
+	if *stores != "" {
+		if err := validTarget("stores", *stores); err != nil {
+			return err
+		}
+	}
+
+	records, lineErrs, err := pharmacy.ReadRecords(a.dataFile)
+	if err != nil {
+		return err
+	}
+	coords, coordErrs, err := pharmacy.ReadCoordinates(a.coordFile)
+	if err != nil {
+		return err
+	}
+
+	var issues []pharmacy.Issue
+	for _, le := range lineErrs {
+		issues = append(issues, pharmacy.Issue{Line: le.Line, Check: "invalid-json", Message: le.Err.Error()})
+	}
+	for _, le := range coordErrs {
+		issues = append(issues, pharmacy.Issue{Check: "invalid-json", Message: fmt.Sprintf("%s line %d: %v", a.coordFile, le.Line, le.Err)})
+	}
+	issues = append(issues, pharmacy.Validate(records, coords)...)
+
+	if *stores != "" {
+		missing, err := a.missingFromStores(ctx, records, *stores)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, missing...)
+	}
+
+	for _, issue := range issues {
+		fmt.Fprintln(os.Stdout, issue)
+	}
+	log.Printf("Validated %d records: %d issues.", len(records), len(issues))
+	if len(issues) > 0 {
+		return errProblems
+	}
+	return nil
+}
+
+// missingFromStores reports dataset records that the selected stores do not hold.
+func (a *app) missingFromStores(ctx context.Context, records []pharmacy.Record, target string) ([]pharmacy.Issue, error) {
+	lines := make(map[string]int, len(records))
+	for _, rec := range records {
+		lines[rec.Text] = rec.Line
+	}
+
+	var diffs []difference
+	if includes(target, targetPostgres) {
+		d, err := a.diffPostgres(ctx, records)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: %w", err)
+		}
+		diffs = append(diffs, d...)
+	}
+	if includes(target, targetChroma) {
+		d, err := a.diffChroma(ctx, records)
+		if err != nil {
+			return nil, fmt.Errorf("chroma: %w", err)
+		}
+		diffs = append(diffs, d...)
+	}
+
+	var issues []pharmacy.Issue
+	for _, d := range diffs {
+		if d.op != "-" {
+			continue
+		}
+		issues = append(issues, pharmacy.Issue{Line: lines[d.text], Check: "missing-" + d.store, Message: "record is not stored in " + d.store})
+	}
+	return issues, nil
+}
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN code VARCHAR(8);
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified;
+
+DROP TABLE IF EXISTS verification_codes;
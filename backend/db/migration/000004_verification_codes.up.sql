@@ -0,0 +1,20 @@
+CREATE TABLE verification_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    purpose VARCHAR(32) NOT NULL CHECK (purpose IN ('email_verification', 'password_reset')),
+    code_hash TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    used_at TIMESTAMP
+);
+
+CREATE INDEX verification_codes_user_purpose_idx ON verification_codes (user_id, purpose, created_at DESC);
+
+-- users.code doubled as "email not verified" and as the password reset code.
+-- Plain-text codes cannot be carried over; anyone with a pending code
+-- requests a new one through /api/auth/resend-code.
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT false;
+UPDATE users SET email_verified = (code IS NULL);
+ALTER TABLE users DROP COLUMN code;
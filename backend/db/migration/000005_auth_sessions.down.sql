@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN refresh_token TEXT UNIQUE, ADD COLUMN expired_at TIMESTAMP;
+
+DROP TABLE IF EXISTS auth_sessions;
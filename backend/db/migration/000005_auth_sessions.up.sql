@@ -0,0 +1,22 @@
+CREATE TABLE auth_sessions (
+    session_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    device_name TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    refresh_token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX auth_sessions_user_idx ON auth_sessions (user_id);
+
+-- Keep users signed in: every unexpired refresh token becomes a session.
+INSERT INTO auth_sessions (session_id, user_id, refresh_token_hash, expires_at)
+SELECT gen_random_uuid(), user_id, encode(sha256(convert_to(refresh_token, 'UTF8')), 'hex'), expired_at
+FROM users
+WHERE refresh_token IS NOT NULL AND expired_at > now();
+
+ALTER TABLE users DROP COLUMN refresh_token, DROP COLUMN expired_at;
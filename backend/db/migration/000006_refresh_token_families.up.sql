@@ -0,0 +1,22 @@
+-- Every auth session is a rotation family. Hashes of refresh tokens that were
+-- already rotated are kept so that replaying one can be detected as theft.
+CREATE TABLE refresh_token_rotations (
+    token_hash TEXT PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES auth_sessions (session_id) ON DELETE CASCADE,
+    rotated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX refresh_token_rotations_session_idx ON refresh_token_rotations (session_id);
+
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    user_id UUID REFERENCES users (user_id) ON DELETE SET NULL,
+    session_id UUID,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_events_user_idx ON audit_events (user_id, created_at DESC);
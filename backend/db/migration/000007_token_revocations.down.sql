@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS locked_at;
+
+DROP TABLE IF EXISTS token_revocations;
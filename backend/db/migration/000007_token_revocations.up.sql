@@ -0,0 +1,12 @@
+-- Denylist for access tokens that must stop working before they expire.
+-- token_key is "jti:<token id>" or "sid:<session id>"; an entry can be
+-- dropped once every token it could match has expired on its own.
+CREATE TABLE token_revocations (
+    token_key TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX token_revocations_expires_idx ON token_revocations (expires_at);
+
+ALTER TABLE users ADD COLUMN locked_at TIMESTAMP;
@@ -0,0 +1,5 @@
+DELETE FROM verification_codes WHERE purpose = 'email_change';
+ALTER TABLE verification_codes DROP COLUMN IF EXISTS new_email;
+ALTER TABLE verification_codes DROP CONSTRAINT verification_codes_purpose_check;
+ALTER TABLE verification_codes ADD CONSTRAINT verification_codes_purpose_check
+    CHECK (purpose IN ('email_verification', 'password_reset'));
@@ -0,0 +1,6 @@
+-- Codes for moving an account to a new address are sent to that address and
+-- remember it until the code is redeemed.
+ALTER TABLE verification_codes DROP CONSTRAINT verification_codes_purpose_check;
+ALTER TABLE verification_codes ADD CONSTRAINT verification_codes_purpose_check
+    CHECK (purpose IN ('email_verification', 'password_reset', 'email_change'));
+ALTER TABLE verification_codes ADD COLUMN new_email VARCHAR;
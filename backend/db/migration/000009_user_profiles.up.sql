@@ -0,0 +1,10 @@
+-- Preferences the assistant applies on every device. Empty strings mean "not set".
+CREATE TABLE user_profiles (
+    user_id UUID PRIMARY KEY REFERENCES users (user_id) ON DELETE CASCADE,
+    display_name VARCHAR(64) NOT NULL DEFAULT '',
+    language VARCHAR(8) NOT NULL DEFAULT '' CHECK (language IN ('', 'ru', 'be', 'en')),
+    default_city VARCHAR(64) NOT NULL DEFAULT '',
+    answer_length VARCHAR(16) NOT NULL DEFAULT '' CHECK (answer_length IN ('', 'short', 'normal', 'detailed')),
+    units VARCHAR(16) NOT NULL DEFAULT '' CHECK (units IN ('', 'metric', 'imperial')),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
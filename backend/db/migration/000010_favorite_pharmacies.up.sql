@@ -0,0 +1,9 @@
+CREATE TABLE favorite_pharmacies (
+    user_id UUID NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    location_id INTEGER NOT NULL REFERENCES locations (id) ON DELETE CASCADE,
+    label VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, location_id)
+);
+
+CREATE INDEX favorite_pharmacies_location_id_idx ON favorite_pharmacies (location_id);
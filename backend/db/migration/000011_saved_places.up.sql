@@ -0,0 +1,9 @@
+CREATE TABLE saved_places (
+    place_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    location GEOMETRY(Point, 4326) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX saved_places_user_id_name_idx ON saved_places (user_id, lower(name));
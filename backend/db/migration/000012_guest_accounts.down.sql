@@ -0,0 +1,2 @@
+DELETE FROM users WHERE 'guest' = ANY (roles);
+DROP TABLE IF EXISTS guest_devices;
@@ -0,0 +1,11 @@
+-- Guests are users with the 'guest' role, a placeholder email and no password.
+-- Their device key is the only credential; it is stored keyed-hashed like
+-- refresh tokens and dropped once the guest upgrades to a full account.
+CREATE TABLE guest_devices (
+    device_key_hash TEXT PRIMARY KEY,
+    user_id UUID NOT NULL UNIQUE REFERENCES users (user_id) ON DELETE CASCADE,
+    ip_address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX guest_devices_ip_address_created_at_idx ON guest_devices (ip_address, created_at);
@@ -0,0 +1,15 @@
+-- Keys for partner and machine clients. Only a keyed hash of each key is
+-- stored; key_prefix is kept so admins can tell keys apart.
+CREATE TABLE api_keys (
+    key_id UUID PRIMARY KEY,
+    key_hash TEXT NOT NULL UNIQUE,
+    key_prefix VARCHAR(16) NOT NULL,
+    owner VARCHAR(128) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    rate_limit_per_minute INTEGER NOT NULL DEFAULT 60 CHECK (rate_limit_per_minute > 0),
+    created_by UUID REFERENCES users (user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
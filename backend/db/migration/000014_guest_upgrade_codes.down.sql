@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_verification_codes_new_email;
+DELETE FROM verification_codes WHERE purpose = 'guest_upgrade';
+ALTER TABLE verification_codes DROP CONSTRAINT verification_codes_purpose_check;
+ALTER TABLE verification_codes ADD CONSTRAINT verification_codes_purpose_check
+    CHECK (purpose IN ('email_verification', 'password_reset', 'email_change'));
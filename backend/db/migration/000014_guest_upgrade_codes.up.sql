@@ -0,0 +1,7 @@
+-- Guests upgrading to a full account get a code sent to the new address, which
+-- is only written to users.email once the code is confirmed.
+ALTER TABLE verification_codes DROP CONSTRAINT verification_codes_purpose_check;
+ALTER TABLE verification_codes ADD CONSTRAINT verification_codes_purpose_check
+    CHECK (purpose IN ('email_verification', 'password_reset', 'email_change', 'guest_upgrade'));
+CREATE INDEX idx_verification_codes_new_email ON verification_codes (new_email)
+    WHERE new_email IS NOT NULL;
@@ -0,0 +1,40 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    key_id,
+    key_hash,
+    key_prefix,
+    owner,
+    scopes,
+    rate_limit_per_minute,
+    created_by,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7,
+    CASE WHEN sqlc.narg(ttl_seconds)::float8 IS NULL THEN NULL
+         ELSE now() + make_interval(secs => sqlc.narg(ttl_seconds)::float8)
+    END
+)
+RETURNING key_id, key_prefix, owner, scopes, rate_limit_per_minute, created_at, expires_at, last_used_at, revoked_at;
+
+-- name: GetActiveAPIKeyByHash :one
+SELECT key_id, owner, scopes, rate_limit_per_minute
+FROM api_keys
+WHERE key_hash = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > now());
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = now()
+WHERE key_id = $1
+  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
+
+-- name: ListAPIKeys :many
+SELECT key_id, key_prefix, owner, scopes, rate_limit_per_minute, created_at, expires_at, last_used_at, revoked_at
+FROM api_keys
+ORDER BY created_at DESC;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = now()
+WHERE key_id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,22 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (
+    event_type,
+    user_id,
+    session_id,
+    ip_address,
+    user_agent,
+    details
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
+
+-- name: ListAuditEventsByUser :many
+SELECT event_type, ip_address, user_agent, details, created_at
+FROM audit_events
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: CountAuditEventsByUser :one
+SELECT count(*)
+FROM audit_events
+WHERE user_id = $1;
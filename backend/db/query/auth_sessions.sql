@@ -0,0 +1,76 @@
+-- name: CreateAuthSession :execrows
+INSERT INTO auth_sessions (
+    session_id,
+    user_id,
+    device_name,
+    user_agent,
+    ip_address,
+    refresh_token_hash,
+    expires_at
+)
+SELECT sqlc.arg(session_id), user_id, sqlc.arg(device_name), sqlc.arg(user_agent), sqlc.arg(ip_address), sqlc.arg(refresh_token_hash),
+    now() + make_interval(secs => sqlc.arg(ttl_seconds)::float8)
+FROM users
+WHERE user_id = sqlc.arg(user_id) AND locked_at IS NULL;
+
+-- name: RotateAuthSession :one
+WITH rotated AS (
+    UPDATE auth_sessions AS s
+    SET refresh_token_hash = sqlc.arg(new_refresh_token_hash),
+        expires_at = now() + make_interval(secs => sqlc.arg(ttl_seconds)::float8),
+        last_used_at = now(),
+        user_agent = sqlc.arg(user_agent),
+        ip_address = sqlc.arg(ip_address)
+    FROM users AS u
+    WHERE s.refresh_token_hash = ANY(sqlc.arg(refresh_token_hashes)::text[])
+      AND s.revoked_at IS NULL
+      AND s.expires_at > now()
+      AND u.user_id = s.user_id
+      AND u.locked_at IS NULL
+    RETURNING s.session_id, s.user_id, u.roles
+), history AS (
+    INSERT INTO refresh_token_rotations (token_hash, session_id)
+    SELECT sqlc.arg(refresh_token_hash), session_id FROM rotated
+)
+SELECT session_id, user_id, roles FROM rotated;
+
+-- name: GetRotatedRefreshToken :one
+SELECT r.session_id, s.user_id
+FROM refresh_token_rotations AS r
+JOIN auth_sessions AS s ON s.session_id = r.session_id
+WHERE r.token_hash = $1;
+
+-- name: RevokeAuthSessionFamily :execrows
+UPDATE auth_sessions
+SET revoked_at = now()
+WHERE session_id = $1 AND revoked_at IS NULL;
+
+-- name: ListAuthSessions :many
+SELECT session_id, device_name, user_agent, ip_address, created_at, last_used_at
+FROM auth_sessions
+WHERE user_id = $1
+  AND revoked_at IS NULL
+  AND expires_at > now()
+ORDER BY last_used_at DESC;
+
+-- name: RevokeAuthSession :execrows
+UPDATE auth_sessions
+SET revoked_at = now()
+WHERE session_id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeOtherAuthSessions :many
+UPDATE auth_sessions
+SET revoked_at = now()
+WHERE user_id = $1 AND session_id <> $2 AND revoked_at IS NULL
+RETURNING session_id;
+
+-- name: RevokeAllAuthSessions :many
+UPDATE auth_sessions
+SET revoked_at = now()
+WHERE user_id = $1 AND revoked_at IS NULL
+RETURNING session_id;
+
+-- name: DeleteStaleAuthSessions :execrows
+DELETE FROM auth_sessions
+WHERE expires_at < now() - interval '7 days'
+   OR revoked_at < now() - interval '7 days';
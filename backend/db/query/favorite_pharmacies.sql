@@ -0,0 +1,51 @@
+-- name: CountFavoritePharmacies :one
+SELECT count(*) FROM favorite_pharmacies
+WHERE user_id = $1;
+
+-- name: CreateFavoritePharmacy :execrows
+INSERT INTO favorite_pharmacies (user_id, location_id, label)
+SELECT $1, id, $2
+FROM locations
+WHERE id = sqlc.arg(location_id);
+
+-- name: GetFavoritePharmacy :one
+SELECT f.location_id, f.label, f.created_at,
+       l.text, l.pharmacy_number, l.phone, l.pharmacy_name,
+       ST_Y(l.location)::float8 AS latitude,
+       ST_X(l.location)::float8 AS longitude
+FROM favorite_pharmacies AS f
+JOIN locations AS l ON l.id = f.location_id
+WHERE f.user_id = $1 AND f.location_id = $2;
+
+-- name: ListFavoritePharmacies :many
+SELECT f.location_id, f.label, f.created_at,
+       l.text, l.pharmacy_number, l.phone, l.pharmacy_name,
+       ST_Y(l.location)::float8 AS latitude,
+       ST_X(l.location)::float8 AS longitude
+FROM favorite_pharmacies AS f
+JOIN locations AS l ON l.id = f.location_id
+WHERE f.user_id = $1
+ORDER BY f.created_at, f.location_id;
+
+-- name: UpdateFavoritePharmacyLabel :execrows
+UPDATE favorite_pharmacies
+SET label = $1
+WHERE user_id = $2 AND location_id = $3;
+
+-- name: DeleteFavoritePharmacy :execrows
+DELETE FROM favorite_pharmacies
+WHERE user_id = $1 AND location_id = $2;
+
+-- name: ListFavoritePharmacyKeys :many
+SELECT f.user_id, f.label, f.created_at, l.pharmacy_name, l.pharmacy_number, l.text
+FROM favorite_pharmacies AS f
+JOIN locations AS l ON l.id = f.location_id;
+
+-- name: RestoreFavoritePharmacy :execrows
+INSERT INTO favorite_pharmacies (user_id, location_id, label, created_at)
+SELECT $1, l.id, $2, $3
+FROM locations AS l
+WHERE l.pharmacy_name = $4 AND l.pharmacy_number = $5
+ORDER BY (l.text = $6) DESC, l.id
+LIMIT 1
+ON CONFLICT DO NOTHING;
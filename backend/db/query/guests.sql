@@ -0,0 +1,64 @@
+-- name: CreateGuest :exec
+WITH guest AS (
+    INSERT INTO users (user_id, email, password, roles)
+    VALUES ($1, $2, '', '{guest}')
+    RETURNING user_id
+)
+INSERT INTO guest_devices (device_key_hash, user_id, ip_address)
+SELECT $3, user_id, $4 FROM guest
+ON CONFLICT (device_key_hash) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+    ip_address = EXCLUDED.ip_address,
+    created_at = now();
+
+-- name: GetGuestByDeviceKey :one
+SELECT u.user_id, u.roles
+FROM guest_devices AS g
+JOIN users AS u ON u.user_id = g.user_id
+WHERE g.device_key_hash = $1 AND 'guest' = ANY (u.roles);
+
+-- name: CountRecentGuests :one
+SELECT count(*) FROM guest_devices
+WHERE ip_address = $1 AND created_at > now() - interval '1 hour';
+
+-- name: UpgradeGuest :execrows
+UPDATE users
+SET password = $2
+WHERE user_id = $1 AND 'guest' = ANY (roles) AND NOT email_verified;
+
+-- name: GetPendingGuestUpgrade :one
+-- The newest guest that asked for the address; only its code is checked.
+SELECT vc.user_id
+FROM verification_codes AS vc
+JOIN users AS u ON u.user_id = vc.user_id
+WHERE vc.new_email = $1
+  AND vc.purpose = $2
+  AND vc.used_at IS NULL
+  AND vc.attempts < vc.max_attempts
+  AND vc.expires_at > now()
+  AND 'guest' = ANY (u.roles)
+ORDER BY vc.created_at DESC
+LIMIT 1;
+
+-- name: ConfirmGuestUpgrade :one
+-- The device key stops being a credential once the account has a password.
+WITH dropped_device AS (
+    DELETE FROM guest_devices WHERE user_id = $1
+)
+UPDATE users
+SET email = $2, email_verified = true, roles = array_replace(roles, 'guest', 'user')
+WHERE user_id = $1 AND 'guest' = ANY (roles)
+RETURNING user_id, roles;
+
+-- name: DeleteInactiveGuests :execrows
+DELETE FROM users AS u
+WHERE 'guest' = ANY (u.roles)
+  AND NOT u.email_verified
+  AND NOT EXISTS (
+    SELECT 1 FROM guest_devices AS g
+    WHERE g.user_id = u.user_id AND g.created_at > now() - interval '30 days'
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM auth_sessions AS s
+    WHERE s.user_id = u.user_id AND s.expires_at > now() - interval '30 days'
+  );
@@ -17,4 +17,18 @@ SELECT EXISTS (
 -- name: CheckPharmacyByName :one
 SELECT EXISTS (
   SELECT 1 FROM locations WHERE pharmacy_name ILIKE $1
-);
\ No newline at end of file
+);
+
+-- name: InsertLocation :exec
+INSERT INTO locations (text, pharmacy_number, phone, pharmacy_name, location)
+VALUES ($1, $2, $3, $4, ST_SetSRID(ST_MakePoint(sqlc.arg(longitude)::float8, sqlc.arg(latitude)::float8), 4326));
+
+-- name: ListLocations :many
+SELECT id, text, pharmacy_number, phone, pharmacy_name,
+       ST_Y(location)::float8 AS latitude,
+       ST_X(location)::float8 AS longitude
+FROM locations
+ORDER BY id;
+
+-- name: DeleteAllLocations :exec
+DELETE FROM locations;
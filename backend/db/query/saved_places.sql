@@ -0,0 +1,34 @@
+-- name: CountSavedPlaces :one
+SELECT count(*) FROM saved_places
+WHERE user_id = $1;
+
+-- name: CreateSavedPlace :one
+INSERT INTO saved_places (place_id, user_id, name, location)
+VALUES ($1, $2, $3, ST_SetSRID(ST_MakePoint(sqlc.arg(longitude)::float8, sqlc.arg(latitude)::float8), 4326))
+RETURNING place_id, name,
+    ST_Y(location)::float8 AS latitude,
+    ST_X(location)::float8 AS longitude,
+    created_at;
+
+-- name: ListSavedPlaces :many
+SELECT place_id, name,
+       ST_Y(location)::float8 AS latitude,
+       ST_X(location)::float8 AS longitude,
+       created_at
+FROM saved_places
+WHERE user_id = $1
+ORDER BY created_at, name;
+
+-- name: UpdateSavedPlace :one
+UPDATE saved_places
+SET name = sqlc.arg(name),
+    location = ST_SetSRID(ST_MakePoint(sqlc.arg(longitude)::float8, sqlc.arg(latitude)::float8), 4326)
+WHERE place_id = sqlc.arg(place_id) AND user_id = sqlc.arg(user_id)
+RETURNING place_id, name,
+    ST_Y(location)::float8 AS latitude,
+    ST_X(location)::float8 AS longitude,
+    created_at;
+
+-- name: DeleteSavedPlace :execrows
+DELETE FROM saved_places
+WHERE place_id = $1 AND user_id = $2;
@@ -0,0 +1,14 @@
+-- name: CreateTokenRevocation :exec
+INSERT INTO token_revocations (token_key, expires_at)
+VALUES (sqlc.arg(token_key), now() + make_interval(secs => sqlc.arg(ttl_seconds)::float8))
+ON CONFLICT (token_key) DO UPDATE
+SET expires_at = GREATEST(token_revocations.expires_at, EXCLUDED.expires_at);
+
+-- name: ListActiveTokenRevocations :many
+SELECT token_key, EXTRACT(EPOCH FROM expires_at - now())::float8 AS ttl_seconds
+FROM token_revocations
+WHERE expires_at > now();
+
+-- name: DeleteExpiredTokenRevocations :execrows
+DELETE FROM token_revocations
+WHERE expires_at <= now();
@@ -2,56 +2,58 @@
 INSERT INTO users (
     user_id,
     email,
-    password,
-    code
+    password
 )VALUES(
-    $1,$2,$3,$4
+    $1,$2,$3
 );
 
--- name: VerifyRefreshToken :one
-WITH updated_user AS (
-    UPDATE users
-    SET refresh_token = NULL, expired_at = NULL 
-    WHERE refresh_token = $1 
-      AND expired_at IS NOT NULL 
-      AND $2 <= expired_at     
-    RETURNING user_id
-)
-SELECT user_id FROM updated_user;
-
--- name: UpdateRefreshToken :exec
-UPDATE users
-SET refresh_token = $1, expired_at = $2
-WHERE user_id = $3;
-
 -- name: GetUserAuthDetailsByEmail :one
-SELECT user_id, password, code
+SELECT user_id, password, email_verified, roles
 FROM users
 WHERE email = $1;
 
--- name: LogoutById :exec
-UPDATE users
-SET refresh_token = NULL, expired_at = NULL
-WHERE user_id = $1;
-
--- name: ConfirmEmailWithTokens :one
+-- name: ConfirmEmail :one
 UPDATE users
-SET code = NULL, refresh_token = $3, expired_at = $4
-WHERE email = $1 AND code = $2
-RETURNING user_id;
+SET email_verified = true, roles = array_replace(roles, 'guest', 'user')
+WHERE user_id = $1
+RETURNING user_id, roles;
 
 -- name: GetUserByEmail :one
-SELECT user_id
+SELECT user_id, email_verified
 FROM users
 WHERE email = $1;
 
--- name: UpdateCodeByUserId :exec
+-- name: ResetPassword :one
 UPDATE users
-SET code = $1
-WHERE user_id = $2;
+SET email_verified = true, password = $2, roles = array_replace(roles, 'guest', 'user')
+WHERE user_id = $1
+RETURNING user_id, roles;
 
--- name: ResetPasswordWithCodeAndSetTokens :one
+-- name: LockUser :execrows
 UPDATE users
-SET code = NULL, refresh_token = $3, expired_at = $4, password = $6
-WHERE user_id = $1 AND code = $2 AND email = $5
-RETURNING user_id;
\ No newline at end of file
+SET locked_at = now()
+WHERE user_id = $1 AND locked_at IS NULL;
+
+-- name: UnlockUser :execrows
+UPDATE users
+SET locked_at = NULL
+WHERE user_id = $1 AND locked_at IS NOT NULL;
+
+-- name: GetUserCredentials :one
+SELECT email, password
+FROM users
+WHERE user_id = $1;
+
+-- name: UpdateUserEmail :execrows
+UPDATE users
+SET email = $2
+WHERE user_id = $1;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE user_id = $1;
+
+-- name: GetUserAccount :one
+SELECT user_id, email, email_verified, roles, locked_at IS NOT NULL AS locked
+FROM users
+WHERE user_id = $1;
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- name: GetUserProfile :one
+SELECT user_id, display_name, language, default_city, answer_length, units, updated_at
+FROM user_profiles
+WHERE user_id = $1;
+
+-- name: UpsertUserProfile :one
+INSERT INTO user_profiles (
+    user_id,
+    display_name,
+    language,
+    default_city,
+    answer_length,
+    units
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (user_id) DO UPDATE
+SET display_name = EXCLUDED.display_name,
+    language = EXCLUDED.language,
+    default_city = EXCLUDED.default_city,
+    answer_length = EXCLUDED.answer_length,
+    units = EXCLUDED.units,
+    updated_at = now()
+RETURNING user_id, display_name, language, default_city, answer_length, units, updated_at;
@@ -0,0 +1,63 @@
+-- name: CreateVerificationCode :exec
+WITH invalidated AS (
+    UPDATE verification_codes
+    SET used_at = now()
+    WHERE user_id = sqlc.arg(user_id) AND purpose = sqlc.arg(purpose) AND used_at IS NULL
+)
+INSERT INTO verification_codes (
+    user_id,
+    purpose,
+    code_hash,
+    max_attempts,
+    expires_at,
+    new_email
+) VALUES (
+    sqlc.arg(user_id), sqlc.arg(purpose), sqlc.arg(code_hash), sqlc.arg(max_attempts), now() + make_interval(secs => sqlc.arg(ttl_seconds)::float8),
+    sqlc.narg(new_email)
+);
+
+-- name: ClaimVerificationCodeAttempt :one
+-- Counts an attempt against the latest active code before it is compared, so
+-- concurrent guesses cannot exceed max_attempts. The predicates are checked
+-- again on the locked row, after any concurrent claim has committed.
+UPDATE verification_codes
+SET attempts = attempts + 1
+WHERE id = (
+    SELECT vc.id
+    FROM verification_codes vc
+    WHERE vc.user_id = $1
+      AND vc.purpose = $2
+      AND vc.used_at IS NULL
+      AND vc.attempts < vc.max_attempts
+      AND vc.expires_at > now()
+    ORDER BY vc.created_at DESC
+    LIMIT 1
+    FOR UPDATE
+)
+  AND used_at IS NULL
+  AND attempts < max_attempts
+  AND expires_at > now()
+RETURNING id, code_hash, new_email, max_attempts - attempts AS remaining_attempts;
+
+-- name: ConsumeVerificationCode :execrows
+-- The attempt that matched was already counted by ClaimVerificationCodeAttempt,
+-- so it may be the last one allowed.
+UPDATE verification_codes
+SET used_at = now()
+WHERE id = $1
+  AND used_at IS NULL
+  AND attempts <= max_attempts
+  AND expires_at > now();
+
+-- name: GetVerificationCodeSendStats :one
+SELECT count(*) AS sent_last_hour,
+       COALESCE(EXTRACT(EPOCH FROM now() - max(created_at)), -1)::float8 AS seconds_since_last,
+       COALESCE(EXTRACT(EPOCH FROM now() - min(created_at)), -1)::float8 AS seconds_since_first
+FROM verification_codes
+WHERE user_id = $1
+  AND purpose = $2
+  AND created_at > now() - interval '1 hour';
+
+-- name: DeleteStaleVerificationCodes :execrows
+DELETE FROM verification_codes
+WHERE created_at < now() - interval '1 day';
@@ -48,6 +48,15 @@ func (q *Queries) CheckPharmacyByPhone(ctx context.Context, phone string) (bool,
 	return exists, err
 }
 
+const deleteAllLocations = `-- name: DeleteAllLocations :exec
+DELETE FROM locations
+`
+
+func (q *Queries) DeleteAllLocations(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteAllLocations)
+	return err
+}
+
 const getNearestPharmacy = `-- name: GetNearestPharmacy :many
 SELECT id, text, ST_AsText(location) AS location_wkt
 		FROM locations
@@ -85,3 +94,75 @@ func (q *Queries) GetNearestPharmacy(ctx context.Context, arg GetNearestPharmacy
 	}
 	return items, nil
 }
+
+const insertLocation = `-- name: InsertLocation :exec
+INSERT INTO locations (text, pharmacy_number, phone, pharmacy_name, location)
+VALUES ($1, $2, $3, $4, ST_SetSRID(ST_MakePoint($5::float8, $6::float8), 4326))
+`
+
+type InsertLocationParams struct {
+	Text           string  `json:"text"`
+	PharmacyNumber string  `json:"pharmacy_number"`
+	Phone          string  `json:"phone"`
+	PharmacyName   string  `json:"pharmacy_name"`
+	Longitude      float64 `json:"longitude"`
+	Latitude       float64 `json:"latitude"`
+}
+
+func (q *Queries) InsertLocation(ctx context.Context, arg InsertLocationParams) error {
+	_, err := q.db.Exec(ctx, insertLocation,
+		arg.Text,
+		arg.PharmacyNumber,
+		arg.Phone,
+		arg.PharmacyName,
+		arg.Longitude,
+		arg.Latitude,
+	)
+	return err
+}
+
+const listLocations = `-- name: ListLocations :many
+SELECT id, text, pharmacy_number, phone, pharmacy_name,
+       ST_Y(location)::float8 AS latitude,
+       ST_X(location)::float8 AS longitude
+FROM locations
+ORDER BY id
+`
+
+type ListLocationsRow struct {
+	ID             int32   `json:"id"`
+	Text           string  `json:"text"`
+	PharmacyNumber string  `json:"pharmacy_number"`
+	Phone          string  `json:"phone"`
+	PharmacyName   string  `json:"pharmacy_name"`
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+}
+
+func (q *Queries) ListLocations(ctx context.Context) ([]ListLocationsRow, error) {
+	rows, err := q.db.Query(ctx, listLocations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLocationsRow{}
+	for rows.Next() {
+		var i ListLocationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Text,
+			&i.PharmacyNumber,
+			&i.Phone,
+			&i.PharmacyName,
+			&i.Latitude,
+			&i.Longitude,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
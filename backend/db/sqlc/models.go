@@ -8,6 +8,58 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiKey struct {
+	KeyID              pgtype.UUID      `json:"key_id"`
+	KeyHash            string           `json:"key_hash"`
+	KeyPrefix          string           `json:"key_prefix"`
+	Owner              string           `json:"owner"`
+	Scopes             []string         `json:"scopes"`
+	RateLimitPerMinute int32            `json:"rate_limit_per_minute"`
+	CreatedBy          pgtype.UUID      `json:"created_by"`
+	CreatedAt          pgtype.Timestamp `json:"created_at"`
+	ExpiresAt          pgtype.Timestamp `json:"expires_at"`
+	LastUsedAt         pgtype.Timestamp `json:"last_used_at"`
+	RevokedAt          pgtype.Timestamp `json:"revoked_at"`
+}
+
+type AuditEvent struct {
+	ID        int64            `json:"id"`
+	EventType string           `json:"event_type"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	SessionID pgtype.UUID      `json:"session_id"`
+	IpAddress string           `json:"ip_address"`
+	UserAgent string           `json:"user_agent"`
+	Details   []byte           `json:"details"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type AuthSession struct {
+	SessionID        pgtype.UUID      `json:"session_id"`
+	UserID           pgtype.UUID      `json:"user_id"`
+	DeviceName       string           `json:"device_name"`
+	UserAgent        string           `json:"user_agent"`
+	IpAddress        string           `json:"ip_address"`
+	RefreshTokenHash string           `json:"refresh_token_hash"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	LastUsedAt       pgtype.Timestamp `json:"last_used_at"`
+	ExpiresAt        pgtype.Timestamp `json:"expires_at"`
+	RevokedAt        pgtype.Timestamp `json:"revoked_at"`
+}
+
+type FavoritePharmacy struct {
+	UserID     pgtype.UUID      `json:"user_id"`
+	LocationID int32            `json:"location_id"`
+	Label      string           `json:"label"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type GuestDevice struct {
+	DeviceKeyHash string           `json:"device_key_hash"`
+	UserID        pgtype.UUID      `json:"user_id"`
+	IpAddress     string           `json:"ip_address"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
 type Location struct {
 	ID             int32       `json:"id"`
 	Text           string      `json:"text"`
@@ -17,11 +69,54 @@ type Location struct {
 	Location       interface{} `json:"location"`
 }
 
+type RefreshTokenRotation struct {
+	TokenHash string           `json:"token_hash"`
+	SessionID pgtype.UUID      `json:"session_id"`
+	RotatedAt pgtype.Timestamp `json:"rotated_at"`
+}
+
+type SavedPlace struct {
+	PlaceID   pgtype.UUID      `json:"place_id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	Name      string           `json:"name"`
+	Location  interface{}      `json:"location"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type TokenRevocation struct {
+	TokenKey  string           `json:"token_key"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
 type User struct {
+	UserID        pgtype.UUID      `json:"user_id"`
+	Email         string           `json:"email"`
+	Password      string           `json:"password"`
+	Roles         []string         `json:"roles"`
+	EmailVerified bool             `json:"email_verified"`
+	LockedAt      pgtype.Timestamp `json:"locked_at"`
+}
+
+type UserProfile struct {
 	UserID       pgtype.UUID      `json:"user_id"`
-	Email        string           `json:"email"`
-	Password     string           `json:"password"`
-	Code         pgtype.Text      `json:"code"`
-	RefreshToken pgtype.Text      `json:"refresh_token"`
-	ExpiredAt    pgtype.Timestamp `json:"expired_at"`
+	DisplayName  string           `json:"display_name"`
+	Language     string           `json:"language"`
+	DefaultCity  string           `json:"default_city"`
+	AnswerLength string           `json:"answer_length"`
+	Units        string           `json:"units"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
+type VerificationCode struct {
+	ID          int64            `json:"id"`
+	UserID      pgtype.UUID      `json:"user_id"`
+	Purpose     string           `json:"purpose"`
+	CodeHash    string           `json:"code_hash"`
+	Attempts    int32            `json:"attempts"`
+	MaxAttempts int32            `json:"max_attempts"`
+	ExpiresAt   pgtype.Timestamp `json:"expires_at"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UsedAt      pgtype.Timestamp `json:"used_at"`
+	NewEmail    pgtype.Text      `json:"new_email"`
 }
@@ -11,40 +11,32 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const confirmEmailWithTokens = `-- name: ConfirmEmailWithTokens :one
+const confirmEmail = `-- name: ConfirmEmail :one
 UPDATE users
-SET code = NULL, refresh_token = $3, expired_at = $4
-WHERE email = $1 AND code = $2
-RETURNING user_id
+SET email_verified = true, roles = array_replace(roles, 'guest', 'user')
+WHERE user_id = $1
+RETURNING user_id, roles
 `
 
-type ConfirmEmailWithTokensParams struct {
-	Email        string           `json:"email"`
-	Code         pgtype.Text      `json:"code"`
-	RefreshToken pgtype.Text      `json:"refresh_token"`
-	ExpiredAt    pgtype.Timestamp `json:"expired_at"`
+type ConfirmEmailRow struct {
+	UserID pgtype.UUID `json:"user_id"`
+	Roles  []string    `json:"roles"`
 }
 
-func (q *Queries) ConfirmEmailWithTokens(ctx context.Context, arg ConfirmEmailWithTokensParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, confirmEmailWithTokens,
-		arg.Email,
-		arg.Code,
-		arg.RefreshToken,
-		arg.ExpiredAt,
-	)
-	var user_id pgtype.UUID
-	err := row.Scan(&user_id)
-	return user_id, err
+func (q *Queries) ConfirmEmail(ctx context.Context, userID pgtype.UUID) (ConfirmEmailRow, error) {
+	row := q.db.QueryRow(ctx, confirmEmail, userID)
+	var i ConfirmEmailRow
+	err := row.Scan(&i.UserID, &i.Roles)
+	return i, err
 }
 
 const createUser = `-- name: CreateUser :exec
 INSERT INTO users (
     user_id,
     email,
-    password,
-    code
+    password
 )VALUES(
-    $1,$2,$3,$4
+    $1,$2,$3
 )
 `
 
@@ -52,145 +44,181 @@ type CreateUserParams struct {
 	UserID   pgtype.UUID `json:"user_id"`
 	Email    string      `json:"email"`
 	Password string      `json:"password"`
-	Code     pgtype.Text `json:"code"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
-	_, err := q.db.Exec(ctx, createUser,
-		arg.UserID,
-		arg.Email,
-		arg.Password,
-		arg.Code,
-	)
+	_, err := q.db.Exec(ctx, createUser, arg.UserID, arg.Email, arg.Password)
 	return err
 }
 
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUser, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getUserAccount = `-- name: GetUserAccount :one
+SELECT user_id, email, email_verified, roles, locked_at IS NOT NULL AS locked
+FROM users
+WHERE user_id = $1
+`
+
+type GetUserAccountRow struct {
+	UserID        pgtype.UUID `json:"user_id"`
+	Email         string      `json:"email"`
+	EmailVerified bool        `json:"email_verified"`
+	Roles         []string    `json:"roles"`
+	Locked        bool        `json:"locked"`
+}
+
+func (q *Queries) GetUserAccount(ctx context.Context, userID pgtype.UUID) (GetUserAccountRow, error) {
+	row := q.db.QueryRow(ctx, getUserAccount, userID)
+	var i GetUserAccountRow
+	err := row.Scan(
+		&i.UserID,
+		&i.Email,
+		&i.EmailVerified,
+		&i.Roles,
+		&i.Locked,
+	)
+	return i, err
+}
+
 const getUserAuthDetailsByEmail = `-- name: GetUserAuthDetailsByEmail :one
-SELECT user_id, password, code
+SELECT user_id, password, email_verified, roles
 FROM users
 WHERE email = $1
 `
 
 type GetUserAuthDetailsByEmailRow struct {
-	UserID   pgtype.UUID `json:"user_id"`
-	Password string      `json:"password"`
-	Code     pgtype.Text `json:"code"`
+	UserID        pgtype.UUID `json:"user_id"`
+	Password      string      `json:"password"`
+	EmailVerified bool        `json:"email_verified"`
+	Roles         []string    `json:"roles"`
 }
 
 func (q *Queries) GetUserAuthDetailsByEmail(ctx context.Context, email string) (GetUserAuthDetailsByEmailRow, error) {
 	row := q.db.QueryRow(ctx, getUserAuthDetailsByEmail, email)
 	var i GetUserAuthDetailsByEmailRow
-	err := row.Scan(&i.UserID, &i.Password, &i.Code)
+	err := row.Scan(
+		&i.UserID,
+		&i.Password,
+		&i.EmailVerified,
+		&i.Roles,
+	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT user_id
+SELECT user_id, email_verified
 FROM users
 WHERE email = $1
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (pgtype.UUID, error) {
+type GetUserByEmailRow struct {
+	UserID        pgtype.UUID `json:"user_id"`
+	EmailVerified bool        `json:"email_verified"`
+}
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error) {
 	row := q.db.QueryRow(ctx, getUserByEmail, email)
-	var user_id pgtype.UUID
-	err := row.Scan(&user_id)
-	return user_id, err
+	var i GetUserByEmailRow
+	err := row.Scan(&i.UserID, &i.EmailVerified)
+	return i, err
 }
 
-const logoutById = `-- name: LogoutById :exec
-UPDATE users
-SET refresh_token = NULL, expired_at = NULL
+const getUserCredentials = `-- name: GetUserCredentials :one
+SELECT email, password
+FROM users
 WHERE user_id = $1
 `
 
-func (q *Queries) LogoutById(ctx context.Context, userID pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, logoutById, userID)
-	return err
+type GetUserCredentialsRow struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
 }
 
-const resetPasswordWithCodeAndSetTokens = `-- name: ResetPasswordWithCodeAndSetTokens :one
+func (q *Queries) GetUserCredentials(ctx context.Context, userID pgtype.UUID) (GetUserCredentialsRow, error) {
+	row := q.db.QueryRow(ctx, getUserCredentials, userID)
+	var i GetUserCredentialsRow
+	err := row.Scan(&i.Email, &i.Password)
+	return i, err
+}
+
+const lockUser = `-- name: LockUser :execrows
 UPDATE users
-SET code = NULL, refresh_token = $3, expired_at = $4, password = $6
-WHERE user_id = $1 AND code = $2 AND email = $5
-RETURNING user_id
+SET locked_at = now()
+WHERE user_id = $1 AND locked_at IS NULL
 `
 
-type ResetPasswordWithCodeAndSetTokensParams struct {
-	UserID       pgtype.UUID      `json:"user_id"`
-	Code         pgtype.Text      `json:"code"`
-	RefreshToken pgtype.Text      `json:"refresh_token"`
-	ExpiredAt    pgtype.Timestamp `json:"expired_at"`
-	Email        string           `json:"email"`
-	Password     string           `json:"password"`
-}
-
-func (q *Queries) ResetPasswordWithCodeAndSetTokens(ctx context.Context, arg ResetPasswordWithCodeAndSetTokensParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, resetPasswordWithCodeAndSetTokens,
-		arg.UserID,
-		arg.Code,
-		arg.RefreshToken,
-		arg.ExpiredAt,
-		arg.Email,
-		arg.Password,
-	)
-	var user_id pgtype.UUID
-	err := row.Scan(&user_id)
-	return user_id, err
+func (q *Queries) LockUser(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, lockUser, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const updateCodeByUserId = `-- name: UpdateCodeByUserId :exec
+const resetPassword = `-- name: ResetPassword :one
 UPDATE users
-SET code = $1
-WHERE user_id = $2
+SET email_verified = true, password = $2, roles = array_replace(roles, 'guest', 'user')
+WHERE user_id = $1
+RETURNING user_id, roles
 `
 
-type UpdateCodeByUserIdParams struct {
-	Code   pgtype.Text `json:"code"`
+type ResetPasswordParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	Password string      `json:"password"`
+}
+
+type ResetPasswordRow struct {
 	UserID pgtype.UUID `json:"user_id"`
+	Roles  []string    `json:"roles"`
 }
 
-func (q *Queries) UpdateCodeByUserId(ctx context.Context, arg UpdateCodeByUserIdParams) error {
-	_, err := q.db.Exec(ctx, updateCodeByUserId, arg.Code, arg.UserID)
-	return err
+func (q *Queries) ResetPassword(ctx context.Context, arg ResetPasswordParams) (ResetPasswordRow, error) {
+	row := q.db.QueryRow(ctx, resetPassword, arg.UserID, arg.Password)
+	var i ResetPasswordRow
+	err := row.Scan(&i.UserID, &i.Roles)
+	return i, err
 }
 
-const updateRefreshToken = `-- name: UpdateRefreshToken :exec
+const unlockUser = `-- name: UnlockUser :execrows
 UPDATE users
-SET refresh_token = $1, expired_at = $2
-WHERE user_id = $3
+SET locked_at = NULL
+WHERE user_id = $1 AND locked_at IS NOT NULL
 `
 
-type UpdateRefreshTokenParams struct {
-	RefreshToken pgtype.Text      `json:"refresh_token"`
-	ExpiredAt    pgtype.Timestamp `json:"expired_at"`
-	UserID       pgtype.UUID      `json:"user_id"`
+func (q *Queries) UnlockUser(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, unlockUser, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-func (q *Queries) UpdateRefreshToken(ctx context.Context, arg UpdateRefreshTokenParams) error {
-	_, err := q.db.Exec(ctx, updateRefreshToken, arg.RefreshToken, arg.ExpiredAt, arg.UserID)
-	return err
-}
-
-const verifyRefreshToken = `-- name: VerifyRefreshToken :one
-WITH updated_user AS (
-    UPDATE users
-    SET refresh_token = NULL, expired_at = NULL 
-    WHERE refresh_token = $1 
-      AND expired_at IS NOT NULL 
-      AND $2 <= expired_at     
-    RETURNING user_id
-)
-SELECT user_id FROM updated_user
+const updateUserEmail = `-- name: UpdateUserEmail :execrows
+UPDATE users
+SET email = $2
+WHERE user_id = $1
 `
 
-type VerifyRefreshTokenParams struct {
-	RefreshToken pgtype.Text      `json:"refresh_token"`
-	ExpiredAt    pgtype.Timestamp `json:"expired_at"`
+type UpdateUserEmailParams struct {
+	UserID pgtype.UUID `json:"user_id"`
+	Email  string      `json:"email"`
 }
 
-func (q *Queries) VerifyRefreshToken(ctx context.Context, arg VerifyRefreshTokenParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, verifyRefreshToken, arg.RefreshToken, arg.ExpiredAt)
-	var user_id pgtype.UUID
-	err := row.Scan(&user_id)
-	return user_id, err
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUserEmail, arg.UserID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	chromago "github.com/amikos-tech/chroma-go/pkg/api/v2"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres pings the pool.
+func Postgres(pool *pgxpool.Pool) Check {
+	return Check{
+		Name:     "postgres",
+		Required: true,
+		Run:      pool.Ping,
+	}
+}
+
+// Migrations checks that the schema is clean and at least at version want,
+// the latest migration this binary ships. A newer schema is accepted so old
+// instances stay ready while a deploy rolls out.
+func Migrations(m *migrate.Migrate, want uint) Check {
+	return Check{
+		Name:     "migrations",
+		Required: true,
+		Run: func(context.Context) error {
+			version, dirty, err := m.Version()
+			if err != nil {
+				return fmt.Errorf("read schema version: %w", err)
+			}
+			if dirty {
+				return fmt.Errorf("schema version %d is dirty", version)
+			}
+			if version < want {
+				return fmt.Errorf("schema version %d is older than %d", version, want)
+			}
+			return nil
+		},
+	}
+}
+
+// ChromaHeartbeat checks that Chroma answers.
+func ChromaHeartbeat(client chromago.Client) Check {
+	return Check{
+		Name:     "chroma",
+		Required: true,
+		Run:      client.Heartbeat,
+	}
+}
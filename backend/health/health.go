@@ -0,0 +1,139 @@
+// Package health serves the liveness and readiness probes of the server.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a check that does not set its own timeout.
+const DefaultTimeout = 2 * time.Second
+
+// Check probes one dependency.
+type Check struct {
+	Name string
+	// Required checks make the server unready when they fail; the others are
+	// only reported.
+	Required bool
+	// Timeout bounds Run; DefaultTimeout when zero.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Dependency statuses and overall statuses reported by Readiness.
+const (
+	StatusUp          = "up"
+	StatusDown        = "down"
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+)
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Status     string `json:"status"`
+	Required   bool   `json:"required"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report is the body of a readiness response.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Checker runs the readiness checks.
+type Checker struct {
+	checks []Check
+}
+
+// New returns a checker running checks on every readiness probe.
+func New(checks ...Check) *Checker {
+	return &Checker{checks: checks}
+}
+
+// Run runs all checks concurrently. The overall status is unavailable if a
+// required check failed and degraded if only optional ones did.
+func (c *Checker) Run(ctx context.Context) Report {
+	results := make([]CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := run(ctx, check)
+			results[i] = CheckResult{Status: StatusUp, Required: check.Required, DurationMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				// The error is logged rather than returned: the probes are
+				// unauthenticated and errors may name internal hosts.
+				slog.WarnContext(ctx, "Readiness check failed", "check", check.Name, "required", check.Required, "err", err)
+				results[i].Status = StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(c.checks))}
+	for i, check := range c.checks {
+		report.Checks[check.Name] = results[i]
+		if results[i].Status == StatusUp {
+			continue
+		}
+		if check.Required {
+			report.Status = StatusUnavailable
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run runs check within its timeout. Checks that ignore ctx are abandoned
+// when it expires rather than waited for.
+func run(ctx context.Context, check Check) error {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- check.Run(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Liveness reports that the process is up and serving. It checks no
+// dependency, so an outage of one does not get the container restarted.
+func (c *Checker) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, map[string]string{"status": StatusOK})
+}
+
+// Readiness runs the checks and answers 503 when a required one failed.
+func (c *Checker) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := c.Run(r.Context())
+	code := http.StatusOK
+	if report.Status == StatusUnavailable {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, r, code, report)
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding health response", "err", err)
+	}
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"strings"
+)
+
+// The types below mark personal data and secrets passed as log attributes.
+// Each masks its value when the record is written, so callers cannot forget
+// to:
+//
+//	slog.Info("code sent", "email", logging.Email(email))
+
+// Email is an email address. Only its first character and domain are kept.
+type Email string
+
+func (e Email) LogValue() slog.Value {
+	return slog.StringValue(maskEmail(string(e)))
+}
+
+// Secret is a password, token, verification code or key. It is never written.
+type Secret string
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// Coordinate is a latitude or longitude. It is rounded to two decimals,
+// about a kilometre, which still tells a city apart without locating a home.
+type Coordinate float64
+
+func (c Coordinate) LogValue() slog.Value {
+	return slog.Float64Value(math.Round(float64(c)*100) / 100)
+}
+
+// Text is free text that may contain personal data, such as a transcription
+// or the context handed to the LLM. Only its length is written.
+type Text string
+
+func (t Text) LogValue() slog.Value {
+	return slog.StringValue(fmt.Sprintf("[%d chars]", len([]rune(t))))
+}
+
+// URL is a connection string or URL. Its password is masked.
+type URL string
+
+func (u URL) LogValue() slog.Value {
+	parsed, err := url.Parse(string(u))
+	if err != nil {
+		return slog.StringValue(redacted)
+	}
+	return slog.StringValue(parsed.Redacted())
+}
+
+const redacted = "[REDACTED]"
+
+// secretKeys are attribute keys whose string values are always masked, as a
+// safety net for values logged without one of the types above.
+var secretKeys = map[string]bool{
+	"password":      true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"code":          true,
+	"device_key":    true,
+	"api_key":       true,
+	"authorization": true,
+	"secret":        true,
+}
+
+// redactAttr is the ReplaceAttr hook of the JSON handler.
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindString {
+		return a
+	}
+	key := strings.ToLower(a.Key)
+	switch {
+	case secretKeys[key]:
+		return slog.String(a.Key, redacted)
+	case key == "email":
+		return slog.String(a.Key, maskEmail(a.Value.String()))
+	}
+	return a
+}
+
+// maskEmail keeps the first character of the local part and the domain:
+// "jane.doe@example.com" becomes "j***@example.com".
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return redacted
+	}
+	first := []rune(local)
+	if len(first) == 0 {
+		return "***@" + domain
+	}
+	return string(first[0]) + "***@" + domain
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestRedactAttr(t *testing.T) {
+	tests := []struct {
+		name string
+		attr slog.Attr
+		want slog.Value
+	}{
+		{name: "secret key", attr: slog.String("password", "hunter2"), want: slog.StringValue(redacted)},
+		{name: "secret key is case-insensitive", attr: slog.String("Authorization", "Bearer abc"), want: slog.StringValue(redacted)},
+		{name: "verification code", attr: slog.String("code", "123456"), want: slog.StringValue(redacted)},
+		{name: "email", attr: slog.String("email", "jane.doe@example.com"), want: slog.StringValue("j***@example.com")},
+		{name: "other key", attr: slog.String("handler", "Login"), want: slog.StringValue("Login")},
+		{name: "non-string value", attr: slog.Int("code", 123456), want: slog.Int64Value(123456)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactAttr(nil, tt.attr)
+			if got.Key != tt.attr.Key {
+				t.Errorf("redactAttr() key = %q, want %q", got.Key, tt.attr.Key)
+			}
+			if !got.Value.Equal(tt.want) {
+				t.Errorf("redactAttr() value = %v, want %v", got.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{email: "jane.doe@example.com", want: "j***@example.com"},
+		{email: "j@example.com", want: "j***@example.com"},
+		{email: "юля@пример.рф", want: "ю***@пример.рф"},
+		{email: "@example.com", want: "***@example.com"},
+		{email: "not-an-email", want: redacted},
+		{email: "", want: redacted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := maskEmail(tt.email); got != tt.want {
+				t.Errorf("maskEmail(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Package mail delivers transactional email (confirmation and password reset
+// codes). Messages are rendered from embedded, localized templates and sent
+// through a Mailer: SMTP in production, a file or log sink in development.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"voice_assistant/util"
+)
+
+// Message is a rendered email with a plain-text and an HTML body.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends a rendered message.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewFromConfig returns the mailer selected by the configuration. In dev mode
+// messages are written to MAIL_DEV_DIR, so codes can be read without a mail
+// server. Otherwise SMTP must be configured.
+func NewFromConfig(config util.Config) (Mailer, error) {
+	if config.MailDevMode {
+		if config.MailDevDir == "" {
+			return nil, fmt.Errorf("MAIL_DEV_DIR is required when MAIL_DEV_MODE is enabled")
+		}
+		return NewFileMailer(config.MailDevDir, config.MailFrom)
+	}
+	if config.SMTPHost == "" || config.MailFrom == "" {
+		return nil, fmt.Errorf("SMTP_HOST and MAIL_FROM are required unless MAIL_DEV_MODE is enabled")
+	}
+	return NewSMTPMailer(SMTPConfig{
+		Host:     config.SMTPHost,
+		Port:     config.SMTPPort,
+		Username: config.SMTPUsername,
+		Password: config.SMTPPassword,
+		From:     config.MailFrom,
+	}), nil
+}
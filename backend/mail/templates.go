@@ -0,0 +1,96 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Template names.
+const (
+	TemplateConfirmEmail  = "confirm_email"
+	TemplatePasswordReset = "password_reset"
+	TemplateChangeEmail   = "change_email"
+)
+
+// DefaultLanguage is used when the client accepts none of the supported languages.
+const DefaultLanguage = "ru"
+
+// Languages lists the languages templates exist for.
+var Languages = []string{"ru", "en"}
+
+//go:embed templates
+var templateFS embed.FS
+
+// CodeData is the data passed to the code templates.
+type CodeData struct {
+	Code string
+}
+
+// Render builds a message for to from the named template in lang.
+// The text template defines "subject" and "text"; the HTML template is the body.
+func Render(name, lang, to string, data any) (Message, error) {
+	base := "templates/" + lang + "/" + name
+	txt, err := texttemplate.ParseFS(templateFS, base+".txt.tmpl")
+	if err != nil {
+		return Message{}, fmt.Errorf("parse %s text template: %w", name, err)
+	}
+	html, err := htmltemplate.ParseFS(templateFS, base+".html.tmpl")
+	if err != nil {
+		return Message{}, fmt.Errorf("parse %s html template: %w", name, err)
+	}
+
+	var subject, text, body bytes.Buffer
+	if err := txt.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	if err := txt.ExecuteTemplate(&text, "text", data); err != nil {
+		return Message{}, fmt.Errorf("render %s text: %w", name, err)
+	}
+	if err := html.Execute(&body, data); err != nil {
+		return Message{}, fmt.Errorf("render %s html: %w", name, err)
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimLeft(text.String(), "\n"),
+		HTML:    body.String(),
+	}, nil
+}
+
+// LanguageFromHeader picks the best supported language from an
+// Accept-Language header value, falling back to DefaultLanguage.
+func LanguageFromHeader(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		candidates = append(candidates, candidate{lang: primary, q: q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		for _, lang := range Languages {
+			if c.q > 0 && c.lang == lang {
+				return lang
+			}
+		}
+	}
+	return DefaultLanguage
+}
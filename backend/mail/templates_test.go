@@ -0,0 +1,82 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	type testCase struct {
+		name     string
+		template string
+		lang     string
+		data     CodeData
+		wantErr  bool
+		wantHTML string
+	}
+	tests := []testCase{
+		{name: "unknown template", template: "welcome", lang: "en", wantErr: true},
+		{name: "unknown language", template: TemplateConfirmEmail, lang: "de", wantErr: true},
+		{name: "code is escaped in HTML", template: TemplateConfirmEmail, lang: "en", data: CodeData{Code: "<b>1</b>"}, wantHTML: "&lt;b&gt;1&lt;/b&gt;"},
+	}
+	// Every template has to exist in every language.
+	for _, template := range []string{TemplateConfirmEmail, TemplatePasswordReset, TemplateChangeEmail} {
+		for _, lang := range Languages {
+			tests = append(tests, testCase{name: lang + "/" + template, template: template, lang: lang, data: CodeData{Code: "123456"}, wantHTML: "123456"})
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Render(tt.template, tt.lang, "user@example.com", tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Render succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if msg.To != "user@example.com" {
+				t.Errorf("To = %q", msg.To)
+			}
+			if msg.Subject == "" || strings.ContainsAny(msg.Subject, "\r\n") {
+				t.Errorf("Subject = %q, want a single non-empty line", msg.Subject)
+			}
+			if !strings.Contains(msg.Text, tt.data.Code) || strings.HasPrefix(msg.Text, "\n") {
+				t.Errorf("Text = %q, want the code without leading blank lines", msg.Text)
+			}
+			if !strings.Contains(msg.HTML, tt.wantHTML) {
+				t.Errorf("HTML does not contain %q:\n%s", tt.wantHTML, msg.HTML)
+			}
+		})
+	}
+}
+
+func TestLanguageFromHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: DefaultLanguage},
+		{header: "en", want: "en"},
+		{header: "en-US,en;q=0.9", want: "en"},
+		{header: "EN-gb", want: "en"},
+		{header: "ru-RU,ru;q=0.9,en;q=0.8", want: "ru"},
+		{header: "de-DE,de;q=0.9,en;q=0.5", want: "en"},
+		{header: "ru;q=0.5,en;q=0.8", want: "en"},
+		{header: "en;q=0,ru;q=0.1", want: "ru"},
+		{header: "en;q=0", want: DefaultLanguage},
+		{header: "fr, de", want: DefaultLanguage},
+		{header: "*", want: DefaultLanguage},
+		{header: "en;q=abc", want: "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := LanguageFromHeader(tt.header); got != tt.want {
+				t.Errorf("LanguageFromHeader(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,23 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"flag"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	"voice_assistant/api"
 	dbCon "voice_assistant/db/sqlc"
+	"voice_assistant/health"
+	"voice_assistant/logging"
+	"voice_assistant/mail"
+	"voice_assistant/metrics"
 	"voice_assistant/tools"
+	"voice_assistant/tracing"
 	"voice_assistant/util"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -19,6 +28,7 @@ import (
 	chromago "github.com/amikos-tech/chroma-go/pkg/api/v2"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
 	genaiembs "github.com/google/generative-ai-go/genai"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
@@ -30,10 +40,27 @@ import (
 var db *dbCon.Queries
 
 func main() {
+	printConfig := flag.Bool("print-config", false, "print the effective configuration with secrets redacted, then exit")
+	flag.Parse()
+
 	config, err := util.LoadConfig(".")
 	if err != nil {
 		log.Fatalf("could not loadconfig: %v", err)
 	}
+	if *printConfig {
+		if err := config.Print(os.Stdout); err != nil {
+			log.Fatalf("could not print config: %v", err)
+		}
+	}
+	if err := config.Validate(); err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+	if *printConfig {
+		return
+	}
+	if err := logging.Setup(config.LogLevel); err != nil {
+		log.Fatalf("could not set up logging: %v", err)
+	}
 
 	loader := openapi3.NewLoader()
 	loader.IsExternalRefsAllowed = true
@@ -52,13 +79,34 @@ func main() {
 
 	doc.Servers = nil
 
-	fmt.Println("API schema loaded and validated successfully...")
+	slog.Info("API schema loaded and validated successfully")
+
+	// Spans are only exported when an OTLP endpoint is configured
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    config.TracingOTLPEndpoint,
+		ServiceName: config.TracingServiceName,
+		SampleRatio: config.TracingSampleRatio,
+	})
+	if err != nil {
+		logging.Fatal("Failed to set up tracing", "err", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Error flushing spans", "err", err)
+		}
+	}()
+
+	slog.Info("Connecting to PostgreSQL database", "dsn", logging.URL(config.DbSource))
 
-	log.Printf("Connecting to PostgreSQL database at %s\n", config.DbSource)
+	poolConfig, err := pgxpool.ParseConfig(config.DbSource)
+	if err != nil {
+		logging.Fatal("Invalid database source", "err", err)
+	}
+	poolConfig.ConnConfig.Tracer = tracing.NewPgxTracer()
 
-	conn, err := pgxpool.New(context.Background(), config.DbSource)
+	conn, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
-		log.Fatalf("Could not connect to database: %v", err)
+		logging.Fatal("Could not connect to database", "err", err)
 	}
 	defer func(conn *pgxpool.Pool) {
 		conn.Close()
@@ -66,11 +114,11 @@ func main() {
 
 	db = dbCon.New(conn)
 
-	fmt.Println("PostgreSql connected successfully...")
+	slog.Info("PostgreSQL connected successfully")
 
 	driver, err := pgxv5.WithInstance(stdlib.OpenDBFromPool(conn), &pgxv5.Config{})
 	if err != nil {
-		log.Fatalf("Failed to create database driver: %v", err)
+		logging.Fatal("Failed to create database driver", "err", err)
 	}
 	// Run database migrations
 	m, err := migrate.NewWithDatabaseInstance(
@@ -79,32 +127,52 @@ func main() {
 		driver,
 	)
 	if err != nil {
-		log.Fatalf("Failed to create migration instance: %v", err)
+		logging.Fatal("Failed to create migration instance", "err", err)
 	}
 	if err = m.Up(); err != nil && err != migrate.ErrNoChange {
-		log.Fatalf("Failed to apply migrations: %v", err)
+		logging.Fatal("Failed to apply migrations", "err", err)
 	}
-	fmt.Println("Database migrations applied successfully...")
+	schemaVersion, _, err := m.Version()
+	if err != nil {
+		logging.Fatal("Failed to read schema version", "err", err)
+	}
+	slog.Info("Database migrations applied successfully", "version", schemaVersion)
 
 	// Create JWT authenticator
 	authenticator, err := tools.NewJwsAuthenticator(config)
 	if err != nil {
-		log.Fatalln("error creating authenticator:", err)
+		logging.Fatal("Error creating authenticator", "err", err)
 	}
 
+	// Denylist for access tokens revoked before they expire
+	revocations := tools.NewRevocationStore(db)
+	if err := revocations.Load(context.Background()); err != nil {
+		logging.Fatal("Failed to load token revocations", "err", err)
+	}
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	revocationsDone := make(chan struct{})
+	go func() {
+		defer close(revocationsDone)
+		revocations.Run(workersCtx, time.Minute)
+	}()
+
+	// API keys of partner and machine clients
+	apiKeys := tools.NewAPIKeyStore(db, authenticator)
+
 	// Standard HTTP server implementation
 	httpHandler := http.NewServeMux()
 
 	// Add middleware for OpenAPI validation
 	validatorOptions := &middleWare.Options{}
-	validatorOptions.Options.AuthenticationFunc = tools.NewAuthenticator(authenticator)
+	validatorOptions.Options.AuthenticationFunc = tools.NewAuthenticator(authenticator, revocations, apiKeys)
+	validatorOptions.ErrorHandlerWithOpts = tools.ValidationErrorHandler
 
 	// Establish database connection
 	ctx := context.Background()
-	geminiBaseURL := "https://google-proxy.hlofiys.xyz/"
 
+	// An empty GEMINI_BASE_URL talks to Google directly
 	httpOptions := genai.HTTPOptions{
-		BaseURL: geminiBaseURL,
+		BaseURL: config.GeminiBaseURL,
 	}
 
 	// Create GenAI client
@@ -113,32 +181,47 @@ func main() {
 		HTTPOptions: httpOptions,
 	})
 	if err != nil {
-		log.Fatalf("Failed to create GenAI client: %v", err)
+		logging.Fatal("Failed to create GenAI client", "err", err)
 	}
 
 	// Create GenAIEmbs client
-	genaiClientEmbs, err := genaiembs.NewClient(ctx,
-		option.WithAPIKey(config.GoogleAPIKey),
-		option.WithEndpoint(geminiBaseURL),
-	)
+	embsOptions := []option.ClientOption{option.WithAPIKey(config.GoogleAPIKey)}
+	if config.GeminiBaseURL != "" {
+		embsOptions = append(embsOptions, option.WithEndpoint(config.GeminiBaseURL))
+	}
+	genaiClientEmbs, err := genaiembs.NewClient(ctx, embsOptions...)
 	if err != nil {
-		log.Fatalf("Failed to create GenAI client: %v", err)
+		logging.Fatal("Failed to create GenAI client", "err", err)
 	}
+	defer func() {
+		if err := genaiClientEmbs.Close(); err != nil {
+			slog.Error("Error closing GenAI client", "err", err)
+		}
+	}()
 
 	// Create Chroma client
 	chromaClient, err := chromago.NewHTTPClient(chromago.WithBaseURL(config.ChromaBaseURL))
 	if err != nil {
-		log.Fatalf("Failed to create Chroma client: %v", err)
+		logging.Fatal("Failed to create Chroma client", "err", err)
 	}
 	// Close the client to release any resources such as local embedding functions
 	defer func() {
 		err = chromaClient.Close()
 		if err != nil {
-			log.Fatalf("Error closing client: %s \n", err)
+			slog.Error("Error closing Chroma client", "err", err)
 		}
 	}()
 
-	server := api.NewServer(*authenticator, genaiClient, genaiClientEmbs, chromaClient, config.ChromaCollectionName, db)
+	// Create mailer for confirmation and password reset codes
+	mailer, err := mail.NewFromConfig(config)
+	if err != nil {
+		logging.Fatal("Failed to create mailer", "err", err)
+	}
+	if config.MailDevMode {
+		slog.Info("Mail dev mode: messages are written locally instead of being sent", "dir", config.MailDevDir)
+	}
+
+	server := api.NewServer(*authenticator, genaiClient, genaiClientEmbs, chromaClient, config.ChromaCollectionName, conn, mailer, revocations, apiKeys)
 
 	openapi3filter.RegisterBodyDecoder("audio/mp4", openapi3filter.FileBodyDecoder)
 	openapi3filter.RegisterBodyDecoder("audio/x-m4a", openapi3filter.FileBodyDecoder)
@@ -148,13 +231,98 @@ func main() {
 
 	handler = validator(handler)
 
+	// Latency is labelled with the operationId of the route, resolved the same
+	// way the validator resolves it.
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		logging.Fatal("Failed to create OpenAPI router", "err", err)
+	}
+	handler = metrics.Middleware(router)(handler)
+	handler = tracing.Middleware(router)(handler)
+
+	checks := []health.Check{
+		health.Postgres(conn),
+		health.Migrations(m, schemaVersion),
+		health.ChromaHeartbeat(chromaClient),
+	}
+	checks = append(checks, server.ReadinessChecks(config.ReadinessCheckLLM)...)
+	for i := range checks {
+		checks[i].Timeout = config.ReadinessCheckTimeout
+	}
+	checker := health.New(checks...)
+
+	// The probes are not part of the API, so they are served next to the
+	// validator.
+	rootMux := http.NewServeMux()
+	rootMux.HandleFunc("GET /healthz", checker.Liveness)
+	rootMux.HandleFunc("GET /readyz", checker.Readiness)
+	rootMux.Handle("/", handler)
+	handler = logging.RequestID(rootMux)
+
 	// Configure the HTTP server
 	s := &http.Server{
-		Handler: handler,
-		Addr:    "0.0.0.0:8080",
+		Handler:           handler,
+		Addr:              config.ServerAddress,
+		ReadHeaderTimeout: config.HTTPReadHeaderTimeout,
+		ReadTimeout:       config.HTTPReadTimeout,
+		WriteTimeout:      config.HTTPWriteTimeout,
+		IdleTimeout:       config.HTTPIdleTimeout,
 	}
 
 	// Start the server
-	log.Printf("Starting server on %s", s.Addr)
-	log.Fatal(s.ListenAndServe())
+	stopCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	serverErr := make(chan error, 2)
+	go func() {
+		slog.Info("Starting server", "address", s.Addr)
+		serverErr <- s.ListenAndServe()
+	}()
+
+	// Metrics get their own listener so they can be kept off the public
+	// network; without METRICS_ADDRESS they are not served at all.
+	var metricsServer *http.Server
+	if config.MetricsAddress != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", metrics.Handler())
+		metricsServer = &http.Server{
+			Handler:           metricsMux,
+			Addr:              config.MetricsAddress,
+			ReadHeaderTimeout: config.HTTPReadHeaderTimeout,
+		}
+		go func() {
+			slog.Info("Starting metrics server", "address", metricsServer.Addr)
+			serverErr <- metricsServer.ListenAndServe()
+		}()
+	}
+	select {
+	case err := <-serverErr:
+		logging.Fatal("Server stopped", "err", err)
+	case <-stopCtx.Done():
+	}
+
+	// Stop accepting requests and let in-flight ones finish, then stop the
+	// background workers. The deferred calls then close the GenAI and Chroma
+	// clients, the database pool last, and flush the spans.
+	slog.Info("Shutting down", "timeout", config.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		slog.Error("In-flight requests did not finish in time", "err", err)
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Metrics server did not stop in time", "err", err)
+		}
+	}
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Background workers did not stop in time", "err", err)
+	}
+	stopWorkers()
+	select {
+	case <-revocationsDone:
+	case <-shutdownCtx.Done():
+	}
+	slog.Info("Server stopped")
 }
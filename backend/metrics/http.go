@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// unmatchedOperation labels requests that match no operation in api.yaml, so
+// scanners probing random paths cannot create new series.
+const unmatchedOperation = "unmatched"
+
+// Middleware records the latency and status of every request, labelled with
+// the operationId router resolves it to.
+func Middleware(router routers.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			operation := unmatchedOperation
+			if route, _, err := router.FindRoute(r); err == nil && route.Operation != nil && route.Operation.OperationID != "" {
+				operation = route.Operation.OperationID
+			}
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			start := time.Now()
+			next.ServeHTTP(rec, r)
+			httpRequestDuration.
+				WithLabelValues(operation, r.Method, strconv.Itoa(rec.status)).
+				Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// statusRecorder remembers the status code written by the handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,132 @@
+// Package metrics defines the Prometheus metrics of the server and serves
+// them on /metrics of the separate METRICS_ADDRESS listener.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "voice_assistant"
+
+// Outcome labels shared by the auth metrics.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Login methods, the `method` label of LoginAttempt.
+const (
+	LoginPassword = "password"
+	LoginGuest    = "guest"
+	LoginRefresh  = "refresh"
+)
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by OpenAPI operation and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "method", "status"})
+
+	// LLM calls take seconds, so the default buckets would lump them together.
+	llmRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_request_duration_seconds",
+		Help:      "Latency of LLM calls by chat round.",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 3, 5, 8, 13, 20, 30},
+	}, []string{"round"})
+
+	llmRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_request_errors_total",
+		Help:      "Failed LLM calls by chat round.",
+	}, []string{"round"})
+
+	chromaQueries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chroma_queries_total",
+		Help:      "Chroma queries by kind (primary or fallback) and outcome.",
+	}, []string{"query", "outcome"})
+
+	toolSelections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chat_tool_selections_total",
+		Help:      "Tools chosen by the LLM in the first chat round; \"none\" when it answered directly.",
+	}, []string{"tool"})
+
+	answerValidationFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chat_answer_validation_failures_total",
+		Help:      "Assistant answers rejected because the pharmacy they name is not in the database.",
+	})
+
+	loginAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_login_attempts_total",
+		Help:      "Login attempts by method and outcome.",
+	}, []string{"method", "outcome"})
+
+	registrations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_registrations_total",
+		Help:      "Registration attempts by outcome.",
+	}, []string{"outcome"})
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveLLMCall records one LLM call of the given chat round ("1" or "2").
+func ObserveLLMCall(round string, d time.Duration, err error) {
+	llmRequestDuration.WithLabelValues(round).Observe(d.Seconds())
+	if err != nil {
+		llmRequestErrors.WithLabelValues(round).Inc()
+	}
+}
+
+// ObserveChromaQuery records one Chroma query; query is "primary" or "fallback".
+func ObserveChromaQuery(query string, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	}
+	chromaQueries.WithLabelValues(query, outcome).Inc()
+}
+
+// ToolSelected records the tool the LLM chose in the first round.
+func ToolSelected(tool string) {
+	toolSelections.WithLabelValues(tool).Inc()
+}
+
+// AnswerRejected records an answer rejected by validation.
+func AnswerRejected() {
+	answerValidationFailures.Inc()
+}
+
+// LoginAttempt records the outcome of a login, guest sign-in or token refresh.
+func LoginAttempt(method, outcome string) {
+	loginAttempts.WithLabelValues(method, outcome).Inc()
+}
+
+// RegistrationAttempt records the outcome of a registration.
+func RegistrationAttempt(outcome string) {
+	registrations.WithLabelValues(outcome).Inc()
+}
+
+// RegisterChatSessions exports the number of in-memory chat sessions, read
+// through count on every scrape.
+func RegisterChatSessions(count func() int) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chat_sessions",
+		Help:      "Chat sessions currently held in memory.",
+	}, func() float64 { return float64(count()) })
+}
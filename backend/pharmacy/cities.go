@@ -0,0 +1,40 @@
+package pharmacy
+
+// Polygon is a closed ring of points; the last point connects back to the first.
+type Polygon []Coordinates
+
+// Contains reports whether c lies inside the polygon (even-odd rule).
+func (p Polygon) Contains(c Coordinates) bool {
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Latitude > c.Latitude) != (b.Latitude > c.Latitude) &&
+			c.Longitude < (b.Longitude-a.Longitude)*(c.Latitude-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// CityBoundaries holds coarse outlines of the cities that appear in the dataset,
+// generous enough to include the whole city but tight enough to catch
+// addresses geocoded into another settlement. Add a city here before loading
+// pharmacies from it; records in unknown cities fail validation.
+var CityBoundaries = map[string]Polygon{
+	"Минск": {
+		{Latitude: 53.985, Longitude: 27.550},
+		{Latitude: 53.965, Longitude: 27.700},
+		{Latitude: 53.905, Longitude: 27.775},
+		{Latitude: 53.825, Longitude: 27.720},
+		{Latitude: 53.815, Longitude: 27.550},
+		{Latitude: 53.835, Longitude: 27.420},
+		{Latitude: 53.895, Longitude: 27.370},
+		{Latitude: 53.960, Longitude: 27.430},
+	},
+	"Фаниполь": {
+		{Latitude: 53.765, Longitude: 27.300},
+		{Latitude: 53.765, Longitude: 27.365},
+		{Latitude: 53.730, Longitude: 27.365},
+		{Latitude: 53.730, Longitude: 27.300},
+	},
+}
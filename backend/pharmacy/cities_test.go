@@ -0,0 +1,49 @@
+package pharmacy
+
+import "testing"
+
+func TestPolygonContains(t *testing.T) {
+	square := Polygon{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 10},
+		{Latitude: 10, Longitude: 10},
+		{Latitude: 10, Longitude: 0},
+	}
+	// A U shape: the notch between the arms is outside.
+	u := Polygon{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 9},
+		{Latitude: 9, Longitude: 9},
+		{Latitude: 9, Longitude: 6},
+		{Latitude: 3, Longitude: 6},
+		{Latitude: 3, Longitude: 3},
+		{Latitude: 9, Longitude: 3},
+		{Latitude: 9, Longitude: 0},
+	}
+
+	tests := []struct {
+		name    string
+		polygon Polygon
+		point   Coordinates
+		want    bool
+	}{
+		{name: "centre of square", polygon: square, point: Coordinates{Latitude: 5, Longitude: 5}, want: true},
+		{name: "north of square", polygon: square, point: Coordinates{Latitude: 11, Longitude: 5}, want: false},
+		{name: "west of square", polygon: square, point: Coordinates{Latitude: 5, Longitude: -1}, want: false},
+		{name: "level with a vertex, outside", polygon: square, point: Coordinates{Latitude: 10, Longitude: 20}, want: false},
+		{name: "arm of U", polygon: u, point: Coordinates{Latitude: 6, Longitude: 1.5}, want: true},
+		{name: "base of U", polygon: u, point: Coordinates{Latitude: 1.5, Longitude: 4.5}, want: true},
+		{name: "notch of U", polygon: u, point: Coordinates{Latitude: 6, Longitude: 4.5}, want: false},
+		{name: "empty polygon", polygon: nil, point: Coordinates{Latitude: 5, Longitude: 5}, want: false},
+		{name: "Minsk centre", polygon: CityBoundaries["Минск"], point: Coordinates{Latitude: 53.9023, Longitude: 27.5619}, want: true},
+		{name: "Fanipol is not in Minsk", polygon: CityBoundaries["Минск"], point: Coordinates{Latitude: 53.7497, Longitude: 27.3336}, want: false},
+		{name: "Fanipol centre", polygon: CityBoundaries["Фаниполь"], point: Coordinates{Latitude: 53.7497, Longitude: 27.3336}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.polygon.Contains(tt.point); got != tt.want {
+				t.Errorf("Contains(%+v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
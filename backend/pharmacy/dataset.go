@@ -0,0 +1,227 @@
+package pharmacy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Metadata keys used in data.jsonl and in the Chroma collection.
+const (
+	KeyOriginalID     = "original_id"
+	KeyPharmacyName   = "pharmacy_name"
+	KeyPharmacyNumber = "pharmacy_number"
+	KeyCity           = "city"
+	KeyStreet         = "street"
+	KeyHouseNumber    = "house_number"
+	KeyFullAddress    = "full_address_computed"
+	KeyPhoneNumber    = "phone_number"
+)
+
+// MetadataKeys lists every metadata key in the order it appears in data.jsonl.
+var MetadataKeys = []string{
+	KeyOriginalID,
+	KeyPharmacyName,
+	KeyPharmacyNumber,
+	KeyCity,
+	KeyStreet,
+	KeyHouseNumber,
+	KeyFullAddress,
+	KeyPhoneNumber,
+}
+
+// Record is one line of data.jsonl.
+type Record struct {
+	Text     string         `json:"text"`
+	Metadata map[string]any `json:"metadata"`
+
+	// Line is the 1-based line number the record was read from.
+	Line int `json:"-"`
+}
+
+// Field returns a metadata value as a string; missing and null values yield "".
+func (r Record) Field(key string) string {
+	v, ok := r.Metadata[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// DocumentID is the Chroma document ID derived from original_id, so that
+// re-uploading the same record overwrites it instead of adding a copy.
+// It returns "" when the record has no original_id.
+func (r Record) DocumentID() string {
+	id := r.Field(KeyOriginalID)
+	if id == "" {
+		return ""
+	}
+	return "pharmacy-" + id
+}
+
+// ContentHash identifies the text and cleaned metadata of a record,
+// so an upload can tell whether a stored document is up to date.
+func (r Record) ContentHash() string {
+	h := sha256.New()
+	h.Write([]byte(r.Text))
+	for _, key := range MetadataKeys {
+		fmt.Fprintf(h, "\x00%s=%s", key, r.Field(key))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Address is the human-readable address used for geocoding.
+func (r Record) Address() string {
+	return fmt.Sprintf("Беларусь, %s, %s, %s", r.Field(KeyCity), r.Field(KeyStreet), r.Field(KeyHouseNumber))
+}
+
+// Coordinates is a WGS84 point.
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// CoordinateRecord is one line of output_with_coordinates.jsonl.
+// Coordinates is nil when the address could not be geocoded.
+type CoordinateRecord struct {
+	Text        string       `json:"text"`
+	Coordinates *Coordinates `json:"coordinates"`
+}
+
+// CleanMetadata replaces nil values with empty strings, since Chroma rejects null metadata.
+func CleanMetadata(metadata map[string]any) map[string]any {
+	cleaned := make(map[string]any, len(metadata))
+	for key, value := range metadata {
+		if value == nil {
+			cleaned[key] = ""
+		} else {
+			cleaned[key] = value
+		}
+	}
+	return cleaned
+}
+
+// LineError describes a line of a JSONL file that could not be decoded.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// ReadRecords reads data.jsonl. Blank lines are skipped; lines that fail to
+// decode are returned separately so callers can decide whether they are fatal.
+func ReadRecords(path string) ([]Record, []LineError, error) {
+	var records []Record
+	lineErrs, err := scanJSONL(path, func(line int, raw []byte) error {
+		var rec Record
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return err
+		}
+		rec.Line = line
+		records = append(records, rec)
+		return nil
+	})
+	return records, lineErrs, err
+}
+
+// ReadCoordinates reads output_with_coordinates.jsonl keyed by record text.
+func ReadCoordinates(path string) (map[string]*Coordinates, []LineError, error) {
+	coords := make(map[string]*Coordinates)
+	lineErrs, err := scanJSONL(path, func(_ int, raw []byte) error {
+		var rec CoordinateRecord
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return err
+		}
+		coords[rec.Text] = rec.Coordinates
+		return nil
+	})
+	return coords, lineErrs, err
+}
+
+func scanJSONL(path string, decode func(line int, raw []byte) error) ([]LineError, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var lineErrs []LineError
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		if err := decode(line, raw); err != nil {
+			lineErrs = append(lineErrs, LineError{Line: line, Err: err})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return lineErrs, fmt.Errorf("read %s: %w", path, err)
+	}
+	return lineErrs, nil
+}
+
+// WriteJSONL writes one JSON document per line, replacing path atomically.
+func WriteJSONL[T any](path string, items []T) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file for %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := EncodeJSONL(tmp, items); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", path, err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// WriteFileAtomic replaces path with data via a temp file and rename.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file for %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", path, err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// EncodeJSONL writes items as JSON lines without escaping non-ASCII text.
+func EncodeJSONL[T any](w io.Writer, items []T) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
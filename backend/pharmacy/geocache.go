@@ -0,0 +1,79 @@
+package pharmacy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileCacheEntry is one cached lookup; Coordinates is nil for a confirmed miss.
+type fileCacheEntry struct {
+	Coordinates *Coordinates `json:"coordinates"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// FileCache is a GeocodeCache persisted as a JSON object keyed by address.
+// The file is rewritten atomically after every Put, so an interrupted run
+// keeps everything looked up so far.
+type FileCache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]fileCacheEntry
+}
+
+// OpenFileCache loads the cache at path; a missing file yields an empty cache.
+func OpenFileCache(path string) (*FileCache, error) {
+	c := &FileCache{path: path, entries: make(map[string]fileCacheEntry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read geocode cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("decode geocode cache %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *FileCache) Get(address string) (Coordinates, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[address]
+	if !ok {
+		return Coordinates{}, false, false
+	}
+	if e.Coordinates == nil {
+		return Coordinates{}, false, true
+	}
+	return *e.Coordinates, true, true
+}
+
+func (c *FileCache) Put(address string, coords Coordinates, found bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := fileCacheEntry{UpdatedAt: time.Now().UTC()}
+	if found {
+		e.Coordinates = &coords
+	}
+	c.entries[address] = e
+
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(c.path, data)
+}
+
+// Len returns the number of cached addresses.
+func (c *FileCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,77 @@
+package pharmacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned by a Geocoder when the address has no match.
+var ErrNotFound = errors.New("address not found")
+
+// Geocoder resolves the coordinates of a dataset record.
+type Geocoder interface {
+	Geocode(ctx context.Context, rec Record) (Coordinates, error)
+}
+
+// GeocodeCache stores geocoding results keyed by address, including misses,
+// so repeated runs give the same answer without asking the upstream service.
+type GeocodeCache interface {
+	// Get returns the cached result; ok is false when the address was never looked up.
+	// A cached miss is returned as found == false.
+	Get(address string) (c Coordinates, found bool, ok bool)
+	Put(address string, c Coordinates, found bool) error
+}
+
+// CachedGeocoder consults Cache before Next and records every answer from Next.
+type CachedGeocoder struct {
+	Next  Geocoder
+	Cache GeocodeCache
+}
+
+func (g *CachedGeocoder) Geocode(ctx context.Context, rec Record) (Coordinates, error) {
+	address := rec.Address()
+	if c, found, ok := g.Cache.Get(address); ok {
+		if !found {
+			return Coordinates{}, fmt.Errorf("%w (cached): %s", ErrNotFound, address)
+		}
+		return c, nil
+	}
+
+	c, err := g.Next.Geocode(ctx, rec)
+	switch {
+	case err == nil:
+		if err := g.Cache.Put(address, c, true); err != nil {
+			return Coordinates{}, fmt.Errorf("cache geocode result: %w", err)
+		}
+		return c, nil
+	case errors.Is(err, ErrNotFound):
+		if err := g.Cache.Put(address, Coordinates{}, false); err != nil {
+			return Coordinates{}, fmt.Errorf("cache geocode miss: %w", err)
+		}
+	}
+	return Coordinates{}, err
+}
+
+// OfflineGeocoder answers from a coordinates file (output_with_coordinates.jsonl)
+// and never touches the network.
+type OfflineGeocoder struct {
+	coords map[string]*Coordinates
+}
+
+// NewOfflineGeocoder loads the coordinates file at path.
+func NewOfflineGeocoder(path string) (*OfflineGeocoder, []LineError, error) {
+	coords, lineErrs, err := ReadCoordinates(path)
+	if err != nil {
+		return nil, lineErrs, err
+	}
+	return &OfflineGeocoder{coords: coords}, lineErrs, nil
+}
+
+func (g *OfflineGeocoder) Geocode(_ context.Context, rec Record) (Coordinates, error) {
+	c := g.coords[rec.Text]
+	if c == nil {
+		return Coordinates{}, fmt.Errorf("%w (offline): %s", ErrNotFound, rec.Address())
+	}
+	return *c, nil
+}
@@ -0,0 +1,137 @@
+package pharmacy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const nominatimUserAgent = "pharmacy-geocoder/1.0"
+
+// NominatimInterval keeps us within the public Nominatim usage policy (max 1 req/s).
+const NominatimInterval = 1100 * time.Millisecond
+
+type nominatimResponse []struct {
+	Lat string `json:"lat"`
+	Lon string `json:"lon"`
+}
+
+// NominatimGeocoder queries a Nominatim-compatible /search endpoint.
+// Requests are spaced at least MinInterval apart, and rate limiting or
+// server errors are retried with backoff.
+type NominatimGeocoder struct {
+	BaseURL     string
+	Client      *http.Client
+	MinInterval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+func NewNominatimGeocoder(baseURL string) *NominatimGeocoder {
+	return &NominatimGeocoder{
+		BaseURL:     baseURL,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		MinInterval: NominatimInterval,
+	}
+}
+
+// nominatimStatusError is returned for non-200 responses.
+type nominatimStatusError struct {
+	status int
+	text   string
+}
+
+func (e *nominatimStatusError) Error() string {
+	return "unexpected status " + e.text
+}
+
+func (g *NominatimGeocoder) Geocode(ctx context.Context, rec Record) (Coordinates, error) {
+	address := rec.Address()
+	var c Coordinates
+	err := Retry(ctx, DefaultBackoff, nominatimTransient, func() error {
+		if err := g.wait(ctx); err != nil {
+			return err
+		}
+		var err error
+		c, err = g.search(ctx, address)
+		return err
+	})
+	if err != nil {
+		return Coordinates{}, fmt.Errorf("geocode %q: %w", address, err)
+	}
+	return c, nil
+}
+
+// wait blocks until MinInterval has passed since the previous request.
+func (g *NominatimGeocoder) wait(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if d := g.MinInterval - time.Since(g.last); d > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+	g.last = time.Now()
+	return nil
+}
+
+func (g *NominatimGeocoder) search(ctx context.Context, address string) (Coordinates, error) {
+	params := url.Values{}
+	params.Set("q", address)
+	params.Set("format", "json")
+	params.Set("limit", "1")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.BaseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return Coordinates{}, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("User-Agent", nominatimUserAgent)
+
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return Coordinates{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Coordinates{}, &nominatimStatusError{status: resp.StatusCode, text: resp.Status}
+	}
+
+	var result nominatimResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Coordinates{}, fmt.Errorf("decode response: %w", err)
+	}
+	if len(result) == 0 {
+		return Coordinates{}, ErrNotFound
+	}
+
+	lat, err := strconv.ParseFloat(result[0].Lat, 64)
+	if err != nil {
+		return Coordinates{}, fmt.Errorf("parse latitude %q: %w", result[0].Lat, err)
+	}
+	lon, err := strconv.ParseFloat(result[0].Lon, 64)
+	if err != nil {
+		return Coordinates{}, fmt.Errorf("parse longitude %q: %w", result[0].Lon, err)
+	}
+	return Coordinates{Latitude: lat, Longitude: lon}, nil
+}
+
+// nominatimTransient retries transport failures, 429 and 5xx responses.
+func nominatimTransient(err error) bool {
+	if err == ErrNotFound || err == context.Canceled {
+		return false
+	}
+	if se, ok := err.(*nominatimStatusError); ok {
+		return se.status == http.StatusTooManyRequests || se.status >= http.StatusInternalServerError
+	}
+	_, isURLErr := err.(*url.Error)
+	return isURLErr
+}
@@ -0,0 +1,78 @@
+package pharmacy
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Progress prints "done/total" lines for long-running data operations.
+// A line is printed at most once per interval, plus once when Finish is called.
+type Progress struct {
+	out      io.Writer
+	label    string
+	total    int
+	done     int
+	failed   int
+	skipped  int
+	started  time.Time
+	lastLine time.Time
+	interval time.Duration
+}
+
+func NewProgress(out io.Writer, label string, total int) *Progress {
+	now := time.Now()
+	return &Progress{
+		out:      out,
+		label:    label,
+		total:    total,
+		started:  now,
+		lastLine: now,
+		interval: 2 * time.Second,
+	}
+}
+
+// Add records n processed items.
+func (p *Progress) Add(n int) {
+	p.done += n
+	p.maybePrint()
+}
+
+// Fail records n items that could not be processed.
+func (p *Progress) Fail(n int) {
+	p.done += n
+	p.failed += n
+	p.maybePrint()
+}
+
+// Skip records n items that did not need processing.
+func (p *Progress) Skip(n int) {
+	p.done += n
+	p.skipped += n
+	p.maybePrint()
+}
+
+func (p *Progress) Failed() int {
+	return p.failed
+}
+
+// Finish prints the final summary line.
+func (p *Progress) Finish() {
+	p.print()
+}
+
+func (p *Progress) maybePrint() {
+	if time.Since(p.lastLine) >= p.interval {
+		p.print()
+	}
+}
+
+func (p *Progress) print() {
+	p.lastLine = time.Now()
+	percent := 100.0
+	if p.total > 0 {
+		percent = float64(p.done) * 100 / float64(p.total)
+	}
+	fmt.Fprintf(p.out, "[%s] %d/%d (%.0f%%) failed=%d skipped=%d elapsed=%s\n",
+		p.label, p.done, p.total, percent, p.failed, p.skipped, time.Since(p.started).Round(time.Second))
+}
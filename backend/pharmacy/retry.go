@@ -0,0 +1,42 @@
+package pharmacy
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff configures Retry.
+type Backoff struct {
+	Attempts  int           // total attempts, including the first one
+	BaseDelay time.Duration // delay before the second attempt; doubled after each failure
+	MaxDelay  time.Duration
+}
+
+// DefaultBackoff retries for roughly a minute before giving up.
+var DefaultBackoff = Backoff{Attempts: 6, BaseDelay: 2 * time.Second, MaxDelay: 30 * time.Second}
+
+// Retry calls fn until it succeeds, returns an error for which transient
+// reports false, the attempts are exhausted, or ctx is done.
+// Delays are jittered by ±20% so parallel runs do not retry in lockstep.
+func Retry(ctx context.Context, b Backoff, transient func(error) bool, fn func() error) error {
+	delay := b.BaseDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= b.Attempts || !transient(err) {
+			return err
+		}
+
+		jitter := time.Duration(float64(delay) * (rand.Float64()*0.4 - 0.2))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(delay + jitter):
+		}
+		delay = min(delay*2, b.MaxDelay)
+	}
+}
@@ -0,0 +1,50 @@
+package pharmacy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	errTransient := errors.New("transient")
+	errPermanent := errors.New("permanent")
+	isTransient := func(err error) bool { return errors.Is(err, errTransient) }
+	backoff := Backoff{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		results   []error // returned by successive calls; later calls succeed
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "first call succeeds", ctx: context.Background(), wantCalls: 1},
+		{name: "succeeds after transient errors", ctx: context.Background(), results: []error{errTransient, errTransient}, wantCalls: 3},
+		{name: "attempts exhausted", ctx: context.Background(), results: []error{errTransient, errTransient, errTransient, errTransient}, wantCalls: 3, wantErr: errTransient},
+		{name: "permanent error is not retried", ctx: context.Background(), results: []error{errPermanent}, wantCalls: 1, wantErr: errPermanent},
+		{name: "transient then permanent", ctx: context.Background(), results: []error{errTransient, errPermanent}, wantCalls: 2, wantErr: errPermanent},
+		{name: "context done while waiting", ctx: cancelled, results: []error{errTransient}, wantCalls: 1, wantErr: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Retry(tt.ctx, backoff, isTransient, func() error {
+				calls++
+				if calls <= len(tt.results) {
+					return tt.results[calls-1]
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("Retry error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fn called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package pharmacy
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// Issue is a single problem found in the dataset.
+type Issue struct {
+	Line    int    // line in the dataset file, 0 if not tied to a line
+	Check   string // short identifier of the check that failed
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("line %d: [%s] %s", i.Line, i.Check, i.Message)
+	}
+	return fmt.Sprintf("[%s] %s", i.Check, i.Message)
+}
+
+// requiredKeys must be present and non-empty for a record to be usable.
+var requiredKeys = []string{KeyOriginalID, KeyPharmacyName, KeyCity, KeyStreet, KeyPhoneNumber}
+
+// phonePattern matches Belarusian numbers in the 80XX… form used by the dataset
+// or in international +375XX… form, with a known operator or Minsk city code.
+var phonePattern = regexp.MustCompile(`^(80|\+375)(17|25|29|33|44)\d{7}$`)
+
+// Validate checks the dataset and its coordinates for problems that would
+// break an import. coords may be nil to skip the coordinate checks.
+func Validate(records []Record, coords map[string]*Coordinates) []Issue {
+	var issues []Issue
+	seenText := make(map[string]int, len(records))
+	seenID := make(map[string]int, len(records))
+	seenNameNumber := make(map[string]int, len(records))
+
+	for _, rec := range records {
+		if rec.Text == "" {
+			issues = append(issues, Issue{Line: rec.Line, Check: "empty-text", Message: "document text is empty"})
+			continue
+		}
+		if first, ok := seenText[rec.Text]; ok {
+			issues = append(issues, Issue{Line: rec.Line, Check: "duplicate-text", Message: fmt.Sprintf("same text as line %d", first)})
+		} else {
+			seenText[rec.Text] = rec.Line
+		}
+
+		if id := rec.DocumentID(); id != "" {
+			if first, ok := seenID[id]; ok {
+				issues = append(issues, Issue{Line: rec.Line, Check: "duplicate-id", Message: fmt.Sprintf("original_id %q already used on line %d", rec.Field(KeyOriginalID), first)})
+			} else {
+				seenID[id] = rec.Line
+			}
+		}
+
+		if name, number := rec.Field(KeyPharmacyName), rec.Field(KeyPharmacyNumber); name != "" && number != "" {
+			key := name + "\x00" + number
+			if first, ok := seenNameNumber[key]; ok {
+				issues = append(issues, Issue{Line: rec.Line, Check: "duplicate-name-number", Message: fmt.Sprintf("%s номер %s already on line %d", name, number, first)})
+			} else {
+				seenNameNumber[key] = rec.Line
+			}
+		}
+
+		// CleanMetadata stores absent or null values as "", which hides them from
+		// metadata filters; required keys are fatal, the rest are still reported.
+		for _, key := range MetadataKeys {
+			if rec.Field(key) != "" {
+				continue
+			}
+			if slices.Contains(requiredKeys, key) {
+				issues = append(issues, Issue{Line: rec.Line, Check: "missing-field", Message: fmt.Sprintf("metadata %q is missing or empty", key)})
+			} else {
+				issues = append(issues, Issue{Line: rec.Line, Check: "empty-field", Message: fmt.Sprintf("metadata %q is missing or empty and would be stored as \"\"", key)})
+			}
+		}
+
+		if phone := rec.Field(KeyPhoneNumber); phone != "" && !phonePattern.MatchString(phone) {
+			issues = append(issues, Issue{Line: rec.Line, Check: "malformed-phone", Message: fmt.Sprintf("phone number %q is not a Belarusian number", phone)})
+		}
+
+		if coords != nil {
+			c, ok := coords[rec.Text]
+			if !ok || c == nil {
+				issues = append(issues, Issue{Line: rec.Line, Check: "missing-coordinates", Message: "no coordinates for " + rec.Address()})
+			} else if issue, bad := checkCity(rec, *c); bad {
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	if coords != nil {
+		var orphans []string
+		for text := range coords {
+			if _, ok := seenText[text]; !ok {
+				orphans = append(orphans, text)
+			}
+		}
+		sort.Strings(orphans)
+		for _, text := range orphans {
+			issues = append(issues, Issue{Check: "orphan-coordinates", Message: fmt.Sprintf("coordinates for unknown record %q", text)})
+		}
+	}
+
+	return issues
+}
+
+// checkCity verifies that c lies within the boundary of the record's city.
+func checkCity(rec Record, c Coordinates) (Issue, bool) {
+	city := rec.Field(KeyCity)
+	if city == "" {
+		return Issue{}, false // already reported as missing-field
+	}
+	boundary, ok := CityBoundaries[city]
+	if !ok {
+		return Issue{Line: rec.Line, Check: "unknown-city", Message: fmt.Sprintf("no boundary for city %q, cannot check coordinates", city)}, true
+	}
+	if !boundary.Contains(c) {
+		return Issue{Line: rec.Line, Check: "outside-city", Message: fmt.Sprintf("%.6f,%.6f is outside %s (%s)", c.Latitude, c.Longitude, city, rec.Address())}, true
+	}
+	return Issue{}, false
+}
@@ -0,0 +1,133 @@
+package pharmacy
+
+import (
+	"slices"
+	"testing"
+)
+
+// testRecord returns a record that passes every check; change adjusts it.
+func testRecord(line int, id string, change func(metadata map[string]any)) Record {
+	metadata := map[string]any{
+		KeyOriginalID:     id,
+		KeyPharmacyName:   "Белфармация",
+		KeyPharmacyNumber: id,
+		KeyCity:           "Минск",
+		KeyStreet:         "проспект Независимости",
+		KeyHouseNumber:    "1",
+		KeyFullAddress:    "Минск, проспект Независимости, 1",
+		KeyPhoneNumber:    "80172000000",
+	}
+	if change != nil {
+		change(metadata)
+	}
+	return Record{Text: "Аптека " + id, Metadata: metadata, Line: line}
+}
+
+var minskCentre = &Coordinates{Latitude: 53.9023, Longitude: 27.5619}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		records    []Record
+		coords     map[string]*Coordinates
+		wantChecks []string
+	}{
+		{
+			name:    "valid record",
+			records: []Record{testRecord(1, "1", nil)},
+			coords:  map[string]*Coordinates{"Аптека 1": minskCentre},
+		},
+		{
+			name:    "coordinate checks skipped without coordinates",
+			records: []Record{testRecord(1, "1", nil)},
+		},
+		{
+			name:       "empty text",
+			records:    []Record{{Line: 1, Metadata: testRecord(1, "1", nil).Metadata}},
+			wantChecks: []string{"empty-text"},
+		},
+		{
+			name: "duplicate text",
+			records: []Record{
+				testRecord(1, "1", nil),
+				func() Record { r := testRecord(2, "2", nil); r.Text = "Аптека 1"; return r }(),
+			},
+			wantChecks: []string{"duplicate-text"},
+		},
+		{
+			name: "duplicate id",
+			records: []Record{
+				testRecord(1, "1", nil),
+				func() Record { r := testRecord(2, "2", nil); r.Metadata[KeyOriginalID] = "1"; return r }(),
+			},
+			wantChecks: []string{"duplicate-id"},
+		},
+		{
+			name: "duplicate name and number",
+			records: []Record{
+				testRecord(1, "1", nil),
+				testRecord(2, "2", func(m map[string]any) { m[KeyPharmacyNumber] = "1" }),
+			},
+			wantChecks: []string{"duplicate-name-number"},
+		},
+		{
+			name:       "missing required field",
+			records:    []Record{testRecord(1, "1", func(m map[string]any) { delete(m, KeyStreet) })},
+			wantChecks: []string{"missing-field"},
+		},
+		{
+			name:       "null optional field",
+			records:    []Record{testRecord(1, "1", func(m map[string]any) { m[KeyHouseNumber] = nil })},
+			wantChecks: []string{"empty-field"},
+		},
+		{
+			name:    "international phone",
+			records: []Record{testRecord(1, "1", func(m map[string]any) { m[KeyPhoneNumber] = "+375291234567" })},
+		},
+		{
+			name:       "malformed phone",
+			records:    []Record{testRecord(1, "1", func(m map[string]any) { m[KeyPhoneNumber] = "8 029 123-45-67" })},
+			wantChecks: []string{"malformed-phone"},
+		},
+		{
+			name:       "unknown operator code",
+			records:    []Record{testRecord(1, "1", func(m map[string]any) { m[KeyPhoneNumber] = "80991234567" })},
+			wantChecks: []string{"malformed-phone"},
+		},
+		{
+			name:       "missing coordinates",
+			records:    []Record{testRecord(1, "1", nil)},
+			coords:     map[string]*Coordinates{"Аптека 1": nil},
+			wantChecks: []string{"missing-coordinates"},
+		},
+		{
+			name:       "outside the city",
+			records:    []Record{testRecord(1, "1", nil)},
+			coords:     map[string]*Coordinates{"Аптека 1": {Latitude: 53.7497, Longitude: 27.3336}},
+			wantChecks: []string{"outside-city"},
+		},
+		{
+			name:       "unknown city",
+			records:    []Record{testRecord(1, "1", func(m map[string]any) { m[KeyCity] = "Гродно" })},
+			coords:     map[string]*Coordinates{"Аптека 1": minskCentre},
+			wantChecks: []string{"unknown-city"},
+		},
+		{
+			name:       "orphan coordinates",
+			records:    []Record{testRecord(1, "1", nil)},
+			coords:     map[string]*Coordinates{"Аптека 1": minskCentre, "Аптека 9": minskCentre},
+			wantChecks: []string{"orphan-coordinates"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checks []string
+			for _, issue := range Validate(tt.records, tt.coords) {
+				checks = append(checks, issue.Check)
+			}
+			if !slices.Equal(checks, tt.wantChecks) {
+				t.Errorf("Validate found %v, want %v", checks, tt.wantChecks)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	db "voice_assistant/db/sqlc"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// APIKeySecurityScheme is the name of the API key scheme in api.yaml.
+const APIKeySecurityScheme = "ApiKeyAuth"
+
+// APIKeyHeader carries the API key of partner and machine clients.
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyPrefix starts every API key so leaked keys are easy to recognise.
+const apiKeyPrefix = "va_"
+
+// APIKeyDisplayLength is how much of a key is stored in the clear, so admins
+// can tell keys apart.
+const APIKeyDisplayLength = 11
+
+// apiKeyRateWindow is the length of the fixed window per-key rate limits are
+// counted in.
+const apiKeyRateWindow = time.Minute
+
+// APIKeyIDContextKey holds the ID of the API key a request was made with.
+const APIKeyIDContextKey = contextKey("api_key_id")
+
+var (
+	ErrNoAPIKey      = errors.New("API key header is missing")
+	ErrAPIKeyInvalid = errors.New("API key is unknown, revoked or expired")
+)
+
+// APIKeyRateLimitError is returned when a key has used up its requests for
+// the current minute.
+type APIKeyRateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *APIKeyRateLimitError) Error() string {
+	return fmt.Sprintf("API key rate limit exceeded, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// APIKeyOwner is the user ID API key requests are attributed to, so chat
+// sessions of a key never mix with those of a user.
+func APIKeyOwner(keyID string) string {
+	return "api-key:" + keyID
+}
+
+// GenerateAPIKey returns a new random API key.
+func GenerateAPIKey() (string, error) {
+	secret, err := generateSecureRandomString(33)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return apiKeyPrefix + secret, nil
+}
+
+// APIKeyStore validates API keys against Postgres and enforces their per-key
+// rate limits. Counters live in memory only, so each instance counts its own
+// requests.
+type APIKeyStore struct {
+	queries *db.Queries
+	auth    *Authenticator
+
+	mu      sync.Mutex
+	windows map[pgtype.UUID]*apiKeyWindow
+}
+
+type apiKeyWindow struct {
+	start time.Time
+	count int
+}
+
+func NewAPIKeyStore(queries *db.Queries, auth *Authenticator) *APIKeyStore {
+	return &APIKeyStore{queries: queries, auth: auth, windows: make(map[pgtype.UUID]*apiKeyWindow)}
+}
+
+// Authenticate looks up the key in the X-API-Key header, makes sure it grants
+// the scopes required by the operation and counts the request against its
+// rate limit.
+func (s *APIKeyStore) Authenticate(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+	req := input.RequestValidationInput.Request
+	apiKey := req.Header.Get(APIKeyHeader)
+	if apiKey == "" {
+		return ErrNoAPIKey
+	}
+	if !strings.HasPrefix(apiKey, apiKeyPrefix) {
+		return ErrAPIKeyInvalid
+	}
+
+	key, err := s.queries.GetActiveAPIKeyByHash(ctx, s.auth.HashAPIKey(apiKey))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAPIKeyInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("looking up API key: %w", err)
+	}
+
+	if err := CheckScopes(input.Scopes, key.Scopes); err != nil {
+		return err
+	}
+
+	firstInWindow, retryAfter, ok := s.take(key.KeyID, int(key.RateLimitPerMinute), time.Now())
+	if !ok {
+		return &APIKeyRateLimitError{RetryAfter: retryAfter}
+	}
+	// last_used_at only needs minute precision, so it is written once per
+	// window rather than on every request.
+	if firstInWindow {
+		if err := s.queries.TouchAPIKey(ctx, key.KeyID); err != nil {
+			slog.ErrorContext(ctx, "Error updating last use of API key", "key_id", key.KeyID, "err", err)
+		}
+	}
+
+	keyID := uuid.UUID(key.KeyID.Bytes).String()
+	newCtx := context.WithValue(req.Context(), UserIDContextKey, APIKeyOwner(keyID))
+	newCtx = context.WithValue(newCtx, APIKeyIDContextKey, keyID)
+	*req = *req.WithContext(newCtx)
+	return nil
+}
+
+// take counts one request for the key against limit.
+func (s *APIKeyStore) take(keyID pgtype.UUID, limit int, now time.Time) (firstInWindow bool, retryAfter time.Duration, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, exists := s.windows[keyID]
+	if !exists || now.Sub(w.start) >= apiKeyRateWindow {
+		w = &apiKeyWindow{start: now}
+		s.windows[keyID] = w
+		firstInWindow = true
+	}
+	if w.count >= limit {
+		return false, w.start.Add(apiKeyRateWindow).Sub(now), false
+	}
+	w.count++
+	return firstInWindow, 0, true
+}
+
+// Prune drops counters whose window has ended.
+func (s *APIKeyStore) Prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for keyID, w := range s.windows {
+		if now.Sub(w.start) >= apiKeyRateWindow {
+			delete(s.windows, keyID)
+		}
+	}
+}
+
+// IsAPIKeyRequest reports whether the request was authenticated with an API
+// key rather than an access token.
+func IsAPIKeyRequest(r *http.Request) bool {
+	keyID, _ := r.Context().Value(APIKeyIDContextKey).(string)
+	return keyID != ""
+}
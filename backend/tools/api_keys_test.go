@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestAPIKeyStoreTake(t *testing.T) {
+	keyID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		limit             int
+		taken             int
+		at                time.Time
+		wantFirstInWindow bool
+		wantOK            bool
+		wantRetryAfter    time.Duration
+	}{
+		{name: "first request", limit: 2, taken: 0, at: start, wantFirstInWindow: true, wantOK: true},
+		{name: "within limit", limit: 2, taken: 1, at: start.Add(10 * time.Second), wantOK: true},
+		{name: "limit used up", limit: 2, taken: 2, at: start.Add(15 * time.Second), wantRetryAfter: 45 * time.Second},
+		{name: "next window", limit: 2, taken: 2, at: start.Add(time.Minute), wantFirstInWindow: true, wantOK: true},
+		{name: "zero limit", limit: 0, taken: 0, at: start, wantRetryAfter: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewAPIKeyStore(nil, nil)
+			for i := 0; i < tt.taken; i++ {
+				if _, _, ok := s.take(keyID, tt.limit, start); !ok {
+					t.Fatalf("take #%d refused", i+1)
+				}
+			}
+			firstInWindow, retryAfter, ok := s.take(keyID, tt.limit, tt.at)
+			if firstInWindow != tt.wantFirstInWindow || ok != tt.wantOK || retryAfter != tt.wantRetryAfter {
+				t.Errorf("take() = (%v, %s, %v), want (%v, %s, %v)",
+					firstInWindow, retryAfter, ok, tt.wantFirstInWindow, tt.wantRetryAfter, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAPIKeyStoreTakeCountsKeysSeparately(t *testing.T) {
+	s := NewAPIKeyStore(nil, nil)
+	now := time.Now()
+	first := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	second := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	if _, _, ok := s.take(first, 1, now); !ok {
+		t.Fatal("first request of the first key refused")
+	}
+	if _, _, ok := s.take(second, 1, now); !ok {
+		t.Fatal("first request of the second key refused once the first key hit its limit")
+	}
+}
+
+func TestAPIKeyStorePrune(t *testing.T) {
+	s := NewAPIKeyStore(nil, nil)
+	now := time.Now()
+	old := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	current := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	s.take(old, 1, now.Add(-time.Minute))
+	s.take(current, 1, now.Add(-time.Second))
+
+	s.Prune(now)
+
+	if _, ok := s.windows[old]; ok {
+		t.Error("Prune kept a counter whose window ended")
+	}
+	if _, ok := s.windows[current]; !ok {
+		t.Error("Prune dropped a counter whose window is still open")
+	}
+}
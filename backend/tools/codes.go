@@ -0,0 +1,18 @@
+package tools
+
+import (
+	"crypto/hmac"
+)
+
+// HashVerificationCode returns the keyed hash under which a one-time code is
+// stored. Six-digit codes are trivially brute-forced from a plain hash, so the
+// credential hash key is mixed in; the purpose keeps a code for one flow from
+// being accepted by another.
+func (f *Authenticator) HashVerificationCode(purpose, code string) string {
+	return f.keyedHash("verification-code", purpose+"\x00"+code)
+}
+
+// VerificationCodeMatches compares code against a stored hash in constant time.
+func (f *Authenticator) VerificationCodeMatches(purpose, code, hash string) bool {
+	return hmac.Equal([]byte(f.HashVerificationCode(purpose, code)), []byte(hash))
+}
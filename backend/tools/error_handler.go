@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	middleWare "github.com/oapi-codegen/nethttp-middleware"
+)
+
+// authRealm is the realm advertised in WWW-Authenticate challenges.
+const authRealm = "voice-assistant"
+
+// tokenErrorDescriptions lists the token errors reported as invalid_token,
+// with the error_description sent for each.
+var tokenErrorDescriptions = []struct {
+	err         error
+	description string
+}{
+	{ErrTokenExpired, "The access token expired"},
+	{ErrTokenNotYetValid, "The access token is not valid yet"},
+	{ErrTokenWrongAudience, "The access token was issued for another audience"},
+	{ErrTokenWrongIssuer, "The access token was issued by another issuer"},
+	{ErrTokenMissingClaim, "The access token is missing a required claim"},
+	{ErrTokenRevoked, "The access token has been revoked"},
+	{ErrTokenInvalid, "The access token is malformed or its signature is invalid"},
+}
+
+// authChallenge builds the RFC 6750 WWW-Authenticate value for an
+// authentication failure. A request without credentials gets no error code.
+func authChallenge(err error) string {
+	challenge := fmt.Sprintf(`Bearer realm=%q`, authRealm)
+	switch {
+	case errors.Is(err, ErrInsufficientScope):
+		return challenge + `, error="insufficient_scope", error_description="The access token does not grant the required scope"`
+	case errors.Is(err, ErrAPIKeyInvalid):
+		return challenge + `, error="invalid_token", error_description="The API key is unknown, revoked or expired"`
+	case errors.Is(err, ErrInvalidAuthHeader):
+		return challenge + `, error="invalid_request", error_description="The Authorization header is malformed"`
+	case errors.Is(err, ErrNoAuthHeader):
+		return challenge
+	}
+	for _, e := range tokenErrorDescriptions {
+		if errors.Is(err, e.err) {
+			return challenge + fmt.Sprintf(`, error="invalid_token", error_description=%q`, e.description)
+		}
+	}
+	return challenge + `, error="invalid_token"`
+}
+
+// ValidationErrorHandler writes OpenAPI validation failures as an Error JSON body.
+// Authentication failures caused by a missing scope are reported as 403 instead of 401,
+// and every authentication failure carries a WWW-Authenticate challenge. An API key
+// over its rate limit gets 429 with Retry-After.
+func ValidationErrorHandler(_ context.Context, err error, w http.ResponseWriter, r *http.Request, opts middleWare.ErrorHandlerOpts) {
+	statusCode := opts.StatusCode
+	var rateLimitErr *APIKeyRateLimitError
+	switch {
+	case errors.As(err, &rateLimitErr):
+		statusCode = http.StatusTooManyRequests
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))))
+	case errors.Is(err, ErrInsufficientScope):
+		statusCode = http.StatusForbidden
+	}
+
+	// openapi3filter errors are multi-line; the first line carries the useful part.
+	message, _, _ := strings.Cut(err.Error(), "\n")
+	switch statusCode {
+	case http.StatusUnauthorized:
+		message = "unauthorized: " + message
+		w.Header().Set("WWW-Authenticate", authChallenge(err))
+	case http.StatusForbidden:
+		message = "forbidden: " + message
+		w.Header().Set("WWW-Authenticate", authChallenge(err))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": message}); err != nil {
+		slog.ErrorContext(r.Context(), "Error encoding error response", "method", r.Method, "path", r.URL.Path, "err", err)
+	}
+}
@@ -1,10 +1,15 @@
 package tools
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"time"
 	"voice_assistant/util"
 
@@ -12,33 +17,138 @@ import (
 	"github.com/google/uuid"
 )
 
+// SessionIDClaim carries the auth session an access token was issued for.
+const SessionIDClaim = "sid"
+
+// AccessTokenTTL is how long an access token stays valid after it is issued.
+const AccessTokenTTL = 30 * time.Minute
+
+// RefreshTokenTTL is how long a refresh token stays valid after it is issued.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
 type Authenticator struct {
 	Config util.Config
+
+	// keys is nil when tokens are signed with the legacy HS256 secret.
+	keys *keySet
+	// hashKey keys the hashes of stored credentials; see keyedHash.
+	hashKey []byte
 }
 
 var _ JWSValidator = (*Authenticator)(nil)
 
-// NewJwsAuthenticator creates an authenticator which uses a HMAC key
-// to validate and sign JWTs.
+// NewJwsAuthenticator creates an authenticator which signs JWTs with the
+// algorithm in JwtSigningAlgorithm: RS256 or EdDSA with the PEM key in
+// JwtSigningKeyFile, or the legacy HS256 with JwtSecret. Tokens signed by any
+// key in JwtVerificationKeyFiles are accepted as well, so the signing key can
+// be rotated without invalidating tokens that are still in use.
 func NewJwsAuthenticator(config util.Config) (*Authenticator, error) {
-	return &Authenticator{Config: config}, nil
+	hashKey := config.CredentialHashKey()
+	if hashKey == "" {
+		return nil, fmt.Errorf("REFRESH_TOKEN_HASH_KEY is required when JWT_SECRET is not set")
+	}
+	f := &Authenticator{Config: config, hashKey: []byte(hashKey)}
+	switch config.JwtSigningAlgorithm {
+	case "", AlgHS256:
+		if err := checkHS256Secret(config.JwtSecret); err != nil {
+			return nil, err
+		}
+		return f, nil
+	case AlgRS256, AlgEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported JWT signing algorithm %q, use %s, %s or %s", config.JwtSigningAlgorithm, AlgRS256, AlgEdDSA, AlgHS256)
+	}
+
+	if config.JwtSigningKeyFile == "" {
+		return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE is required for %s", config.JwtSigningAlgorithm)
+	}
+	keys, err := loadKeySet(config.JwtSigningKeyFile, config.JwtVerificationKeyFiles)
+	if err != nil {
+		return nil, fmt.Errorf("load JWT keys: %w", err)
+	}
+	if alg := keys.signing.method.Alg(); alg != config.JwtSigningAlgorithm {
+		return nil, fmt.Errorf("JWT signing key is a %s key but JWT_SIGNING_ALGORITHM is %s", alg, config.JwtSigningAlgorithm)
+	}
+	f.keys = keys
+	if config.JwtAcceptHS256 {
+		if err := checkHS256Secret(config.JwtSecret); err != nil {
+			return nil, fmt.Errorf("JWT_ACCEPT_HS256 is set: %w", err)
+		}
+	}
+	return f, nil
 }
 
-// GenerateToken creates a new JWT token with the given permissions and duration
-func (f *Authenticator) GenerateToken(userID uuid.UUID) (string, error) {
+// checkHS256Secret rejects secrets too short to sign or verify HS256 tokens.
+// An empty HMAC key is valid to the jwt library, so without this check anyone
+// could sign tokens when JWT_SECRET is unset.
+func checkHS256Secret(secret string) error {
+	if len(secret) < util.MinHS256SecretLength {
+		return fmt.Errorf("JWT_SECRET must be at least %d bytes for %s", util.MinHS256SecretLength, AlgHS256)
+	}
+	return nil
+}
+
+// acceptsHS256 reports whether tokens signed with JwtSecret are valid: always
+// in HS256 mode, and during a migration to asymmetric keys if enabled.
+func (f *Authenticator) acceptsHS256() bool {
+	return f.keys == nil || f.Config.JwtAcceptHS256
+}
+
+// validMethods lists the algorithms ValidateJWS accepts.
+func (f *Authenticator) validMethods() []string {
+	var methods []string
+	if f.keys != nil {
+		for _, kid := range f.keys.order {
+			if alg := f.keys.verification[kid].method.Alg(); !slices.Contains(methods, alg) {
+				methods = append(methods, alg)
+			}
+		}
+	}
+	if f.acceptsHS256() {
+		methods = append(methods, AlgHS256)
+	}
+	return methods
+}
+
+// JWKS returns the public verification keys; it is empty in HS256 mode,
+// since the shared secret must never be published.
+func (f *Authenticator) JWKS() []JWK {
+	if f.keys == nil {
+		return []JWK{}
+	}
+	return f.keys.jwks()
+}
+
+// GenerateToken creates a new JWT token for a session with the given roles and the scopes they grant
+func (f *Authenticator) GenerateToken(userID uuid.UUID, roles []string, sessionID uuid.UUID) (string, error) {
+	tokenID, err := uuid.NewRandom()
+	if err != nil {
+		return "", fmt.Errorf("error generating token ID: %v", err)
+	}
+
 	now := time.Now()
-	tokenDuration := time.Minute * 30
 	claims := jwt.MapClaims{
-		"sub": userID,
-		"exp": now.Add(tokenDuration).Unix(),
-		"iat": now.Unix(),
-		"nbf": now.Unix(),
-		"iss": f.Config.JwtIssuer,
-		"aud": f.Config.JwtAudience,
+		"sub":          userID,
+		"exp":          now.Add(AccessTokenTTL).Unix(),
+		"iat":          now.Unix(),
+		"nbf":          now.Unix(),
+		"iss":          f.Config.JwtIssuer,
+		"aud":          f.Config.JwtAudience,
+		RolesClaim:     roles,
+		ScopeClaim:     strings.Join(ScopesForRoles(roles), " "),
+		SessionIDClaim: sessionID.String(),
+		TokenIDClaim:   tokenID.String(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(f.Config.JwtSecret))
+	var tokenString string
+	if f.keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		tokenString, err = token.SignedString([]byte(f.Config.JwtSecret))
+	} else {
+		token := jwt.NewWithClaims(f.keys.signing.method, claims)
+		token.Header["kid"] = f.keys.signing.kid
+		tokenString, err = token.SignedString(f.keys.signing.private)
+	}
 	if err != nil {
 		return "", fmt.Errorf("error signing token: %v", err)
 	}
@@ -62,25 +172,111 @@ func (f *Authenticator) GenerateRefreshToken() (string, time.Time, error) {
 	}
 
 	now := time.Now()
-	expiresAt := now.AddDate(0, 1, 0)
+	expiresAt := now.Add(RefreshTokenTTL)
 
 	return refreshToken, expiresAt, nil
 }
 
+// keyedHash returns the HMAC of value under the credential hash key
+// (RefreshTokenHashKey, or JwtSecret when unset), so a leaked table cannot be
+// used to confirm guessed credentials. The purpose keeps a hash of one kind of
+// credential from matching another.
+func (f *Authenticator) keyedHash(purpose, value string) string {
+	mac := hmac.New(sha256.New, f.hashKey)
+	mac.Write([]byte(purpose + "\x00" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HashRefreshToken returns the value under which a refresh token is stored;
+// the token itself is never persisted.
+func (f *Authenticator) HashRefreshToken(token string) string {
+	return f.keyedHash("refresh-token", token)
+}
+
+// LegacyHashRefreshToken is the unkeyed hash sessions were stored under
+// before HashRefreshToken was keyed. Such sessions are upgraded on their next
+// rotation and disappear once RefreshTokenTTL has passed.
+func (f *Authenticator) LegacyHashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashDeviceKey returns the value under which a guest's device key is stored.
+// The key is a long-lived credential, so it is hashed like a refresh token.
+func (f *Authenticator) HashDeviceKey(deviceKey string) string {
+	return f.keyedHash("guest-device", deviceKey)
+}
+
+// HashAPIKey returns the value under which an API key is stored.
+func (f *Authenticator) HashAPIKey(apiKey string) string {
+	return f.keyedHash("api-key", apiKey)
+}
+
 // ValidateJWS ensures that the critical JWT claims needed to ensure that we
 // trust the JWT are present and with the correct values.
 func (f *Authenticator) ValidateJWS(jwsString string) (jwt.Token, error) {
 	token, err := jwt.Parse(jwsString, func(token *jwt.Token) (any, error) {
 		// Don't forget to validate the alg is what you expect:
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			if !f.acceptsHS256() {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return []byte(f.Config.JwtSecret), nil
+		}
+		if f.keys == nil {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
-		// hmacSampleSecret is a []byte containing your secret, e.g. []byte("my_secret_key")
-		return []byte(f.Config.JwtSecret), nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		key, ok := f.keys.verification[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if key.method.Alg() != token.Method.Alg() {
+			return nil, fmt.Errorf("signing method %v does not match key %q", token.Header["alg"], kid)
+		}
+		return key.public, nil
+	}, f.parserOptions()...)
 	if err != nil {
-		return jwt.Token{}, fmt.Errorf("error parsing jws token: %v", err)
+		return jwt.Token{}, classifyTokenError(err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return jwt.Token{}, fmt.Errorf("%w: unexpected claims type", ErrTokenInvalid)
+	}
+	for _, claim := range f.requiredClaims() {
+		if value, ok := claims[claim]; !ok || value == nil || value == "" {
+			return jwt.Token{}, fmt.Errorf("%w: %s", ErrTokenMissingClaim, claim)
+		}
 	}
 	return *token, nil
 }
+
+// defaultRequiredClaims are enforced when JwtRequiredClaims is empty.
+var defaultRequiredClaims = []string{"sub", "exp", "iat"}
+
+func (f *Authenticator) requiredClaims() []string {
+	if len(f.Config.JwtRequiredClaims) == 0 {
+		return defaultRequiredClaims
+	}
+	return f.Config.JwtRequiredClaims
+}
+
+// parserOptions enforces the signing algorithm, expiry with JwtLeeway of clock
+// skew, and the issuer and audience GenerateToken writes, when configured.
+func (f *Authenticator) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(f.validMethods()),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(f.Config.JwtLeeway),
+	}
+	if f.Config.JwtIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(f.Config.JwtIssuer))
+	}
+	if f.Config.JwtAudience != "" {
+		opts = append(opts, jwt.WithAudience(f.Config.JwtAudience))
+	}
+	return opts
+}
@@ -0,0 +1,326 @@
+package tools
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"voice_assistant/util"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	testSecret   = "0123456789abcdef0123456789abcdef"
+	testIssuer   = "voice-assistant"
+	testAudience = "voice-assistant-api"
+)
+
+func testConfig() util.Config {
+	return util.Config{
+		JwtSecret:   testSecret,
+		JwtIssuer:   testIssuer,
+		JwtAudience: testAudience,
+		JwtLeeway:   30 * time.Second,
+	}
+}
+
+func newTestAuthenticator(t *testing.T, config util.Config) *Authenticator {
+	t.Helper()
+	f, err := NewJwsAuthenticator(config)
+	if err != nil {
+		t.Fatalf("NewJwsAuthenticator: %v", err)
+	}
+	return f
+}
+
+// validClaims returns claims GenerateToken would write, issued now.
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub": uuid.NewString(),
+		"exp": now.Add(AccessTokenTTL).Unix(),
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"iss": testIssuer,
+		"aud": testAudience,
+	}
+}
+
+func withClaims(change func(jwt.MapClaims)) jwt.MapClaims {
+	claims := validClaims()
+	change(claims)
+	return claims
+}
+
+func signHS256(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestNewJwsAuthenticator(t *testing.T) {
+	dir := t.TempDir()
+	edKey := writePKCS8(t, dir, "ed25519.pem", generateEd25519Key(t))
+
+	tests := []struct {
+		name   string
+		config func(*util.Config)
+		wantOK bool
+	}{
+		{name: "HS256", config: func(c *util.Config) {}, wantOK: true},
+		{name: "explicit HS256", config: func(c *util.Config) { c.JwtSigningAlgorithm = AlgHS256 }, wantOK: true},
+		{name: "empty secret", config: func(c *util.Config) { c.JwtSecret = "" }},
+		{name: "empty secret with a hash key", config: func(c *util.Config) { c.JwtSecret = ""; c.RefreshTokenHashKey = "hash-key" }},
+		{name: "short secret", config: func(c *util.Config) { c.JwtSecret = testSecret[:util.MinHS256SecretLength-1] }},
+		{name: "unsupported algorithm", config: func(c *util.Config) { c.JwtSigningAlgorithm = "none" }},
+		{name: "EdDSA without a key file", config: func(c *util.Config) { c.JwtSigningAlgorithm = AlgEdDSA }},
+		{name: "key does not match the algorithm", config: func(c *util.Config) {
+			c.JwtSigningAlgorithm, c.JwtSigningKeyFile = AlgRS256, edKey
+		}},
+		{name: "EdDSA without a secret", config: func(c *util.Config) {
+			c.JwtSigningAlgorithm, c.JwtSigningKeyFile, c.JwtSecret, c.RefreshTokenHashKey = AlgEdDSA, edKey, "", "hash-key"
+		}, wantOK: true},
+		{name: "EdDSA without any hash key", config: func(c *util.Config) {
+			c.JwtSigningAlgorithm, c.JwtSigningKeyFile, c.JwtSecret = AlgEdDSA, edKey, ""
+		}},
+		{name: "accepting HS256 with an empty secret", config: func(c *util.Config) {
+			c.JwtSigningAlgorithm, c.JwtSigningKeyFile, c.JwtSecret, c.RefreshTokenHashKey, c.JwtAcceptHS256 = AlgEdDSA, edKey, "", "hash-key", true
+		}},
+		{name: "accepting HS256 with a secret", config: func(c *util.Config) {
+			c.JwtSigningAlgorithm, c.JwtSigningKeyFile, c.JwtAcceptHS256 = AlgEdDSA, edKey, true
+		}, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig()
+			tt.config(&config)
+			_, err := NewJwsAuthenticator(config)
+			if (err == nil) != tt.wantOK {
+				t.Errorf("NewJwsAuthenticator error = %v, want success %v", err, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	dir := t.TempDir()
+	edKey := generateEd25519Key(t)
+	rsaKey := generateRSAKey(t, 2048)
+
+	edConfig := testConfig()
+	edConfig.JwtSigningAlgorithm = AlgEdDSA
+	edConfig.JwtSigningKeyFile = writePKCS8(t, dir, "ed25519.pem", edKey)
+	edConfig.JwtVerificationKeyFiles = []string{writePublicKey(t, dir, "rsa.pub", rsaKey.Public())}
+
+	tests := []struct {
+		name     string
+		config   util.Config
+		wantKtys []string
+	}{
+		{name: "HS256 publishes nothing", config: testConfig(), wantKtys: []string{}},
+		{name: "signing key first, then rotated keys", config: edConfig, wantKtys: []string{"OKP", "RSA"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := newTestAuthenticator(t, tt.config).JWKS()
+			if keys == nil {
+				t.Fatal("JWKS returned nil, want an empty list to encode as []")
+			}
+			ktys := make([]string, 0, len(keys))
+			for _, key := range keys {
+				ktys = append(ktys, key.Kty)
+				if key.Use != "sig" || key.Kid == "" {
+					t.Errorf("JWK %+v lacks use or kid", key)
+				}
+			}
+			if !slices.Equal(ktys, tt.wantKtys) {
+				t.Errorf("JWKS key types = %v, want %v", ktys, tt.wantKtys)
+			}
+		})
+	}
+}
+
+func TestGenerateTokenKid(t *testing.T) {
+	dir := t.TempDir()
+	config := testConfig()
+	config.JwtSigningAlgorithm = AlgEdDSA
+	config.JwtSigningKeyFile = writePKCS8(t, dir, "ed25519.pem", generateEd25519Key(t))
+	f := newTestAuthenticator(t, config)
+
+	signed, err := f.GenerateToken(uuid.New(), []string{RoleUser}, uuid.New())
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	token, _, err := jwt.NewParser().ParseUnverified(signed, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if kid := token.Header["kid"]; kid != f.JWKS()[0].Kid {
+		t.Errorf("token kid = %v, want the signing key %s", kid, f.JWKS()[0].Kid)
+	}
+	if alg := token.Method.Alg(); alg != AlgEdDSA {
+		t.Errorf("token alg = %s, want %s", alg, AlgEdDSA)
+	}
+}
+
+func TestValidateJWS(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := generateRSAKey(t, 2048)
+	edKey := generateEd25519Key(t)
+
+	hs256 := newTestAuthenticator(t, testConfig())
+
+	edConfig := testConfig()
+	edConfig.JwtSigningAlgorithm = AlgEdDSA
+	edConfig.JwtSigningKeyFile = writePKCS8(t, dir, "ed25519.pem", edKey)
+	edConfig.JwtVerificationKeyFiles = []string{writePKCS8(t, dir, "rsa.pem", rsaKey)}
+	eddsa := newTestAuthenticator(t, edConfig)
+
+	migratingConfig := edConfig
+	migratingConfig.JwtAcceptHS256 = true
+	migrating := newTestAuthenticator(t, migratingConfig)
+
+	signWith := func(method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		return signed
+	}
+	edKid := eddsa.keys.signing.kid
+	rsaKid := eddsa.keys.order[1]
+
+	generated, err := hs256.GenerateToken(uuid.New(), []string{RoleUser}, uuid.New())
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	generatedEdDSA, err := eddsa.GenerateToken(uuid.New(), []string{RoleUser}, uuid.New())
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		auth    *Authenticator
+		token   string
+		wantErr error
+	}{
+		{name: "generated HS256 token", auth: hs256, token: generated},
+		{name: "generated EdDSA token", auth: eddsa, token: generatedEdDSA},
+		{name: "token from the rotated-out RSA key", auth: eddsa, token: signWith(jwt.SigningMethodRS256, rsaKey, rsaKid, validClaims())},
+		{name: "HS256 token while migrating", auth: migrating, token: signHS256(t, testSecret, validClaims())},
+		{name: "HS256 token once migrated", auth: eddsa, token: signHS256(t, testSecret, validClaims()), wantErr: ErrTokenInvalid},
+		{name: "wrong secret", auth: hs256, token: signHS256(t, strings.Repeat("x", 32), validClaims()), wantErr: ErrTokenInvalid},
+		{name: "empty secret", auth: hs256, token: signHS256(t, "", validClaims()), wantErr: ErrTokenInvalid},
+		{name: "asymmetric token in HS256 mode", auth: hs256, token: signWith(jwt.SigningMethodEdDSA, edKey, edKid, validClaims()), wantErr: ErrTokenInvalid},
+		{name: "alg none", auth: hs256, token: signWith(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", validClaims()), wantErr: ErrTokenInvalid},
+		{name: "algorithm does not match the kid", auth: eddsa, token: signWith(jwt.SigningMethodRS256, rsaKey, edKid, validClaims()), wantErr: ErrTokenInvalid},
+		{name: "unknown kid", auth: eddsa, token: signWith(jwt.SigningMethodEdDSA, generateEd25519Key(t), "unknown", validClaims()), wantErr: ErrTokenInvalid},
+		{name: "garbage", auth: hs256, token: "not.a.token", wantErr: ErrTokenInvalid},
+		{
+			name:    "expired",
+			auth:    hs256,
+			token:   signHS256(t, testSecret, withClaims(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() })),
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:  "expired within the leeway",
+			auth:  hs256,
+			token: signHS256(t, testSecret, withClaims(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-10 * time.Second).Unix() })),
+		},
+		{
+			name:    "not valid yet",
+			auth:    hs256,
+			token:   signHS256(t, testSecret, withClaims(func(c jwt.MapClaims) { c["nbf"] = time.Now().Add(time.Minute).Unix() })),
+			wantErr: ErrTokenNotYetValid,
+		},
+		{
+			name:    "issued in the future",
+			auth:    hs256,
+			token:   signHS256(t, testSecret, withClaims(func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Minute).Unix() })),
+			wantErr: ErrTokenNotYetValid,
+		},
+		{
+			name:    "wrong issuer",
+			auth:    hs256,
+			token:   signHS256(t, testSecret, withClaims(func(c jwt.MapClaims) { c["iss"] = "someone-else" })),
+			wantErr: ErrTokenWrongIssuer,
+		},
+		{
+			name:    "wrong audience",
+			auth:    hs256,
+			token:   signHS256(t, testSecret, withClaims(func(c jwt.MapClaims) { c["aud"] = "another-api" })),
+			wantErr: ErrTokenWrongAudience,
+		},
+		{
+			name:    "no expiry",
+			auth:    hs256,
+			token:   signHS256(t, testSecret, withClaims(func(c jwt.MapClaims) { delete(c, "exp") })),
+			wantErr: ErrTokenMissingClaim,
+		},
+		{
+			name:    "no subject",
+			auth:    hs256,
+			token:   signHS256(t, testSecret, withClaims(func(c jwt.MapClaims) { delete(c, "sub") })),
+			wantErr: ErrTokenMissingClaim,
+		},
+		{
+			name:    "empty subject",
+			auth:    hs256,
+			token:   signHS256(t, testSecret, withClaims(func(c jwt.MapClaims) { c["sub"] = "" })),
+			wantErr: ErrTokenMissingClaim,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.auth.ValidateJWS(tt.token)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("ValidateJWS: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateJWS error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyedHash(t *testing.T) {
+	f := newTestAuthenticator(t, testConfig())
+	keyed := testConfig()
+	keyed.RefreshTokenHashKey = "another-hash-key"
+	other := newTestAuthenticator(t, keyed)
+
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{name: "deterministic", a: f.HashRefreshToken("token"), b: f.HashRefreshToken("token"), same: true},
+		{name: "different tokens", a: f.HashRefreshToken("token"), b: f.HashRefreshToken("token2"), same: false},
+		{name: "purposes are separated", a: f.HashRefreshToken("token"), b: f.HashAPIKey("token"), same: false},
+		{name: "device keys are separated", a: f.HashDeviceKey("token"), b: f.HashAPIKey("token"), same: false},
+		{name: "depends on the hash key", a: f.HashRefreshToken("token"), b: other.HashRefreshToken("token"), same: false},
+		{name: "differs from the legacy hash", a: f.HashRefreshToken("token"), b: f.LegacyHashRefreshToken("token"), same: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.a == tt.b) != tt.same {
+				t.Errorf("hashes %s and %s: equal = %v, want %v", tt.a, tt.b, tt.a == tt.b, tt.same)
+			}
+		})
+	}
+}
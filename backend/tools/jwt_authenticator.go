@@ -18,13 +18,52 @@ type JWSValidator interface {
 }
 
 type contextKey string
-const UserIDContextKey = contextKey("user_id")
+
+const (
+	UserIDContextKey    = contextKey("user_id")
+	RolesContextKey     = contextKey("roles")
+	SessionIDContextKey = contextKey("session_id")
+	TokenIDContextKey   = contextKey("token_id")
+)
 
 var (
 	ErrNoAuthHeader      = errors.New("authorization header is missing")
 	ErrInvalidAuthHeader = errors.New("authorization header is malformed")
+	ErrInsufficientScope = errors.New("token does not grant the required scope")
+	ErrTokenRevoked      = errors.New("token has been revoked")
+)
+
+// Reasons ValidateJWS rejects a token. The returned error wraps exactly one of
+// them, and ValidationErrorHandler reports it in the WWW-Authenticate header.
+var (
+	ErrTokenExpired       = errors.New("token has expired")
+	ErrTokenNotYetValid   = errors.New("token is not valid yet")
+	ErrTokenWrongAudience = errors.New("token was issued for another audience")
+	ErrTokenWrongIssuer   = errors.New("token was issued by another issuer")
+	ErrTokenMissingClaim  = errors.New("token is missing a required claim")
+	ErrTokenInvalid       = errors.New("token is malformed or its signature is invalid")
 )
 
+// classifyTokenError maps a jwt.Parse error to one of the ErrToken* reasons.
+func classifyTokenError(err error) error {
+	var reason error
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		reason = ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		reason = ErrTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		reason = ErrTokenWrongAudience
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		reason = ErrTokenWrongIssuer
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		reason = ErrTokenMissingClaim
+	default:
+		reason = ErrTokenInvalid
+	}
+	return fmt.Errorf("%w: %v", reason, err)
+}
+
 // GetJWSFromRequest extracts a JWS string from an Authorization: Bearer <jws> header
 func GetJWSFromRequest(req *http.Request) (string, error) {
 	authHdr := req.Header.Get("Authorization")
@@ -41,15 +80,21 @@ func GetJWSFromRequest(req *http.Request) (string, error) {
 	return strings.TrimPrefix(authHdr, prefix), nil
 }
 
-func NewAuthenticator(v JWSValidator) openapi3filter.AuthenticationFunc {
+// NewAuthenticator checks BearerAuth requirements with Authenticate and,
+// when apiKeys is set, ApiKeyAuth requirements with the key store.
+func NewAuthenticator(v JWSValidator, revocations *RevocationStore, apiKeys *APIKeyStore) openapi3filter.AuthenticationFunc {
 	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
-		return Authenticate(v, ctx, input)
+		if input.SecuritySchemeName == APIKeySecurityScheme && apiKeys != nil {
+			return apiKeys.Authenticate(ctx, input)
+		}
+		return Authenticate(v, revocations, ctx, input)
 	}
 }
 
-// Authenticate uses the specified validator to ensure a JWT is valid, then makes
-// sure that the claims provided by the JWT match the scopes as required in the API.
-func Authenticate(v JWSValidator, ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+// Authenticate uses the specified validator to ensure a JWT is valid and, when
+// revocations is set, has not been revoked. It then makes sure that the claims
+// provided by the JWT match the scopes as required in the API.
+func Authenticate(v JWSValidator, revocations *RevocationStore, ctx context.Context, input *openapi3filter.AuthenticationInput) error {
 
 	if input.SecuritySchemeName != "BearerAuth" {
 		return fmt.Errorf("security scheme %s != 'BearerAuth'", input.SecuritySchemeName)
@@ -57,12 +102,12 @@ func Authenticate(v JWSValidator, ctx context.Context, input *openapi3filter.Aut
 
 	jws, err := GetJWSFromRequest(input.RequestValidationInput.Request)
 	if err != nil {
-		return fmt.Errorf("getting jws: %w", err) 
+		return fmt.Errorf("getting jws: %w", err)
 	}
 
 	token, err := v.ValidateJWS(jws)
 	if err != nil {
-		return fmt.Errorf("validating JWS: %w", err) 
+		return fmt.Errorf("validating JWS: %w", err)
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
@@ -70,15 +115,30 @@ func Authenticate(v JWSValidator, ctx context.Context, input *openapi3filter.Aut
 		return fmt.Errorf("could not parse token claims")
 	}
 
-	userIDClaim, ok := claims["sub"].(string) 
+	userIDClaim, ok := claims["sub"].(string)
 	if !ok || userIDClaim == "" {
 		return fmt.Errorf("token is missing 'sub' (userID) claim or it's not a string")
 	}
 
+	if revocations != nil && revocations.IsRevoked(claims) {
+		return ErrTokenRevoked
+	}
+
+	// Every scope declared on the operation must be present in the token.
+	if err := CheckTokenScopes(input.Scopes, claims); err != nil {
+		return err
+	}
+
 	newCtx := context.WithValue(input.RequestValidationInput.Request.Context(), UserIDContextKey, userIDClaim)
-	
+	newCtx = context.WithValue(newCtx, RolesContextKey, GetRolesFromClaims(claims))
+	if sessionID, ok := claims[SessionIDClaim].(string); ok && sessionID != "" {
+		newCtx = context.WithValue(newCtx, SessionIDContextKey, sessionID)
+	}
+	if tokenID, ok := claims[TokenIDClaim].(string); ok && tokenID != "" {
+		newCtx = context.WithValue(newCtx, TokenIDContextKey, tokenID)
+	}
+
 	*input.RequestValidationInput.Request = *input.RequestValidationInput.Request.WithContext(newCtx)
 
 	return nil
 }
-
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestClassifyTokenError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "expired", err: jwt.ErrTokenExpired, want: ErrTokenExpired},
+		{name: "not valid yet", err: jwt.ErrTokenNotValidYet, want: ErrTokenNotYetValid},
+		{name: "used before issued", err: jwt.ErrTokenUsedBeforeIssued, want: ErrTokenNotYetValid},
+		{name: "wrong audience", err: jwt.ErrTokenInvalidAudience, want: ErrTokenWrongAudience},
+		{name: "wrong issuer", err: jwt.ErrTokenInvalidIssuer, want: ErrTokenWrongIssuer},
+		{name: "required claim missing", err: jwt.ErrTokenRequiredClaimMissing, want: ErrTokenMissingClaim},
+		{name: "bad signature", err: jwt.ErrTokenSignatureInvalid, want: ErrTokenInvalid},
+		{name: "malformed", err: jwt.ErrTokenMalformed, want: ErrTokenInvalid},
+		{name: "unknown error", err: errors.New("boom"), want: ErrTokenInvalid},
+		{name: "wrapped as jwt.Parse does", err: fmt.Errorf("%w: %w", jwt.ErrTokenInvalidClaims, jwt.ErrTokenExpired), want: ErrTokenExpired},
+	}
+	reasons := []error{ErrTokenExpired, ErrTokenNotYetValid, ErrTokenWrongAudience, ErrTokenWrongIssuer, ErrTokenMissingClaim, ErrTokenInvalid}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyTokenError(tt.err)
+			for _, reason := range reasons {
+				if errors.Is(got, reason) != (reason == tt.want) {
+					t.Errorf("classifyTokenError(%v) = %v, want it to wrap only %v", tt.err, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetJWSFromRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    string
+		wantErr error
+	}{
+		{name: "bearer token", header: "Bearer abc.def.ghi", want: "abc.def.ghi"},
+		{name: "missing header", header: "", wantErr: ErrNoAuthHeader},
+		{name: "other scheme", header: "Basic dXNlcjpwYXNz", wantErr: ErrInvalidAuthHeader},
+		{name: "lowercase scheme", header: "bearer abc.def.ghi", wantErr: ErrInvalidAuthHeader},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			got, err := GetJWSFromRequest(req)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("GetJWSFromRequest = %q, %v; want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
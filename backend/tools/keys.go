@@ -0,0 +1,229 @@
+package tools
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms accepted in JWT_SIGNING_ALGORITHM.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP (Ed25519)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// verificationKey is a public key tokens may be signed with, identified by kid.
+type verificationKey struct {
+	kid    string
+	method jwt.SigningMethod
+	public crypto.PublicKey
+	jwk    JWK
+}
+
+// signingKey is the private key new tokens are signed with.
+type signingKey struct {
+	verificationKey
+	private crypto.PrivateKey
+}
+
+// keySet holds the asymmetric keys of an Authenticator. The signing key is
+// always among the verification keys; older keys stay there during rotation
+// so tokens they signed remain valid until they expire.
+type keySet struct {
+	signing      *signingKey
+	verification map[string]*verificationKey
+	// order keeps the JWKS output stable.
+	order []string
+}
+
+// loadKeySet reads the signing key and any additional verification keys.
+// Verification files may hold public or private keys; only the public part is used.
+func loadKeySet(signingKeyFile string, verificationKeyFiles []string) (*keySet, error) {
+	private, err := readPrivateKey(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	public, err := publicKeyOf(private)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", signingKeyFile, err)
+	}
+	vk, err := newVerificationKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", signingKeyFile, err)
+	}
+
+	ks := &keySet{
+		signing:      &signingKey{verificationKey: *vk, private: private},
+		verification: map[string]*verificationKey{vk.kid: vk},
+		order:        []string{vk.kid},
+	}
+	for _, file := range verificationKeyFiles {
+		public, err := readPublicKey(file)
+		if err != nil {
+			return nil, err
+		}
+		vk, err := newVerificationKey(public)
+		if err != nil {
+			return nil, fmt.Errorf("verification key %s: %w", file, err)
+		}
+		if _, ok := ks.verification[vk.kid]; ok {
+			continue
+		}
+		ks.verification[vk.kid] = vk
+		ks.order = append(ks.order, vk.kid)
+	}
+	return ks, nil
+}
+
+// jwks returns the public keys in the order they were configured.
+func (ks *keySet) jwks() []JWK {
+	keys := make([]JWK, 0, len(ks.order))
+	for _, kid := range ks.order {
+		keys = append(keys, ks.verification[kid].jwk)
+	}
+	return keys
+}
+
+func newVerificationKey(public crypto.PublicKey) (*verificationKey, error) {
+	var (
+		method jwt.SigningMethod
+		jwk    JWK
+	)
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA key is %d bits, at least 2048 are required", key.N.BitLen())
+		}
+		method = jwt.SigningMethodRS256
+		jwk = JWK{
+			Kty: "RSA",
+			Alg: AlgRS256,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+		jwk = JWK{
+			Kty: "OKP",
+			Alg: AlgEdDSA,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", public)
+	}
+	jwk.Use = "sig"
+	jwk.Kid = thumbprint(jwk)
+	return &verificationKey{kid: jwk.Kid, method: method, public: public, jwk: jwk}, nil
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint used as kid, so a key gets
+// the same ID on every instance without configuring one.
+func thumbprint(jwk JWK) string {
+	var members map[string]string
+	switch jwk.Kty {
+	case "RSA":
+		members = map[string]string{"e": jwk.E, "kty": jwk.Kty, "n": jwk.N}
+	default:
+		members = map[string]string{"crv": jwk.Crv, "kty": jwk.Kty, "x": jwk.X}
+	}
+	// encoding/json sorts map keys, which is the canonical form RFC 7638 asks for.
+	canonical, _ := json.Marshal(members)
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", path)
+	}
+	return block, nil
+}
+
+func readPrivateKey(path string) (crypto.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	return parsePrivateKey(path, block)
+}
+
+func parsePrivateKey(path string, block *pem.Block) (crypto.PrivateKey, error) {
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: parse private key: %w", path, err)
+		}
+		return key, nil
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: parse private key: %w", path, err)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%s: unexpected PEM block %q, want a private key", path, block.Type)
+	}
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == "PUBLIC KEY" {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: parse public key: %w", path, err)
+		}
+		return key, nil
+	}
+	if strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		private, err := parsePrivateKey(path, block)
+		if err != nil {
+			return nil, err
+		}
+		return publicKeyOf(private)
+	}
+	return nil, fmt.Errorf("%s: unexpected PEM block %q, want a public key", path, block.Type)
+}
+
+func publicKeyOf(private crypto.PrivateKey) (crypto.PublicKey, error) {
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", private)
+	}
+	return signer.Public(), nil
+}
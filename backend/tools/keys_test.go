@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writePEM writes a PEM block of the given type to a file in dir.
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func writePKCS8(t *testing.T, dir, name string, key crypto.PrivateKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal %s: %v", name, err)
+	}
+	return writePEM(t, dir, name, "PRIVATE KEY", der)
+}
+
+func writePublicKey(t *testing.T, dir, name string, key crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("marshal %s: %v", name, err)
+	}
+	return writePEM(t, dir, name, "PUBLIC KEY", der)
+}
+
+func generateRSAKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	return key
+}
+
+func generateEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate Ed25519 key: %v", err)
+	}
+	return key
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := generateRSAKey(t, 2048)
+	edKey := generateEd25519Key(t)
+	otherEdKey := generateEd25519Key(t)
+
+	rsaPKCS8 := writePKCS8(t, dir, "rsa.pem", rsaKey)
+	rsaPKCS1 := writePEM(t, dir, "rsa-pkcs1.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+	edPrivate := writePKCS8(t, dir, "ed25519.pem", edKey)
+	edPublic := writePublicKey(t, dir, "ed25519.pub", edKey.Public())
+	otherEdPublic := writePublicKey(t, dir, "other-ed25519.pub", otherEdKey.Public())
+	weakRSA := writePKCS8(t, dir, "weak-rsa.pem", generateRSAKey(t, 1024))
+	certificate := writePEM(t, dir, "cert.pem", "CERTIFICATE", []byte("not a key"))
+	garbage := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("no pem here"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		signing          string
+		verification     []string
+		wantAlg          string
+		wantVerification int
+		wantErr          bool
+	}{
+		{name: "RSA PKCS#8", signing: rsaPKCS8, wantAlg: AlgRS256, wantVerification: 1},
+		{name: "RSA PKCS#1", signing: rsaPKCS1, wantAlg: AlgRS256, wantVerification: 1},
+		{name: "Ed25519", signing: edPrivate, wantAlg: AlgEdDSA, wantVerification: 1},
+		{name: "rotation keeps the old key", signing: edPrivate, verification: []string{rsaPKCS8, otherEdPublic}, wantAlg: AlgEdDSA, wantVerification: 3},
+		{name: "signing key listed again is skipped", signing: edPrivate, verification: []string{edPublic}, wantAlg: AlgEdDSA, wantVerification: 1},
+		{name: "RSA key under 2048 bits", signing: weakRSA, wantErr: true},
+		{name: "weak verification key", signing: edPrivate, verification: []string{weakRSA}, wantErr: true},
+		{name: "public key as signing key", signing: edPublic, wantErr: true},
+		{name: "unexpected PEM block", signing: edPrivate, verification: []string{certificate}, wantErr: true},
+		{name: "no PEM block", signing: garbage, wantErr: true},
+		{name: "missing file", signing: filepath.Join(dir, "missing.pem"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks, err := loadKeySet(tt.signing, tt.verification)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("loadKeySet succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadKeySet: %v", err)
+			}
+			if alg := ks.signing.method.Alg(); alg != tt.wantAlg {
+				t.Errorf("signing algorithm = %s, want %s", alg, tt.wantAlg)
+			}
+			if len(ks.verification) != tt.wantVerification || len(ks.order) != tt.wantVerification {
+				t.Errorf("got %d verification keys (%d ordered), want %d", len(ks.verification), len(ks.order), tt.wantVerification)
+			}
+			if ks.order[0] != ks.signing.kid {
+				t.Errorf("first key in JWKS is %s, want the signing key %s", ks.order[0], ks.signing.kid)
+			}
+			for i, jwk := range ks.jwks() {
+				if jwk.Kid != ks.order[i] || jwk.Kid != thumbprint(jwk) {
+					t.Errorf("JWK %d has kid %s, want %s (its thumbprint)", i, jwk.Kid, thumbprint(jwk))
+				}
+			}
+		})
+	}
+}
+
+func TestLoadKeySetKidIsStable(t *testing.T) {
+	dir := t.TempDir()
+	key := generateEd25519Key(t)
+	private := writePKCS8(t, dir, "ed25519.pem", key)
+	public := writePublicKey(t, dir, "ed25519.pub", key.Public())
+
+	signing, err := loadKeySet(private, nil)
+	if err != nil {
+		t.Fatalf("loadKeySet: %v", err)
+	}
+	// Another instance only given the public key must derive the same kid.
+	other, err := loadKeySet(writePKCS8(t, dir, "other.pem", generateEd25519Key(t)), []string{public})
+	if err != nil {
+		t.Fatalf("loadKeySet: %v", err)
+	}
+	if !slices.Contains(other.order, signing.signing.kid) {
+		t.Errorf("kid %s of the signing key not found among %v", signing.signing.kid, other.order)
+	}
+}
+
+func TestThumbprint(t *testing.T) {
+	tests := []struct {
+		name string
+		jwk  JWK
+		want string
+	}{
+		{
+			// RFC 7638, section 3.1.
+			name: "RFC 7638 example",
+			jwk: JWK{
+				Kty: "RSA",
+				E:   "AQAB",
+				N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+				// Members outside the thumbprint do not change it.
+				Kid: "ignored",
+				Use: "sig",
+				Alg: AlgRS256,
+			},
+			want: "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+		},
+		{
+			// RFC 8037, appendix A.3.
+			name: "RFC 8037 Ed25519 example",
+			jwk:  JWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+			want: "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thumbprint(tt.jwk); got != tt.want {
+				t.Errorf("thumbprint = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	db "voice_assistant/db/sqlc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenIDClaim carries the unique ID of an access token.
+const TokenIDClaim = "jti"
+
+// RevocationStore is a denylist of access tokens that must stop working before
+// they expire: single tokens by jti, or every token of an auth session by sid.
+// Lookups are served from memory; when queries is set, entries are also
+// written to Postgres so they survive restarts and reach other instances on
+// their next Prune.
+//
+// An entry only has to outlive the tokens it matches, so it expires
+// AccessTokenTTL after it is added.
+type RevocationStore struct {
+	queries *db.Queries
+
+	mu      sync.RWMutex
+	entries map[string]time.Time
+}
+
+// NewRevocationStore creates a store persisted through queries, or an
+// in-memory one if queries is nil.
+func NewRevocationStore(queries *db.Queries) *RevocationStore {
+	return &RevocationStore{queries: queries, entries: make(map[string]time.Time)}
+}
+
+func tokenRevocationKey(jti string) string         { return "jti:" + jti }
+func sessionRevocationKey(sessionID string) string { return "sid:" + sessionID }
+
+// RevokeToken denylists the access token with the given jti.
+func (s *RevocationStore) RevokeToken(ctx context.Context, jti string) error {
+	return s.add(ctx, tokenRevocationKey(jti))
+}
+
+// RevokeSessions denylists every access token issued for the given sessions.
+func (s *RevocationStore) RevokeSessions(ctx context.Context, sessionIDs ...string) error {
+	for _, sessionID := range sessionIDs {
+		if err := s.add(ctx, sessionRevocationKey(sessionID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RevocationStore) add(ctx context.Context, key string) error {
+	// The in-memory entry is added first so the token stops working on this
+	// instance even if persisting it fails.
+	s.mu.Lock()
+	s.entries[key] = time.Now().Add(AccessTokenTTL)
+	s.mu.Unlock()
+
+	if s.queries == nil {
+		return nil
+	}
+	err := s.queries.CreateTokenRevocation(ctx, db.CreateTokenRevocationParams{
+		TokenKey:   key,
+		TtlSeconds: AccessTokenTTL.Seconds(),
+	})
+	if err != nil {
+		return fmt.Errorf("persist token revocation: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether the token with these claims has been denylisted.
+func (s *RevocationStore) IsRevoked(claims jwt.MapClaims) bool {
+	now := time.Now()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if jti, ok := claims[TokenIDClaim].(string); ok && jti != "" {
+		if expiresAt, ok := s.entries[tokenRevocationKey(jti)]; ok && now.Before(expiresAt) {
+			return true
+		}
+	}
+	if sessionID, ok := claims[SessionIDClaim].(string); ok && sessionID != "" {
+		if expiresAt, ok := s.entries[sessionRevocationKey(sessionID)]; ok && now.Before(expiresAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// Load replaces the in-memory entries with the unexpired ones from Postgres.
+func (s *RevocationStore) Load(ctx context.Context) error {
+	if s.queries == nil {
+		return nil
+	}
+	rows, err := s.queries.ListActiveTokenRevocations(ctx)
+	if err != nil {
+		return fmt.Errorf("load token revocations: %w", err)
+	}
+
+	now := time.Now()
+	entries := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		entries[row.TokenKey] = now.Add(time.Duration(row.TtlSeconds * float64(time.Second)))
+	}
+
+	s.mu.Lock()
+	// Keep entries added locally whose write to Postgres failed.
+	for key, expiresAt := range s.entries {
+		if _, ok := entries[key]; !ok && now.Before(expiresAt) {
+			entries[key] = expiresAt
+		}
+	}
+	s.entries = entries
+	s.mu.Unlock()
+	return nil
+}
+
+// Prune drops expired entries from memory and Postgres, then reloads the
+// remaining ones so revocations made by other instances are picked up.
+func (s *RevocationStore) Prune(ctx context.Context) error {
+	now := time.Now()
+	s.mu.Lock()
+	for key, expiresAt := range s.entries {
+		if !now.Before(expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	s.mu.Unlock()
+
+	if s.queries == nil {
+		return nil
+	}
+	if _, err := s.queries.DeleteExpiredTokenRevocations(ctx); err != nil {
+		return fmt.Errorf("delete expired token revocations: %w", err)
+	}
+	return s.Load(ctx)
+}
+
+// Run prunes the store every interval until ctx is done.
+func (s *RevocationStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Prune(ctx); err != nil {
+				slog.ErrorContext(ctx, "Error pruning token revocations", "err", err)
+			}
+		}
+	}
+}
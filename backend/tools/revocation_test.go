@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRevocationStoreIsRevoked(t *testing.T) {
+	ctx := context.Background()
+	s := NewRevocationStore(nil)
+	if err := s.RevokeToken(ctx, "revoked-jti"); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+	if err := s.RevokeSessions(ctx, "revoked-sid", "other-sid"); err != nil {
+		t.Fatalf("RevokeSessions: %v", err)
+	}
+	// An entry that outlived its tokens but has not been pruned yet.
+	s.entries[tokenRevocationKey("expired-jti")] = time.Now().Add(-time.Second)
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   bool
+	}{
+		{name: "no claims", claims: jwt.MapClaims{}, want: false},
+		{name: "unrelated token", claims: jwt.MapClaims{TokenIDClaim: "jti", SessionIDClaim: "sid"}, want: false},
+		{name: "revoked token", claims: jwt.MapClaims{TokenIDClaim: "revoked-jti", SessionIDClaim: "sid"}, want: true},
+		{name: "revoked session", claims: jwt.MapClaims{TokenIDClaim: "jti", SessionIDClaim: "revoked-sid"}, want: true},
+		{name: "second revoked session", claims: jwt.MapClaims{SessionIDClaim: "other-sid"}, want: true},
+		{name: "jti is not matched as a session", claims: jwt.MapClaims{SessionIDClaim: "revoked-jti"}, want: false},
+		{name: "expired entry", claims: jwt.MapClaims{TokenIDClaim: "expired-jti"}, want: false},
+		{name: "empty ids", claims: jwt.MapClaims{TokenIDClaim: "", SessionIDClaim: ""}, want: false},
+		{name: "non-string ids", claims: jwt.MapClaims{TokenIDClaim: 1, SessionIDClaim: true}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.IsRevoked(tt.claims); got != tt.want {
+				t.Errorf("IsRevoked(%v) = %v, want %v", tt.claims, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevocationStorePrune(t *testing.T) {
+	now := time.Now()
+	s := NewRevocationStore(nil)
+	s.entries = map[string]time.Time{
+		tokenRevocationKey("expired"):     now.Add(-time.Minute),
+		sessionRevocationKey("expired"):   now.Add(-time.Second),
+		tokenRevocationKey("active"):      now.Add(time.Minute),
+		sessionRevocationKey("active"):    now.Add(AccessTokenTTL),
+		tokenRevocationKey("also-active"): now.Add(time.Hour),
+	}
+
+	if err := s.Prune(context.Background()); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+
+	want := []string{tokenRevocationKey("active"), sessionRevocationKey("active"), tokenRevocationKey("also-active")}
+	if len(s.entries) != len(want) {
+		t.Errorf("Prune kept %d entries, want %d: %v", len(s.entries), len(want), s.entries)
+	}
+	for _, key := range want {
+		if _, ok := s.entries[key]; !ok {
+			t.Errorf("Prune dropped unexpired entry %q", key)
+		}
+	}
+	if !s.IsRevoked(jwt.MapClaims{SessionIDClaim: "active"}) {
+		t.Error("session revocation stopped working after Prune")
+	}
+}
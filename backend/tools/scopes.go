@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Roles stored in users.roles.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+	// RoleGuest is held by anonymous device accounts until they are upgraded.
+	RoleGuest = "guest"
+)
+
+// Scopes referenced by the `security` sections of api.yaml.
+const (
+	ScopeChat    = "chat"
+	ScopeAccount = "account"
+	ScopeAdmin   = "admin"
+)
+
+const (
+	RolesClaim = "roles"
+	ScopeClaim = "scope"
+)
+
+// roleScopes maps every role to the scopes it grants.
+var roleScopes = map[string][]string{
+	RoleUser:  {ScopeChat, ScopeAccount},
+	RoleAdmin: {ScopeChat, ScopeAccount, ScopeAdmin},
+	RoleGuest: {ScopeChat},
+}
+
+// APIKeyScopes lists the scopes an API key can be issued with. Keys act for
+// machine clients rather than users, so account and admin stay token-only.
+var APIKeyScopes = []string{ScopeChat}
+
+// ScopesForRoles returns the sorted, de-duplicated set of scopes granted by roles.
+// Unknown roles grant nothing.
+func ScopesForRoles(roles []string) []string {
+	var scopes []string
+	for _, role := range roles {
+		for _, scope := range roleScopes[role] {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	slices.Sort(scopes)
+	return scopes
+}
+
+// GetScopesFromClaims reads the space-delimited `scope` claim (RFC 8693).
+func GetScopesFromClaims(claims jwt.MapClaims) []string {
+	raw, ok := claims[ScopeClaim].(string)
+	if !ok {
+		return nil
+	}
+	return strings.Fields(raw)
+}
+
+// GetRolesFromClaims reads the `roles` claim.
+func GetRolesFromClaims(claims jwt.MapClaims) []string {
+	raw, ok := claims[RolesClaim].([]any)
+	if !ok {
+		return nil
+	}
+	roles := make([]string, 0, len(raw))
+	for _, r := range raw {
+		if role, ok := r.(string); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// CheckTokenScopes makes sure every expected scope is granted by the token.
+func CheckTokenScopes(expectedScopes []string, claims jwt.MapClaims) error {
+	return CheckScopes(expectedScopes, GetScopesFromClaims(claims))
+}
+
+// CheckScopes makes sure every expected scope is in granted.
+func CheckScopes(expectedScopes, granted []string) error {
+	var missing []string
+	for _, scope := range expectedScopes {
+		if !slices.Contains(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing %s", ErrInsufficientScope, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestScopesForRoles(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []string
+		want  []string
+	}{
+		{name: "no roles", roles: nil, want: nil},
+		{name: "user", roles: []string{RoleUser}, want: []string{ScopeAccount, ScopeChat}},
+		{name: "guest", roles: []string{RoleGuest}, want: []string{ScopeChat}},
+		{name: "admin", roles: []string{RoleAdmin}, want: []string{ScopeAccount, ScopeAdmin, ScopeChat}},
+		{name: "overlapping roles are de-duplicated", roles: []string{RoleUser, RoleAdmin, RoleUser}, want: []string{ScopeAccount, ScopeAdmin, ScopeChat}},
+		{name: "unknown role grants nothing", roles: []string{"superuser"}, want: nil},
+		{name: "unknown role next to a known one", roles: []string{"superuser", RoleGuest}, want: []string{ScopeChat}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScopesForRoles(tt.roles); !slices.Equal(got, tt.want) {
+				t.Errorf("ScopesForRoles(%v) = %v, want %v", tt.roles, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+		granted  []string
+		wantErr  bool
+	}{
+		{name: "nothing expected", expected: nil, granted: nil},
+		{name: "all granted", expected: []string{ScopeChat}, granted: []string{ScopeAccount, ScopeChat}},
+		{name: "several expected and granted", expected: []string{ScopeChat, ScopeAdmin}, granted: []string{ScopeAdmin, ScopeChat}},
+		{name: "one missing", expected: []string{ScopeChat, ScopeAdmin}, granted: []string{ScopeChat}, wantErr: true},
+		{name: "nothing granted", expected: []string{ScopeAccount}, granted: nil, wantErr: true},
+		{name: "scopes are case sensitive", expected: []string{ScopeChat}, granted: []string{"CHAT"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckScopes(tt.expected, tt.granted)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckScopes(%v, %v) = %v, want error %v", tt.expected, tt.granted, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInsufficientScope) {
+				t.Errorf("CheckScopes error %v does not wrap ErrInsufficientScope", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/amikos-tech/chroma-go/pkg/embeddings"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// embeddingFunction wraps an embedding function with a span per call.
+type embeddingFunction struct {
+	next   embeddings.EmbeddingFunction
+	tracer trace.Tracer
+}
+
+// EmbeddingFunction traces the calls Chroma makes to ef to embed documents
+// and queries.
+func EmbeddingFunction(ef embeddings.EmbeddingFunction) embeddings.EmbeddingFunction {
+	return &embeddingFunction{next: ef, tracer: Tracer("voice_assistant/embeddings")}
+}
+
+func (f *embeddingFunction) EmbedDocuments(ctx context.Context, texts []string) ([]embeddings.Embedding, error) {
+	ctx, span := f.tracer.Start(ctx, "embedding.documents",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("embedding.texts", len(texts))),
+	)
+	out, err := f.next.EmbedDocuments(ctx, texts)
+	End(span, err)
+	return out, err
+}
+
+func (f *embeddingFunction) EmbedQuery(ctx context.Context, text string) (embeddings.Embedding, error) {
+	ctx, span := f.tracer.Start(ctx, "embedding.query", trace.WithSpanKind(trace.SpanKindClient))
+	out, err := f.next.EmbedQuery(ctx, text)
+	End(span, err)
+	return out, err
+}
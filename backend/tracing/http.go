@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/getkin/kin-openapi/routers"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Middleware starts a server span for every request, continuing the trace of
+// the caller if it sent a traceparent header. Spans are named after the
+// operationId router resolves the request to, so they group the same way the
+// latency metrics do.
+func Middleware(router routers.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return otelhttp.NewHandler(next, "http.server",
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				if route, _, err := router.FindRoute(r); err == nil && route.Operation != nil && route.Operation.OperationID != "" {
+					return route.Operation.OperationID
+				}
+				return r.Method
+			}),
+		)
+	}
+}
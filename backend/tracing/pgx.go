@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PgxTracer starts a client span for every query run through a pgx
+// connection. Spans are named after the sqlc query ("-- name: GetUser :one"),
+// so no SQL parsing is needed to tell them apart.
+type PgxTracer struct {
+	tracer trace.Tracer
+}
+
+// NewPgxTracer returns a tracer to set as pgx.ConnConfig.Tracer.
+func NewPgxTracer() *PgxTracer {
+	return &PgxTracer{tracer: Tracer("voice_assistant/db")}
+}
+
+var _ pgx.QueryTracer = (*PgxTracer)(nil)
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *PgxTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = t.tracer.Start(ctx, "db."+queryName(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *PgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	}
+	End(span, data.Err)
+}
+
+// queryName returns the sqlc name of sql, or "query" for statements that
+// were not generated by sqlc, such as the migrations.
+func queryName(sql string) string {
+	const marker = "-- name: "
+	if !strings.HasPrefix(sql, marker) {
+		return "query"
+	}
+	name, _, _ := strings.Cut(sql[len(marker):], " ")
+	return name
+}
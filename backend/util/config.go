@@ -2,6 +2,8 @@ package util
 
 import (
 	"log"
+	"reflect"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -9,20 +11,107 @@ import (
 // Config stores all configuration of the application.
 // The values are read by viper from a config file or environment variable.
 type Config struct {
-	DbDriver                 string `mapstructure:"DB_DRIVER"`
-	DbSource                 string `mapstructure:"DB_SOURCE"`
-	PostgresUser             string `mapstructure:"POSTGRES_USER"`
-	PostgresPassword         string `mapstructure:"POSTGRES_PASSWORD"`
-	PostgresDb               string `mapstructure:"POSTGRES_DB"`
-	ServerAddress            string `mapstructure:"SERVER_ADDRESS"`
-	JwtSecret                string `mapstructure:"JWT_SECRET"`
-	JwtIssuer                string `mapstructure:"JWT_ISSUER"`
-	JwtAudience              string `mapstructure:"JWT_AUDIENCE"`
-	GoogleAPIKey             string `mapstructure:"GEMINI_API_KEY"`
-	ChromaBaseURL            string `mapstructure:"CHROMA_BASE_URL"`
-	ChromaCollectionName     string `mapstructure:"CHROMA_COLLECTION_NAME"`
-	GoogleEmbeddingModelName string `mapstructure:"GOOGLE_EMBEDDING_MODEL_NAME"`
-	GoogleChatModelName      string `mapstructure:"GOOGLE_CHAT_MODEL_NAME"`
+	DbDriver                    string        `mapstructure:"DB_DRIVER"`
+	DbSource                    string        `mapstructure:"DB_SOURCE"`
+	PostgresUser                string        `mapstructure:"POSTGRES_USER"`
+	PostgresPassword            string        `mapstructure:"POSTGRES_PASSWORD"`
+	PostgresDb                  string        `mapstructure:"POSTGRES_DB"`
+	ServerAddress               string        `mapstructure:"SERVER_ADDRESS"`
+	MetricsAddress              string        `mapstructure:"METRICS_ADDRESS"`
+	JwtSecret                   string        `mapstructure:"JWT_SECRET"`
+	RefreshTokenHashKey         string        `mapstructure:"REFRESH_TOKEN_HASH_KEY"`
+	JwtSigningAlgorithm         string        `mapstructure:"JWT_SIGNING_ALGORITHM"`
+	JwtSigningKeyFile           string        `mapstructure:"JWT_SIGNING_KEY_FILE"`
+	JwtVerificationKeyFiles     []string      `mapstructure:"JWT_VERIFICATION_KEY_FILES"`
+	JwtAcceptHS256              bool          `mapstructure:"JWT_ACCEPT_HS256"`
+	JwtIssuer                   string        `mapstructure:"JWT_ISSUER"`
+	JwtAudience                 string        `mapstructure:"JWT_AUDIENCE"`
+	JwtLeeway                   time.Duration `mapstructure:"JWT_LEEWAY"`
+	JwtRequiredClaims           []string      `mapstructure:"JWT_REQUIRED_CLAIMS"`
+	GoogleAPIKey                string        `mapstructure:"GEMINI_API_KEY"`
+	ChromaBaseURL               string        `mapstructure:"CHROMA_BASE_URL"`
+	ChromaCollectionName        string        `mapstructure:"CHROMA_COLLECTION_NAME"`
+	GoogleEmbeddingModelName    string        `mapstructure:"GOOGLE_EMBEDDING_MODEL_NAME"`
+	GoogleChatModelName         string        `mapstructure:"GOOGLE_CHAT_MODEL_NAME"`
+	GeminiBaseURL               string        `mapstructure:"GEMINI_BASE_URL"`
+	LLMTimeout                  time.Duration `mapstructure:"LLM_TIMEOUT"`
+	PharmacyDataFile            string        `mapstructure:"PHARMACY_DATA_FILE"`
+	PharmacyCoordinatesFile     string        `mapstructure:"PHARMACY_COORDINATES_FILE"`
+	NominatimURL                string        `mapstructure:"NOMINATIM_URL"`
+	PharmacyGeocoder            string        `mapstructure:"PHARMACY_GEOCODER"`
+	GeocodeCacheFile            string        `mapstructure:"GEOCODE_CACHE_FILE"`
+	VerificationCodeTTL         time.Duration `mapstructure:"VERIFICATION_CODE_TTL"`
+	VerificationCodeMaxAttempts int           `mapstructure:"VERIFICATION_CODE_MAX_ATTEMPTS"`
+	VerificationCodeResendAfter time.Duration `mapstructure:"VERIFICATION_CODE_RESEND_AFTER"`
+	VerificationCodeHourlyLimit int           `mapstructure:"VERIFICATION_CODE_HOURLY_LIMIT"`
+	ChatHourlyLimit             int           `mapstructure:"CHAT_HOURLY_LIMIT"`
+	GuestChatHourlyLimit        int           `mapstructure:"GUEST_CHAT_HOURLY_LIMIT"`
+	GuestHourlyLimitPerIP       int           `mapstructure:"GUEST_HOURLY_LIMIT_PER_IP"`
+	ChatMaxUploadBytes          int64         `mapstructure:"CHAT_MAX_UPLOAD_BYTES"`
+	ChatSearchResults           int           `mapstructure:"CHAT_SEARCH_RESULTS"`
+	ChatContextResults          int           `mapstructure:"CHAT_CONTEXT_RESULTS"`
+	ChatHistoryTTL              time.Duration `mapstructure:"CHAT_HISTORY_TTL"`
+	ChatSessionTTL              time.Duration `mapstructure:"CHAT_SESSION_TTL"`
+	CleanupInterval             time.Duration `mapstructure:"CLEANUP_INTERVAL"`
+	FuzzyPharmacyNameDistance   int           `mapstructure:"FUZZY_PHARMACY_NAME_DISTANCE"`
+	FuzzyPharmacyNumberDistance int           `mapstructure:"FUZZY_PHARMACY_NUMBER_DISTANCE"`
+	FuzzyCityDistance           int           `mapstructure:"FUZZY_CITY_DISTANCE"`
+	FuzzyStreetDistance         int           `mapstructure:"FUZZY_STREET_DISTANCE"`
+	FuzzyHouseNumberDistance    int           `mapstructure:"FUZZY_HOUSE_NUMBER_DISTANCE"`
+	HTTPReadHeaderTimeout       time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"`
+	HTTPReadTimeout             time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	HTTPWriteTimeout            time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout             time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	MailDevMode                 bool          `mapstructure:"MAIL_DEV_MODE"`
+	MailDevDir                  string        `mapstructure:"MAIL_DEV_DIR"`
+	MailFrom                    string        `mapstructure:"MAIL_FROM"`
+	SMTPHost                    string        `mapstructure:"SMTP_HOST"`
+	SMTPPort                    int           `mapstructure:"SMTP_PORT"`
+	SMTPUsername                string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword                string        `mapstructure:"SMTP_PASSWORD"`
+	LogLevel                    string        `mapstructure:"LOG_LEVEL"`
+	TracingOTLPEndpoint         string        `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingServiceName          string        `mapstructure:"TRACING_SERVICE_NAME"`
+	TracingSampleRatio          float64       `mapstructure:"TRACING_SAMPLE_RATIO"`
+	ReadinessCheckTimeout       time.Duration `mapstructure:"READINESS_CHECK_TIMEOUT"`
+	ReadinessCheckLLM           bool          `mapstructure:"READINESS_CHECK_LLM"`
+	ShutdownTimeout             time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+}
+
+// defaults are used for settings missing from both the config file and the
+// environment. Registering a key here also lets viper pick it up from the
+// environment when the config file does not mention it.
+var defaults = map[string]any{
+	"SERVER_ADDRESS":                 "0.0.0.0:8080",
+	"GOOGLE_CHAT_MODEL_NAME":         "gemini-2.0-flash",
+	"GOOGLE_EMBEDDING_MODEL_NAME":    "text-embedding-004",
+	"GEMINI_BASE_URL":                "https://google-proxy.hlofiys.xyz/",
+	"LLM_TIMEOUT":                    "60s",
+	"CHAT_MAX_UPLOAD_BYTES":          32 << 20,
+	"CHAT_SEARCH_RESULTS":            10,
+	"CHAT_CONTEXT_RESULTS":           5,
+	"VERIFICATION_CODE_TTL":          "15m",
+	"VERIFICATION_CODE_MAX_ATTEMPTS": 5,
+	"VERIFICATION_CODE_RESEND_AFTER": "1m",
+	"VERIFICATION_CODE_HOURLY_LIMIT": 5,
+	"GUEST_CHAT_HOURLY_LIMIT":        10,
+	"GUEST_HOURLY_LIMIT_PER_IP":      5,
+	"CHAT_HISTORY_TTL":               "15m",
+	"CHAT_SESSION_TTL":               "1h",
+	"CLEANUP_INTERVAL":               "30m",
+	"FUZZY_PHARMACY_NAME_DISTANCE":   4,
+	"FUZZY_PHARMACY_NUMBER_DISTANCE": 1,
+	"FUZZY_CITY_DISTANCE":            2,
+	"FUZZY_STREET_DISTANCE":          2,
+	"FUZZY_HOUSE_NUMBER_DISTANCE":    1,
+	"HTTP_READ_HEADER_TIMEOUT":       "10s",
+	"HTTP_READ_TIMEOUT":              "60s",
+	"HTTP_WRITE_TIMEOUT":             "3m",
+	"HTTP_IDLE_TIMEOUT":              "2m",
+	"READINESS_CHECK_TIMEOUT":        "2s",
+	"SHUTDOWN_TIMEOUT":               "30s",
+	"TRACING_SAMPLE_RATIO":           1.0,
+	"LOG_LEVEL":                      "info",
 }
 
 // LoadConfig reads configuration from file or environment variables.
@@ -30,8 +119,19 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.AddConfigPath(path)
 	viper.SetConfigName("config") // Name of config file (without extension)
 	viper.SetConfigType("yaml")
+	for key, value := range defaults {
+		viper.SetDefault(key, value)
+	}
 
 	viper.AutomaticEnv() // Read in environment variables that match
+	// AutomaticEnv only covers keys viper already knows from the file or the
+	// defaults, so bind the rest for deployments configured by environment alone.
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if err = viper.BindEnv(t.Field(i).Tag.Get("mapstructure")); err != nil {
+			return
+		}
+	}
 
 	// Read config file if present
 	if err = viper.ReadInConfig(); err != nil {
@@ -49,6 +149,19 @@ func LoadConfig(path string) (config Config, err error) {
 	// Always load sensitive fields from environment variables
 	config.JwtSecret = viper.GetString("JWT_SECRET")
 	config.GoogleAPIKey = viper.GetString("GEMINI_API_KEY")
+	config.SMTPPassword = viper.GetString("SMTP_PASSWORD")
+	config.RefreshTokenHashKey = viper.GetString("REFRESH_TOKEN_HASH_KEY")
 
 	return
 }
+
+// CredentialHashKey returns the key stored credentials such as refresh tokens,
+// device keys, API keys and verification codes are hashed with:
+// RefreshTokenHashKey, or JwtSecret when it is unset. It is empty when
+// neither is configured, which the server refuses to start with.
+func (c Config) CredentialHashKey() string {
+	if c.RefreshTokenHashKey != "" {
+		return c.RefreshTokenHashKey
+	}
+	return c.JwtSecret
+}
@@ -0,0 +1,168 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// MinHS256SecretLength is the shortest JWT_SECRET accepted for HS256.
+const MinHS256SecretLength = 32
+
+// A chat request may wait for the LLM chatLLMRounds times, each bounded by
+// LLM_TIMEOUT: once for the transcript and tool call, once for the answer to
+// the tool result. chatWriteHeadroom covers the embedding, Chroma and Postgres
+// calls in between and writing the response.
+const (
+	chatLLMRounds     = 2
+	chatWriteHeadroom = 30 * time.Second
+)
+
+// Validate reports every missing or malformed setting the server needs, so a
+// misconfigured deployment fails at startup with all problems listed at
+// once. The JWT key files and SMTP are checked when the authenticator and
+// mailer are created, since that needs reading files.
+func (c Config) Validate() error {
+	var errs []error
+	required := func(key, value string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+	absoluteURL := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s must be an absolute URL, got %q", key, value))
+		}
+	}
+	positive := func(key string, d time.Duration) {
+		if d <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be a positive duration, got %s", key, d))
+		}
+	}
+	atLeast := func(key string, n, min int64) {
+		if n < min {
+			errs = append(errs, fmt.Errorf("%s must be at least %d, got %d", key, min, n))
+		}
+	}
+
+	required("DB_SOURCE", c.DbSource)
+	required("GEMINI_API_KEY", c.GoogleAPIKey)
+	required("GOOGLE_CHAT_MODEL_NAME", c.GoogleChatModelName)
+	required("GOOGLE_EMBEDDING_MODEL_NAME", c.GoogleEmbeddingModelName)
+	required("CHROMA_BASE_URL", c.ChromaBaseURL)
+	required("CHROMA_COLLECTION_NAME", c.ChromaCollectionName)
+	if c.JwtSigningAlgorithm == "" || c.JwtSigningAlgorithm == "HS256" || c.JwtAcceptHS256 {
+		if len(c.JwtSecret) < MinHS256SecretLength {
+			errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d bytes when HS256 tokens are signed or accepted", MinHS256SecretLength))
+		}
+	}
+	if c.MailDevMode && c.MailDevDir == "" {
+		// Codes would be dropped, making registration and password reset impossible.
+		errs = append(errs, errors.New("MAIL_DEV_DIR is required when MAIL_DEV_MODE is enabled"))
+	}
+	if c.CredentialHashKey() == "" {
+		errs = append(errs, errors.New("REFRESH_TOKEN_HASH_KEY is required when JWT_SECRET is not set"))
+	}
+	absoluteURL("CHROMA_BASE_URL", c.ChromaBaseURL)
+	absoluteURL("GEMINI_BASE_URL", c.GeminiBaseURL)
+	absoluteURL("TRACING_OTLP_ENDPOINT", c.TracingOTLPEndpoint)
+	if _, _, err := net.SplitHostPort(c.ServerAddress); err != nil {
+		errs = append(errs, fmt.Errorf("SERVER_ADDRESS must be host:port, got %q", c.ServerAddress))
+	}
+	// Metrics are not served when METRICS_ADDRESS is empty, and never on the
+	// public API listener.
+	if c.MetricsAddress != "" {
+		if _, _, err := net.SplitHostPort(c.MetricsAddress); err != nil {
+			errs = append(errs, fmt.Errorf("METRICS_ADDRESS must be host:port, got %q", c.MetricsAddress))
+		} else if c.MetricsAddress == c.ServerAddress {
+			errs = append(errs, errors.New("METRICS_ADDRESS must differ from SERVER_ADDRESS"))
+		}
+	}
+
+	positive("LLM_TIMEOUT", c.LLMTimeout)
+	positive("CHAT_HISTORY_TTL", c.ChatHistoryTTL)
+	positive("CHAT_SESSION_TTL", c.ChatSessionTTL)
+	positive("CLEANUP_INTERVAL", c.CleanupInterval)
+	positive("HTTP_READ_HEADER_TIMEOUT", c.HTTPReadHeaderTimeout)
+	positive("HTTP_READ_TIMEOUT", c.HTTPReadTimeout)
+	positive("HTTP_WRITE_TIMEOUT", c.HTTPWriteTimeout)
+	positive("HTTP_IDLE_TIMEOUT", c.HTTPIdleTimeout)
+	positive("READINESS_CHECK_TIMEOUT", c.ReadinessCheckTimeout)
+	positive("SHUTDOWN_TIMEOUT", c.ShutdownTimeout)
+	positive("VERIFICATION_CODE_TTL", c.VerificationCodeTTL)
+	positive("VERIFICATION_CODE_RESEND_AFTER", c.VerificationCodeResendAfter)
+	if minWrite := chatLLMRounds*c.LLMTimeout + chatWriteHeadroom; c.HTTPWriteTimeout < minWrite {
+		errs = append(errs, fmt.Errorf("HTTP_WRITE_TIMEOUT (%s) must be at least %d×LLM_TIMEOUT plus %s (%s) to fit a whole chat request",
+			c.HTTPWriteTimeout, chatLLMRounds, chatWriteHeadroom, minWrite))
+	}
+
+	atLeast("CHAT_MAX_UPLOAD_BYTES", c.ChatMaxUploadBytes, 1)
+	atLeast("CHAT_SEARCH_RESULTS", int64(c.ChatSearchResults), 1)
+	atLeast("CHAT_CONTEXT_RESULTS", int64(c.ChatContextResults), 1)
+	atLeast("VERIFICATION_CODE_MAX_ATTEMPTS", int64(c.VerificationCodeMaxAttempts), 1)
+	atLeast("VERIFICATION_CODE_HOURLY_LIMIT", int64(c.VerificationCodeHourlyLimit), 1)
+	// For the hourly limits below zero means unlimited.
+	atLeast("CHAT_HOURLY_LIMIT", int64(c.ChatHourlyLimit), 0)
+	atLeast("GUEST_CHAT_HOURLY_LIMIT", int64(c.GuestChatHourlyLimit), 0)
+	atLeast("GUEST_HOURLY_LIMIT_PER_IP", int64(c.GuestHourlyLimitPerIP), 0)
+	atLeast("FUZZY_PHARMACY_NAME_DISTANCE", int64(c.FuzzyPharmacyNameDistance), 0)
+	atLeast("FUZZY_PHARMACY_NUMBER_DISTANCE", int64(c.FuzzyPharmacyNumberDistance), 0)
+	atLeast("FUZZY_CITY_DISTANCE", int64(c.FuzzyCityDistance), 0)
+	atLeast("FUZZY_STREET_DISTANCE", int64(c.FuzzyStreetDistance), 0)
+	atLeast("FUZZY_HOUSE_NUMBER_DISTANCE", int64(c.FuzzyHouseNumberDistance), 0)
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1, got %v", c.TracingSampleRatio))
+	}
+
+	return errors.Join(errs...)
+}
+
+// redactedValue replaces secrets in printed configuration.
+const redactedValue = "[REDACTED]"
+
+// Redacted returns a copy of the config with secrets masked, safe to print
+// or log.
+func (c Config) Redacted() Config {
+	for _, secret := range []*string{&c.PostgresPassword, &c.JwtSecret, &c.RefreshTokenHashKey, &c.GoogleAPIKey, &c.SMTPPassword} {
+		if *secret != "" {
+			*secret = redactedValue
+		}
+	}
+	if u, err := url.Parse(c.DbSource); err == nil {
+		c.DbSource = u.Redacted()
+	} else if c.DbSource != "" {
+		c.DbSource = redactedValue
+	}
+	return c
+}
+
+// Print writes the config as YAML, one KEY: value line per setting in the
+// order of the struct, with secrets redacted. The output can be used as a
+// config.yaml.
+func (c Config) Print(w io.Writer) error {
+	v := reflect.ValueOf(c.Redacted())
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		value := v.Field(i).Interface()
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		// JSON scalars and arrays are valid YAML flow values.
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", t.Field(i).Tag.Get("mapstructure"), err)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", t.Field(i).Tag.Get("mapstructure"), encoded); err != nil {
+			return err
+		}
+	}
+	return nil
+}